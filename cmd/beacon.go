@@ -0,0 +1,194 @@
+package cmd
+
+import (
+	"bufio"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/ethpandaops/validator-tools/pkg/beacon"
+	"github.com/ethpandaops/validator-tools/pkg/validator"
+)
+
+// beaconTokenEnv is the environment variable a bearer token can be passed in
+const beaconTokenEnv = "VALIDATOR_TOOLS_BEACON_TOKEN"
+
+var (
+	beaconQuorum      int
+	beaconTimeout     time.Duration
+	beaconTokenFile   string
+	beaconHeaders     []string
+	beaconHeadersFile string
+	beaconCACert      string
+	beaconClientCert  string
+	beaconClientKey   string
+	beaconRecordDir   string
+	beaconReplayDir   string
+	beaconStatePath   string
+)
+
+// addBeaconFlags registers the beacon node flags of a command, storing the
+// endpoint URLs in urls
+func addBeaconFlags(cmd *cobra.Command, urls *[]string) {
+	cmd.Flags().StringSliceVar(urls, "beacon", []string{}, "Beacon node endpoint URLs (comma-separated or repeated, e.g. 'http://localhost:5052'); later nodes are used on failover")
+	cmd.Flags().IntVar(&beaconQuorum, "beacon-quorum", 0, "Number of beacon nodes that must agree on genesis, spec and validator answers (0 disables the check)")
+	cmd.Flags().DurationVar(&beaconTimeout, "beacon-timeout", beacon.DefaultTimeout, "Timeout of a single beacon node request")
+	cmd.Flags().StringVar(&beaconTokenFile, "beacon-token-file", "", "File containing a bearer token for the beacon nodes (or set "+beaconTokenEnv+")")
+	cmd.Flags().StringArrayVar(&beaconHeaders, "beacon-header", []string{}, "Header sent to the beacon nodes as 'Name: value' (repeatable, ${VAR} is expanded)")
+	cmd.Flags().StringVar(&beaconHeadersFile, "beacon-headers-file", "", "File with a 'Name: value' header per line sent to the beacon nodes")
+	cmd.Flags().StringVar(&beaconCACert, "beacon-ca-cert", "", "PEM bundle of CAs trusted for the beacon nodes, in addition to the system roots")
+	cmd.Flags().StringVar(&beaconClientCert, "beacon-client-cert", "", "PEM client certificate for mutual TLS with the beacon nodes")
+	cmd.Flags().StringVar(&beaconClientKey, "beacon-client-key", "", "PEM client key for mutual TLS with the beacon nodes")
+	cmd.Flags().StringVar(&beaconRecordDir, "beacon-record", "", "Record every beacon node response to this fixture directory")
+	cmd.Flags().StringVar(&beaconReplayDir, "beacon-replay", "", "Answer beacon requests from a fixture directory written by --beacon-record, without network access")
+	cmd.Flags().StringVar(&beaconStatePath, "state", "", "SSZ beacon state file (e.g. from /eth/v2/debug/beacon/states/finalized) to use instead of a beacon node")
+	cmd.MarkFlagsMutuallyExclusive("beacon-record", "beacon-replay")
+	cmd.MarkFlagsMutuallyExclusive("beacon", "beacon-replay", "state")
+	cmd.MarkFlagsMutuallyExclusive("beacon-record", "state")
+}
+
+// haveBeacon reports whether beacon data is available, from nodes at urls,
+// replayed fixtures or a state file
+func haveBeacon(urls []string) bool {
+	return len(urls) > 0 || beaconReplayDir != "" || beaconStatePath != ""
+}
+
+// newBeaconClient returns the client commands use to talk to the beacon nodes
+// at urls. ${VAR} references in the URLs are expanded, so basic auth
+// credentials can come from the environment. A --state file is read as a
+// state of network, or of the network it belongs to if network is empty.
+func newBeaconClient(urls []string, network string) (beacon.Client, error) {
+	if beaconStatePath != "" {
+		return validator.LoadStateFile(beaconStatePath, network)
+	}
+
+	if beaconReplayDir != "" {
+		log.Infof("Replaying beacon responses from %s", beaconReplayDir)
+
+		return beacon.NewReplayer(beaconReplayDir)
+	}
+
+	client, err := newBeaconNodeClient(urls)
+	if err != nil {
+		return nil, err
+	}
+
+	if beaconRecordDir != "" {
+		log.Infof("Recording beacon responses to %s", beaconRecordDir)
+
+		return beacon.NewRecorder(client, beaconRecordDir)
+	}
+
+	return client, nil
+}
+
+// newBeaconNodeClient returns a client for the beacon nodes at urls
+func newBeaconNodeClient(urls []string) (beacon.Client, error) {
+	if len(urls) == 0 {
+		return nil, errors.New("no beacon node set, use --beacon, --beacon-replay or --state")
+	}
+
+	opts, err := beaconOptions()
+	if err != nil {
+		return nil, err
+	}
+
+	endpoints := make([]beacon.Endpoint, 0, len(urls))
+
+	for _, url := range urls {
+		client, err := beacon.NewClient(os.ExpandEnv(url), opts)
+		if err != nil {
+			return nil, err
+		}
+
+		endpoints = append(endpoints, beacon.Endpoint{Name: client.Name(), Client: client})
+	}
+
+	if len(endpoints) == 1 && beaconQuorum == 0 {
+		return endpoints[0].Client, nil
+	}
+
+	return beacon.NewMultiClient(endpoints, beaconQuorum)
+}
+
+// redactBeaconURLs returns the beacon node URLs in a form that is safe to log
+func redactBeaconURLs(urls []string) string {
+	redacted := make([]string, 0, len(urls))
+	for _, url := range urls {
+		redacted = append(redacted, beacon.RedactURL(url))
+	}
+
+	return strings.Join(redacted, ",")
+}
+
+// beaconOptions builds the connection options from the beacon flags, reading
+// secrets from files and the environment
+func beaconOptions() (*beacon.Options, error) {
+	opts := &beacon.Options{
+		Timeout:        beaconTimeout,
+		Headers:        map[string]string{},
+		CACertFile:     beaconCACert,
+		ClientCertFile: beaconClientCert,
+		ClientKeyFile:  beaconClientKey,
+	}
+
+	opts.BearerToken = os.Getenv(beaconTokenEnv)
+
+	if beaconTokenFile != "" {
+		token, err := os.ReadFile(beaconTokenFile)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read beacon token file")
+		}
+
+		opts.BearerToken = strings.TrimSpace(string(token))
+	}
+
+	headers := append([]string{}, beaconHeaders...)
+
+	if beaconHeadersFile != "" {
+		lines, err := readHeaderLines(beaconHeadersFile)
+		if err != nil {
+			return nil, err
+		}
+
+		headers = append(headers, lines...)
+	}
+
+	for _, header := range headers {
+		name, value, ok := strings.Cut(header, ":")
+		if !ok || strings.TrimSpace(name) == "" {
+			// Only the name is reported, the value may be a secret
+			return nil, errors.Errorf("invalid beacon header %q, expected 'Name: value'", strings.TrimSpace(name))
+		}
+
+		opts.Headers[strings.TrimSpace(name)] = os.ExpandEnv(strings.TrimSpace(value))
+	}
+
+	return opts, nil
+}
+
+// readHeaderLines returns the non-empty, non-comment lines of a headers file
+func readHeaderLines(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open beacon headers file")
+	}
+	defer file.Close()
+
+	var lines []string
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		lines = append(lines, line)
+	}
+
+	return lines, errors.Wrap(scanner.Err(), "failed to read beacon headers file")
+}
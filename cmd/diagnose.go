@@ -0,0 +1,15 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var diagnoseCmd = &cobra.Command{
+	Use:   "diagnose",
+	Short: "Diagnose signing domains and beacon node configuration",
+	Long:  `Tries every known network and fork version to find which signing domain verifies each voluntary exit or deposit signature, or compares a beacon node's configuration with a network's constants.`,
+}
+
+func init() {
+	rootCmd.AddCommand(diagnoseCmd)
+}
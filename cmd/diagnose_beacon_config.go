@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/ethpandaops/validator-tools/pkg/validator"
+)
+
+var (
+	diagnoseBeaconConfigBeaconURLs []string
+	diagnoseBeaconConfigNetwork    string
+)
+
+var diagnoseBeaconConfigCmd = &cobra.Command{
+	Use:   "beacon_config",
+	Short: "Compare a beacon node's config with a network's constants",
+	Long: `Checks the genesis validators root, fork versions and signing domains of a
+beacon node against the expected network, and prints a detailed diff of
+/eth/v1/config/spec against the network's prysm params as JSON.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := newBeaconClient(diagnoseBeaconConfigBeaconURLs, diagnoseBeaconConfigNetwork)
+		if err != nil {
+			return err
+		}
+
+		diff, err := validator.FetchSpecDiff(cmd.Context(), client, diagnoseBeaconConfigNetwork)
+		if err != nil {
+			return errors.Wrap(err, "failed to diff beacon node spec")
+		}
+
+		diff.Log()
+
+		out, err := json.MarshalIndent(diff, "", "  ")
+		if err != nil {
+			return errors.Wrap(err, "failed to marshal spec diff")
+		}
+
+		fmt.Println(string(out))
+
+		return validator.CheckBeaconNetwork(cmd.Context(), client, diagnoseBeaconConfigNetwork)
+	},
+	SilenceUsage: true,
+}
+
+func init() {
+	diagnoseCmd.AddCommand(diagnoseBeaconConfigCmd)
+
+	addBeaconFlags(diagnoseBeaconConfigCmd, &diagnoseBeaconConfigBeaconURLs)
+	diagnoseBeaconConfigCmd.Flags().StringVar(&diagnoseBeaconConfigNetwork, "network", "", "Network the beacon node is expected to be on")
+
+	if err := diagnoseBeaconConfigCmd.MarkFlagRequired("network"); err != nil {
+		log.WithError(err).Fatalf("Failed to mark flag %s as required", "network")
+	}
+
+	diagnoseBeaconConfigCmd.MarkFlagsOneRequired("beacon", "beacon-replay", "state")
+}
@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/ethpandaops/validator-tools/pkg/validator"
+)
+
+var (
+	diagnoseDepositDataInput   string
+	diagnoseDepositDataNetwork string
+)
+
+var diagnoseDepositDataCmd = &cobra.Command{
+	Use:   "deposit_data",
+	Short: "Diagnose deposit data signing domains",
+	Long: `Reports which network and fork version verifies the signature of each
+deposit. With --network, deposits signed for another network or with a fork
+version other than the genesis fork version are flagged.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		diagnoses, err := validator.DiagnoseDeposits(diagnoseDepositDataInput, diagnoseDepositDataNetwork)
+		if err != nil {
+			return errors.Wrap(err, "failed to diagnose deposit data")
+		}
+
+		return reportDiagnoses(diagnoses)
+	},
+	SilenceUsage: true,
+}
+
+func init() {
+	diagnoseCmd.AddCommand(diagnoseDepositDataCmd)
+
+	diagnoseDepositDataCmd.Flags().StringVar(&diagnoseDepositDataInput, "input", "", "Path to deposit data JSON file")
+	diagnoseDepositDataCmd.Flags().StringVar(&diagnoseDepositDataNetwork, "network", "", "Network the deposits are expected to be signed for (optional)")
+
+	err := diagnoseDepositDataCmd.MarkFlagRequired("input")
+	if err != nil {
+		log.WithError(err).Fatalf("Failed to mark flag %s as required", "input")
+	}
+}
@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"runtime"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/ethpandaops/validator-tools/pkg/validator"
+)
+
+var (
+	diagnoseExitsInput   string
+	diagnoseExitsNetwork string
+	diagnoseExitsWorkers int
+)
+
+var diagnoseVoluntaryExitsCmd = &cobra.Command{
+	Use:   "voluntary_exits",
+	Short: "Diagnose voluntary exit signing domains",
+	Long: `Reports which network and fork version verifies the signature of each
+voluntary exit. With --network, exits signed for another network or with a
+fork version other than Capella are flagged.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		diagnoses, err := validator.DiagnoseExits(diagnoseExitsInput, diagnoseExitsNetwork, diagnoseExitsWorkers)
+		if err != nil {
+			return errors.Wrap(err, "failed to diagnose exits")
+		}
+
+		return reportDiagnoses(diagnoses)
+	},
+	SilenceUsage: true,
+}
+
+func init() {
+	diagnoseCmd.AddCommand(diagnoseVoluntaryExitsCmd)
+
+	diagnoseVoluntaryExitsCmd.Flags().StringVar(&diagnoseExitsInput, "input", "", "Directory containing voluntary exit files")
+	diagnoseVoluntaryExitsCmd.Flags().StringVar(&diagnoseExitsNetwork, "network", "", "Network the exits are expected to be signed for (optional)")
+	diagnoseVoluntaryExitsCmd.Flags().IntVar(&diagnoseExitsWorkers, "workers", runtime.NumCPU(), "Number of files diagnosed in parallel (default: number of CPU cores)")
+
+	err := diagnoseVoluntaryExitsCmd.MarkFlagRequired("input")
+	if err != nil {
+		log.WithError(err).Fatalf("Failed to mark flag %s as required", "input")
+	}
+}
+
+// reportDiagnoses logs the diagnoses, prints them as JSON and fails if any has a problem
+func reportDiagnoses(diagnoses []*validator.Diagnosis) error {
+	problems := validator.LogDiagnoses(diagnoses)
+
+	out, err := json.MarshalIndent(diagnoses, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal diagnoses")
+	}
+
+	fmt.Println(string(out))
+
+	if problems > 0 {
+		return errors.Errorf("%d of %d signatures have problems", problems, len(diagnoses))
+	}
+
+	log.WithField("signatures", len(diagnoses)).Info("✅ All signatures verify for the expected domain")
+
+	return nil
+}
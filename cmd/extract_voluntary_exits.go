@@ -15,7 +15,8 @@ var (
 	extractExitsNetwork         string
 	extractExitsWithdrawalCreds string
 	extractExitsPubkeys         []string
-	extractExitsBeaconURL       string
+	extractExitsBeaconURLs      []string
+	extractExitsChecksums       bool
 )
 
 var extractVoluntaryExitsCmd = &cobra.Command{
@@ -23,12 +24,34 @@ var extractVoluntaryExitsCmd = &cobra.Command{
 	Short: "Extract voluntary exit messages",
 	Long:  `Extract voluntary exit messages for Ethereum validators.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if extractExitsChecksums {
+			report, err := validator.VerifyChecksums(extractExitsInput)
+			if err != nil {
+				return errors.Wrap(err, "failed to verify checksum manifest")
+			}
+
+			if err := report.Err(); err != nil {
+				return err
+			}
+
+			log.Info("Checksum manifest verified")
+		}
+
+		client, err := newBeaconClient(extractExitsBeaconURLs, extractExitsNetwork)
+		if err != nil {
+			return err
+		}
+
+		if err := validator.CheckBeaconNetwork(cmd.Context(), client, extractExitsNetwork); err != nil {
+			return errors.Wrap(err, "beacon node network check failed")
+		}
+
 		exits, err := validator.NewVoluntaryExits(extractExitsInput, extractExitsNetwork, extractExitsWithdrawalCreds, extractExitsPubkeys)
 		if err != nil {
 			return errors.Wrap(err, "failed to load exits")
 		}
 
-		err = exits.Extract(extractExitsBeaconURL, extractExitsOutput)
+		err = exits.Extract(cmd.Context(), client, extractExitsOutput)
 		if err != nil {
 			return errors.Wrap(err, "failed to extract exits")
 		}
@@ -59,10 +82,11 @@ func init() {
 
 	extractVoluntaryExitsCmd.Flags().StringVar(&extractExitsInput, "input", "", "Path to directory containing exit files")
 	extractVoluntaryExitsCmd.Flags().StringVar(&extractExitsOutput, "output", "", "Path to directory to save extracted exit files")
-	extractVoluntaryExitsCmd.Flags().StringVar(&extractExitsNetwork, "network", "", "Network (mainnet, holesky or hoodi)")
+	extractVoluntaryExitsCmd.Flags().StringVar(&extractExitsNetwork, "network", "", "Network (mainnet, holesky, hoodi, sepolia, gnosis, chiado or ephemery)")
 	extractVoluntaryExitsCmd.Flags().StringVar(&extractExitsWithdrawalCreds, "withdrawal-credentials", "", "Withdrawal credentials (hex)")
 	extractVoluntaryExitsCmd.Flags().StringSliceVar(&extractExitsPubkeys, "pubkeys", []string{}, "Expected validator pubkeys (comma-separated)")
-	extractVoluntaryExitsCmd.Flags().StringVar(&extractExitsBeaconURL, "beacon", "", "Beacon node endpoint URL (e.g. 'http://localhost:5052')")
+	addBeaconFlags(extractVoluntaryExitsCmd, &extractExitsBeaconURLs)
+	extractVoluntaryExitsCmd.Flags().BoolVar(&extractExitsChecksums, "checksums", false, "Verify input files against the SHA256SUMS manifest in the input directory")
 
 	err := extractVoluntaryExitsCmd.MarkFlagRequired("input")
 	if err != nil {
@@ -89,8 +113,5 @@ func init() {
 		log.WithError(err).Fatalf("Failed to mark flag %s as required", "pubkeys")
 	}
 
-	err = extractVoluntaryExitsCmd.MarkFlagRequired("beacon")
-	if err != nil {
-		log.WithError(err).Fatalf("Failed to mark flag %s as required", "beacon")
-	}
+	extractVoluntaryExitsCmd.MarkFlagsOneRequired("beacon", "beacon-replay", "state")
 }
@@ -1,11 +1,14 @@
 package cmd
 
 import (
+	"context"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"runtime"
 	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
@@ -19,12 +22,22 @@ var (
 	voluntaryExitsInputPrefix           string
 	voluntaryExitsWithdrawCreds         string
 	voluntaryExitsPassphrase            string
-	voluntaryExitsBeaconURL             string
+	voluntaryExitsBeaconURLs            []string
+	voluntaryExitsNetwork               string
 	voluntaryDomainBlsToExecutionChange string
 	voluntaryExitsIterations            int
 	voluntaryExitsIndexStart            int
 	voluntaryExitsIndexOffset           int
+	voluntaryExitsIndexState            string
 	voluntaryExitsWorkers               int
+	voluntaryExitsHookBeforeRun         string
+	voluntaryExitsHookAfterKeystore     string
+	voluntaryExitsHookAfterRun          string
+	voluntaryExitsHookOnFailure         string
+	voluntaryExitsHookFailurePolicy     string
+	voluntaryExitsProgressFile          string
+	voluntaryExitsProgressFD            int
+	voluntaryExitsNoProgressBar         bool
 )
 
 var generateVoluntaryExitsCmd = &cobra.Command{
@@ -36,7 +49,25 @@ It requires ethdo, jq, and curl to be installed on the system.
 
 The command supports parallel processing using multiple workers, each with its own
 temporary directory for ethdo operations. The number of workers can be specified
-with the --workers flag, defaulting to the number of CPU cores.`,
+with the --workers flag, defaulting to the number of CPU cores.
+
+Hook commands can be run before the run, after each keystore, after the run and
+on failure. Each hook is run with 'sh -c' and receives a JSON document on stdin
+describing the event (keystore, pubkey, counts, output directory and error).
+--hook-failure-policy controls whether a failing hook aborts the run or only
+logs a warning.
+
+Progress (exits/sec, ETA, per-keystore and overall completion) can be written as
+newline-delimited JSON events to a file (--progress-file) or an inherited file
+descriptor (--progress-fd). When stderr is a terminal a live progress bar is
+rendered unless --no-progress-bar is set.
+
+With --network the beacon configuration (genesis validators root, fork versions
+and domains) is built from built-in network presets. Combined with --index-start
+no beacon node is needed at all, which allows generation on air-gapped machines.
+When both --network and --beacon are set, the node's genesis validators root,
+fork versions and domains are checked against the network before anything is
+signed.`,
 	RunE: runGenerateVoluntaryExits,
 }
 
@@ -54,11 +85,21 @@ func init() {
 	generateVoluntaryExitsCmd.Flags().StringVar(&voluntaryExitsInputPrefix, "prefix", "keystore-", "Prefix for input files to match")
 	generateVoluntaryExitsCmd.Flags().StringVar(&voluntaryExitsWithdrawCreds, "withdrawal-credentials", "", "Withdrawal credentials (hex)")
 	generateVoluntaryExitsCmd.Flags().StringVar(&voluntaryExitsPassphrase, "passphrase", "", "Passphrase for your keystore(s)")
-	generateVoluntaryExitsCmd.Flags().StringVar(&voluntaryExitsBeaconURL, "beacon", "", "Beacon node endpoint URL (e.g. 'http://localhost:5052')")
+	addBeaconFlags(generateVoluntaryExitsCmd, &voluntaryExitsBeaconURLs)
+	generateVoluntaryExitsCmd.Flags().StringVar(&voluntaryExitsNetwork, "network", "", "Build the beacon configuration from built-in presets for this network (mainnet, holesky, hoodi, sepolia, gnosis, chiado or ephemery) instead of fetching it; with --beacon, the node must match this network")
 	generateVoluntaryExitsCmd.Flags().IntVar(&voluntaryExitsIterations, "count", 50000, "Number of validators to process")
 	generateVoluntaryExitsCmd.Flags().IntVar(&voluntaryExitsIndexStart, "index-start", -1, "Starting validator index (optional, will query beacon node if not set)")
 	generateVoluntaryExitsCmd.Flags().IntVar(&voluntaryExitsIndexOffset, "index-offset", 0, "Offset to add to the starting validator index")
+	generateVoluntaryExitsCmd.Flags().StringVar(&voluntaryExitsIndexState, "index-state", "head", "Beacon state the highest validator index is looked up in (head, justified, finalized, a slot or a state root)")
 	generateVoluntaryExitsCmd.Flags().IntVar(&voluntaryExitsWorkers, "workers", defaultWorkers, "Number of parallel workers (default: number of CPU cores)")
+	generateVoluntaryExitsCmd.Flags().StringVar(&voluntaryExitsHookBeforeRun, "hook-before-run", "", "Command to run before generation starts")
+	generateVoluntaryExitsCmd.Flags().StringVar(&voluntaryExitsHookAfterKeystore, "hook-after-keystore", "", "Command to run after each keystore is processed")
+	generateVoluntaryExitsCmd.Flags().StringVar(&voluntaryExitsHookAfterRun, "hook-after-run", "", "Command to run after generation completes")
+	generateVoluntaryExitsCmd.Flags().StringVar(&voluntaryExitsHookOnFailure, "hook-on-failure", "", "Command to run when generation fails")
+	generateVoluntaryExitsCmd.Flags().StringVar(&voluntaryExitsHookFailurePolicy, "hook-failure-policy", string(validator.HookFailureAbort), "What to do when a hook fails (abort or warn)")
+	generateVoluntaryExitsCmd.Flags().StringVar(&voluntaryExitsProgressFile, "progress-file", "", "Append newline-delimited JSON progress events to this file")
+	generateVoluntaryExitsCmd.Flags().IntVar(&voluntaryExitsProgressFD, "progress-fd", -1, "Write newline-delimited JSON progress events to this file descriptor")
+	generateVoluntaryExitsCmd.Flags().BoolVar(&voluntaryExitsNoProgressBar, "no-progress-bar", false, "Disable the live progress bar in interactive terminals")
 	generateVoluntaryExitsCmd.Flags().StringVar(&voluntaryDomainBlsToExecutionChange, "domain-bls-to-execution-change", "", "BLS to execution change domain (optional, may be required as only some clients provide DOMAIN_BLS_TO_EXECUTION_CHANGE via /eth/v1/config/spec)")
 
 	if err := generateVoluntaryExitsCmd.MarkFlagRequired("output"); err != nil {
@@ -76,21 +117,74 @@ func init() {
 	if err := generateVoluntaryExitsCmd.MarkFlagRequired("passphrase"); err != nil {
 		panic(err)
 	}
+}
 
-	if err := generateVoluntaryExitsCmd.MarkFlagRequired("beacon"); err != nil {
-		panic(err)
+func runGenerateVoluntaryExits(cmd *cobra.Command, args []string) error {
+	hooks, err := validator.NewHooks(map[validator.HookEvent]string{
+		validator.HookBeforeRun:     voluntaryExitsHookBeforeRun,
+		validator.HookAfterKeystore: voluntaryExitsHookAfterKeystore,
+		validator.HookAfterRun:      voluntaryExitsHookAfterRun,
+		validator.HookOnFailure:     voluntaryExitsHookOnFailure,
+	}, voluntaryExitsHookFailurePolicy)
+	if err != nil {
+		return err
+	}
+
+	if err := generateVoluntaryExits(cmd.Context(), hooks); err != nil {
+		hookCtx := &validator.HookContext{
+			Event:     validator.HookOnFailure,
+			Count:     voluntaryExitsIterations,
+			OutputDir: voluntaryExitsOutputDir,
+			Error:     err.Error(),
+		}
+
+		// Report the keystore that failed, if the run got that far
+		var keystoreErr *validator.KeystoreError
+		if errors.As(err, &keystoreErr) {
+			hookCtx.Keystore = keystoreErr.Keystore
+			hookCtx.Pubkey = keystoreErr.Pubkey
+			hookCtx.KeystoreNumber = keystoreErr.KeystoreNumber
+			hookCtx.TotalKeystores = keystoreErr.TotalKeystores
+			hookCtx.StartIndex = keystoreErr.StartIndex
+		}
+
+		if hookErr := hooks.Run(hookCtx); hookErr != nil {
+			log.WithError(hookErr).Warn("Failure hook failed")
+		}
+
+		return err
 	}
+
+	return nil
 }
 
-func runGenerateVoluntaryExits(cmd *cobra.Command, args []string) error {
+func generateVoluntaryExits(ctx context.Context, hooks *validator.Hooks) error {
 	if voluntaryExitsWorkers < 1 {
 		return errors.New("number of workers must be at least 1")
 	}
 
+	if !haveBeacon(voluntaryExitsBeaconURLs) {
+		if voluntaryExitsNetwork == "" {
+			return errors.New("either --beacon or --network must be set")
+		}
+
+		if voluntaryExitsIndexStart < 0 {
+			return errors.New("--index-start is required when no --beacon is set")
+		}
+	}
+
 	if _, err := exec.LookPath("ethdo"); err != nil {
 		return errors.Errorf("Required command 'ethdo' not found. Please install it first.\nFor ethdo, please visit: https://github.com/wealdtech/ethdo")
 	}
 
+	if err := hooks.Run(&validator.HookContext{
+		Event:     validator.HookBeforeRun,
+		Count:     voluntaryExitsIterations,
+		OutputDir: voluntaryExitsOutputDir,
+	}); err != nil {
+		return err
+	}
+
 	if err := os.MkdirAll(voluntaryExitsOutputDir, 0o755); err != nil {
 		return errors.Wrap(err, "failed to create output directory")
 	}
@@ -129,31 +223,83 @@ func runGenerateVoluntaryExits(cmd *cobra.Command, args []string) error {
 		voluntaryExitsOutputDir,
 		voluntaryExitsWithdrawCreds,
 		voluntaryExitsPassphrase,
-		voluntaryExitsBeaconURL,
+		redactBeaconURLs(voluntaryExitsBeaconURLs),
 		voluntaryExitsIterations,
 		voluntaryExitsIndexStart,
 		voluntaryExitsIndexOffset,
 		voluntaryExitsWorkers,
 	)
 
+	if haveBeacon(voluntaryExitsBeaconURLs) {
+		if generator.Beacon, err = newBeaconClient(voluntaryExitsBeaconURLs, voluntaryExitsNetwork); err != nil {
+			return err
+		}
+	}
+
+	generator.Hooks = hooks
+	generator.IndexStateID = voluntaryExitsIndexState
+
 	// Set total number of keystores
 	generator.SetTotalKeystores(len(keystoreFiles))
 
-	startIdx, err := generator.GetValidatorStartIndex()
+	progressEvents, closeProgress, err := openProgressEvents()
 	if err != nil {
-		return errors.Wrap(err, "failed to get validator start index")
+		return err
+	}
+	defer closeProgress()
+
+	var progressBar io.Writer
+	if !voluntaryExitsNoProgressBar && isTerminal(os.Stderr) {
+		progressBar = os.Stderr
+	}
+
+	generator.Progress = validator.NewProgress(generator.TotalKeystores, voluntaryExitsIterations, progressEvents, progressBar)
+
+	// Log lines share stderr with the progress bar, so clear it around them
+	if progressBar != nil {
+		validatorLog := validator.GetLogger()
+
+		log.SetOutput(generator.Progress.LogWriter(os.Stderr))
+		validatorLog.SetOutput(generator.Progress.LogWriter(os.Stderr))
+
+		defer func() {
+			log.SetOutput(os.Stderr)
+			validatorLog.SetOutput(os.Stderr)
+		}()
+	}
+
+	// Make sure the beacon node is on the expected network before using it for
+	// validator indices or signing anything
+	if generator.Beacon != nil && voluntaryExitsNetwork != "" {
+		if err := validator.CheckBeaconNetwork(ctx, generator.Beacon, voluntaryExitsNetwork); err != nil {
+			return errors.Wrap(err, "beacon node network check failed")
+		}
 	}
 
-	config, err := generator.FetchBeaconConfig()
+	startIdx, err := generator.GetValidatorStartIndex(ctx)
 	if err != nil {
-		return errors.Wrap(err, "failed to fetch beacon configuration")
+		return errors.Wrap(err, "failed to get validator start index")
+	}
+
+	var config *validator.BeaconConfig
+
+	if voluntaryExitsNetwork != "" {
+		config, err = validator.NewBeaconConfigFromNetwork(voluntaryExitsNetwork)
+		if err != nil {
+			return errors.Wrap(err, "failed to build beacon configuration from network presets")
+		}
+	} else {
+		config, err = validator.FetchBeaconConfig(ctx, generator.Beacon)
+		if err != nil {
+			return errors.Wrap(err, "failed to fetch beacon configuration")
+		}
 	}
 
 	if voluntaryDomainBlsToExecutionChange != "" {
 		config.BlsToExecutionChangeDomain = voluntaryDomainBlsToExecutionChange
 	}
 
-	log.Info("Beacon configuration fetched successfully")
+	log.Info("Beacon configuration loaded successfully")
 	log.Infof("Latest validator index on chain: %d", startIdx)
 	log.Infof("Using %d workers for parallel processing", voluntaryExitsWorkers)
 	log.Infof("Processing %d keystores", len(keystoreFiles))
@@ -166,7 +312,85 @@ func runGenerateVoluntaryExits(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	generator.Progress.Finish()
+
+	ethdoVersion, err := validator.EthdoVersion()
+	if err != nil {
+		log.WithError(err).Warn("Failed to determine ethdo version")
+	}
+
+	manifest := &validator.Manifest{
+		CreatedAt: time.Now().UTC(),
+		ToolVersion: validator.VersionInfo{
+			Release:   Release,
+			GitCommit: GitCommit,
+			OS:        GOOS,
+			Arch:      GOARCH,
+		},
+		EthdoVersion:          ethdoVersion,
+		BeaconConfig:          config,
+		WithdrawalCredentials: voluntaryExitsWithdrawCreds,
+		StartIndex:            startIdx,
+		Count:                 voluntaryExitsIterations,
+		Pubkeys:               generator.Pubkeys,
+	}
+
+	if err := validator.WriteManifest(voluntaryExitsOutputDir, manifest); err != nil {
+		return errors.Wrap(err, "failed to write manifest")
+	}
+
+	if err := validator.UpdateChecksums(voluntaryExitsOutputDir, []string{validator.ManifestFileName}); err != nil {
+		return errors.Wrap(err, "failed to update checksum manifest")
+	}
+
+	if err := hooks.Run(&validator.HookContext{
+		Event:          validator.HookAfterRun,
+		TotalKeystores: int(generator.TotalKeystores),
+		Count:          voluntaryExitsIterations,
+		StartIndex:     startIdx,
+		OutputDir:      voluntaryExitsOutputDir,
+	}); err != nil {
+		return err
+	}
+
 	log.Infof("Processing complete. Processed %d iterations for each keystore.", voluntaryExitsIterations)
 
 	return nil
 }
+
+// openProgressEvents opens the destination for JSON progress events, if any
+func openProgressEvents() (io.Writer, func(), error) {
+	if voluntaryExitsProgressFile != "" && voluntaryExitsProgressFD >= 0 {
+		return nil, nil, errors.New("only one of --progress-file and --progress-fd can be set")
+	}
+
+	if voluntaryExitsProgressFile != "" {
+		f, err := os.OpenFile(voluntaryExitsProgressFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "failed to open progress file")
+		}
+
+		return f, func() { f.Close() }, nil
+	}
+
+	if voluntaryExitsProgressFD >= 0 {
+		f := os.NewFile(uintptr(voluntaryExitsProgressFD), "progress")
+		if _, err := f.Stat(); err != nil {
+			return nil, nil, errors.Wrapf(err, "invalid progress file descriptor %d", voluntaryExitsProgressFD)
+		}
+
+		return f, func() {}, nil
+	}
+
+	return nil, func() {}, nil
+}
+
+// isTerminal reports whether f is attached to an interactive terminal
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+
+	return info.Mode()&os.ModeCharDevice != 0
+}
@@ -3,19 +3,28 @@ package cmd
 import (
 	"os"
 
+	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+
+	"github.com/ethpandaops/validator-tools/pkg/validator"
 )
 
 var (
 	log = logrus.New()
+
+	networkConfigPath     string
+	genesisValidatorsRoot string
+	genesisStatePath      string
+	ephemerySource        string
 )
 
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
-	Use:   "validator-tools",
-	Short: "Runs validator tools.",
-	Long:  `Runs validator tools.`,
+	Use:               "validator-tools",
+	Short:             "Runs validator tools.",
+	Long:              `Runs validator tools.`,
+	PersistentPreRunE: loadNetworkConfig,
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
@@ -27,6 +36,62 @@ func Execute() {
 	}
 }
 
+func init() {
+	rootCmd.PersistentFlags().StringVar(&networkConfigPath, "network-config", "", "Path to a consensus-specs config.yaml for a custom network (e.g. a devnet)")
+	rootCmd.PersistentFlags().StringVar(&genesisValidatorsRoot, "genesis-validators-root", "", "Genesis validators root (hex) of the custom network")
+	rootCmd.PersistentFlags().StringVar(&ephemerySource, "ephemery-source", validator.DefaultEphemerySource, "Directory or base URL with the current Ephemery iteration's config.yaml and genesis_validators_root.txt (or genesis.ssz)")
+	rootCmd.PersistentFlags().StringVar(&genesisStatePath, "genesis-state", "", "Path to the custom network's genesis.ssz, used to derive the genesis validators root and genesis time")
+}
+
+// loadEphemery fetches the current Ephemery iteration from --ephemery-source,
+// with the same timeout, TLS and header options as the beacon nodes
+func loadEphemery() error {
+	opts, err := beaconOptions()
+	if err != nil {
+		return err
+	}
+
+	_, err = validator.LoadEphemeryConfig(ephemerySource, opts)
+
+	return err
+}
+
 func initCommon() {
 
 }
+
+// loadNetworkConfig registers the custom network from --network-config and makes
+// it the default for commands with a --network flag. With --network ephemery the
+// current Ephemery iteration is loaded up front.
+func loadNetworkConfig(cmd *cobra.Command, args []string) error {
+	if flag := cmd.Flags().Lookup("network"); flag != nil && flag.Value.String() == validator.EphemeryNetwork {
+		if err := loadEphemery(); err != nil {
+			return err
+		}
+	}
+
+	if networkConfigPath == "" {
+		if genesisValidatorsRoot != "" {
+			return errors.New("--genesis-validators-root requires --network-config")
+		}
+
+		if genesisStatePath != "" {
+			return errors.New("--genesis-state requires --network-config")
+		}
+
+		return nil
+	}
+
+	name, err := validator.LoadNetworkConfig(networkConfigPath, genesisValidatorsRoot, genesisStatePath)
+	if err != nil {
+		return err
+	}
+
+	if flag := cmd.Flags().Lookup("network"); flag != nil && !flag.Changed {
+		if err := cmd.Flags().Set("network", name); err != nil {
+			return errors.Wrap(err, "failed to set network")
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,15 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Run servers for testing",
+	Long:  `Run servers for testing, such as a mock beacon node.`,
+}
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+}
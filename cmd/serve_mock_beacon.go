@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/ethpandaops/validator-tools/pkg/beacon/mock"
+)
+
+var (
+	mockBeaconConfig   string
+	mockBeaconFixtures string
+	mockBeaconListen   string
+)
+
+var serveMockBeaconCmd = &cobra.Command{
+	Use:     "mock-beacon",
+	Aliases: []string{"mock_beacon"},
+	Short:   "Serve a mock beacon node",
+	Long: `Serves the beacon API endpoints used by validator-tools (genesis, fork, spec,
+validators, node syncing and the voluntary exit and BLS to execution change
+pools) from a YAML config or a fixture directory written by --beacon-record.
+Every state id is answered from the same state, and submitted exits and BLS
+to execution changes are added to the pools.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var (
+			state *mock.State
+			err   error
+		)
+
+		if mockBeaconConfig != "" {
+			state, err = mock.LoadConfig(mockBeaconConfig)
+		} else {
+			state, err = mock.LoadFixtures(mockBeaconFixtures)
+		}
+
+		if err != nil {
+			return errors.Wrap(err, "failed to load mock beacon state")
+		}
+
+		listener, err := net.Listen("tcp", mockBeaconListen)
+		if err != nil {
+			return errors.Wrap(err, "failed to listen")
+		}
+
+		server := &http.Server{
+			Handler:           mock.NewServer(state),
+			ReadHeaderTimeout: 10 * time.Second,
+		}
+
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		go func() {
+			<-ctx.Done()
+
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			if err := server.Shutdown(shutdownCtx); err != nil {
+				log.WithError(err).Warn("Failed to shut down mock beacon node")
+			}
+		}()
+
+		log.WithField("validators", len(state.Validators)).Infof("Serving mock beacon node on http://%s", listener.Addr())
+
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			return errors.Wrap(err, "mock beacon node failed")
+		}
+
+		return nil
+	},
+	SilenceUsage: true,
+}
+
+func init() {
+	serveCmd.AddCommand(serveMockBeaconCmd)
+
+	serveMockBeaconCmd.Flags().StringVar(&mockBeaconConfig, "config", "", "YAML file with the genesis, fork, spec and validators to serve")
+	serveMockBeaconCmd.Flags().StringVar(&mockBeaconFixtures, "fixtures", "", "Fixture directory written by --beacon-record to serve")
+	serveMockBeaconCmd.Flags().StringVar(&mockBeaconListen, "listen", "127.0.0.1:5052", "Address to listen on")
+
+	serveMockBeaconCmd.MarkFlagsOneRequired("config", "fixtures")
+	serveMockBeaconCmd.MarkFlagsMutuallyExclusive("config", "fixtures")
+}
@@ -41,6 +41,8 @@ var verifyDepositData verifyDepositDataFunc = func() error {
 	}
 
 	if err := depositData.Verify(); err != nil {
+		log.Infof("Run 'validator-tools diagnose deposit_data --input %s' to find the network and fork version the deposits were signed for", verifyDepositDataInput)
+
 		return errors.Wrap(err, "failed to verify deposit data")
 	}
 
@@ -73,8 +75,8 @@ func init() {
 	verifyCmd.AddCommand(verifyDepositDataCmd)
 
 	verifyDepositDataCmd.Flags().StringVar(&verifyDepositDataInput, "input", "", "Path to deposit data JSON file")
-	verifyDepositDataCmd.Flags().StringVar(&verifyExpectedNetwork, "network", "", "Expected network (e.g. mainnet, goerli)")
-	verifyDepositDataCmd.Flags().Uint64Var(&verifyExpectedAmount, "amount", 32000000000, "Expected deposit amount in Gwei")
+	verifyDepositDataCmd.Flags().StringVar(&verifyExpectedNetwork, "network", "", "Expected network (e.g. mainnet, gnosis)")
+	verifyDepositDataCmd.Flags().Uint64Var(&verifyExpectedAmount, "amount", 0, "Expected deposit amount in Gwei, or mGNO on gnosis and chiado (default: the network's activation amount, 32000000000)")
 	verifyDepositDataCmd.Flags().StringVar(&verifyExpectedWithdrawalCred, "withdrawal-credentials", "", "Expected withdrawal credentials (hex)")
 	verifyDepositDataCmd.Flags().IntVar(&verifyExpectedCount, "count", 0, "Expected number of deposits")
 
@@ -1,9 +1,14 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
+	"path/filepath"
+	"runtime"
+	"strings"
 
 	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/prysm/v5/consensus-types/primitives"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 
@@ -18,35 +23,143 @@ var (
 	verifyExitsPubkeys                 []string
 	verifyExitsSkipIndexMissmatchCheck bool
 	verifyExitsSkipMessage             bool
+	verifyExitsChecksums               bool
+	verifyExitsManifest                string
+	verifyExitsState                   string
+	verifyExitsStructural              bool
+	verifyExitsWorkers                 int
+	verifyExitsBatchSize               int
+	verifyExitsCollectAll              bool
+	verifyExitsDuplicates              string
+
+	// verifyExitsFirstIndex is the first validator index exits are expected
+	// for, only known from a manifest
+	verifyExitsFirstIndex = -1
 )
 
 var verifyVoluntaryExitsCmd = &cobra.Command{
 	Use:   "voluntary_exits",
 	Short: "Verify voluntary exit messages",
-	Long:  `Verify voluntary exit messages for Ethereum validators.`,
+	Long: `Verify voluntary exit messages for Ethereum validators.
+
+By default verification stops at the first problem. With --collect-all every
+check is run to completion and all findings, grouped by pubkey and file, are
+printed as JSON before the command exits non-zero.
+
+--structural-check without --state only checks the exit epoch against the
+network's current epoch. The registry rules and the Electra/Fulu rules, such
+as no pending partial withdrawal, are only checked with --state.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		exits, err := validator.NewVoluntaryExits(verifyExitsInput, verifyExitsNetwork, verifyExitsWithdrawalCreds, verifyExitsPubkeys)
+		if err := applyVerifyExitsManifest(cmd); err != nil {
+			return err
+		}
+
+		duplicatePolicy, err := validator.ParseDuplicatePolicy(verifyExitsDuplicates)
+		if err != nil {
+			return err
+		}
+
+		var checksums *validator.ChecksumReport
+
+		if verifyExitsChecksums {
+			report, err := validator.VerifyChecksums(verifyExitsInput)
+			if err != nil {
+				return errors.Wrap(err, "failed to verify checksum manifest")
+			}
+
+			if err := report.Err(); err != nil && !verifyExitsCollectAll {
+				return err
+			}
+
+			if report.OK() {
+				log.Info("Checksum manifest verified")
+			}
+
+			checksums = report
+		}
+
+		newExits := validator.NewVoluntaryExits
+		if verifyExitsCollectAll {
+			newExits = validator.CollectVoluntaryExits
+		}
+
+		exits, err := newExits(verifyExitsInput, verifyExitsNetwork, verifyExitsWithdrawalCreds, verifyExitsPubkeys)
 		if err != nil {
 			return errors.Wrap(err, "failed to verify exits")
 		}
 
+		if exits.Findings != nil && checksums != nil {
+			exits.Findings.AddChecksums(verifyExitsInput, checksums)
+		}
+
+		if verifyExitsState != "" {
+			stateFile, err := validator.LoadStateFile(verifyExitsState, verifyExitsNetwork)
+			if err != nil {
+				return errors.Wrap(err, "failed to load beacon state")
+			}
+
+			exits.UseStateFile(stateFile)
+		}
+
+		exits.CheckStructure = exits.CheckStructure || verifyExitsStructural
+
+		if exits.CheckStructure && verifyExitsState == "" {
+			log.Info("Without --state only the exit epoch is checked; the registry and Electra/Fulu rules need a beacon state")
+		}
+
+		exits.NumWorkers = verifyExitsWorkers
+		exits.BatchSize = verifyExitsBatchSize
+
+		err = exits.ValidateDuplicates(duplicatePolicy)
+		if err != nil {
+			return errors.Wrap(err, "failed to check for duplicate exits")
+		}
+
 		err = exits.ValidateCount(verifyExitsNumExits)
 		if err != nil {
 			return errors.Wrap(err, "failed to check exit count")
 		}
 
+		err = exits.ValidateFileNames()
+		if err != nil {
+			return errors.Wrap(err, "failed to check exit file names")
+		}
+
 		if !verifyExitsSkipIndexMissmatchCheck {
 			err = exits.ValidateIndices()
 			if err != nil {
 				return errors.Wrap(err, "failed to check exit indices")
 			}
+
+			if verifyExitsFirstIndex >= 0 {
+				err = exits.ValidateRange(primitives.ValidatorIndex(verifyExitsFirstIndex), verifyExitsNumExits)
+				if err != nil {
+					return errors.Wrap(err, "failed to check exit indices against manifest")
+				}
+			}
 		}
 
 		rsp, err := exits.Verify()
 		if err != nil {
+			// A manifest next to the exits tells which Ephemery iteration they were signed for
+			if verifyExitsNetwork == validator.EphemeryNetwork && verifyExitsManifest == "" {
+				if manifest, mErr := validator.LoadManifest(filepath.Join(verifyExitsInput, validator.ManifestFileName)); mErr == nil {
+					manifest.EarlierEphemeryIteration()
+				}
+			}
+
+			log.Infof("Run 'validator-tools diagnose voluntary_exits --input %s --network %s' to find the network and fork version the exits were signed for",
+				verifyExitsInput, verifyExitsNetwork)
+
 			return errors.Wrap(err, "failed to verify exits")
 		}
 
+		if exits.Findings != nil {
+			if err := reportFindings(exits.Findings); err != nil {
+				return err
+			}
+		}
+
 		if !verifyExitsSkipMessage {
 			log.WithFields(logrus.Fields{
 				"first_validator_index": rsp.FirstIndex,
@@ -62,36 +175,148 @@ var verifyVoluntaryExitsCmd = &cobra.Command{
 
 		return nil
 	},
+	SilenceUsage: true,
 }
 
 func init() {
+	// Default to number of CPU threads if possible, otherwise use 1
+	defaultWorkers := runtime.NumCPU()
+	if defaultWorkers < 1 {
+		defaultWorkers = 1
+	}
+
 	verifyCmd.AddCommand(verifyVoluntaryExitsCmd)
 
 	verifyVoluntaryExitsCmd.Flags().StringVar(&verifyExitsInput, "input", "", "Path to directory containing exit files")
-	verifyVoluntaryExitsCmd.Flags().StringVar(&verifyExitsNetwork, "network", "", "Network (mainnet, holesky or hoodi)")
+	verifyVoluntaryExitsCmd.Flags().StringVar(&verifyExitsNetwork, "network", "", "Network (mainnet, holesky, hoodi, sepolia, gnosis, chiado or ephemery)")
 	verifyVoluntaryExitsCmd.Flags().StringVar(&verifyExitsWithdrawalCreds, "withdrawal-credentials", "", "Withdrawal credentials (hex)")
 	verifyVoluntaryExitsCmd.Flags().IntVar(&verifyExitsNumExits, "count", 0, "Number of exits that should have been generated")
 	verifyVoluntaryExitsCmd.Flags().StringSliceVar(&verifyExitsPubkeys, "pubkeys", []string{}, "Expected validator pubkeys (comma-separated)")
 	verifyVoluntaryExitsCmd.Flags().BoolVar(&verifyExitsSkipIndexMissmatchCheck, "skip-index-missmatch-check", false, "Skip validator index missmatch check")
 	verifyVoluntaryExitsCmd.Flags().BoolVar(&verifyExitsSkipMessage, "skip-check-message", false, "Skip check message")
+	verifyVoluntaryExitsCmd.Flags().BoolVar(&verifyExitsChecksums, "checksums", false, "Verify files against the SHA256SUMS manifest in the input directory")
+	verifyVoluntaryExitsCmd.Flags().StringVar(&verifyExitsState, "state", "", "SSZ beacon state file to check exits against, required for the registry and Electra/Fulu rules (implies --structural-check)")
+	verifyVoluntaryExitsCmd.Flags().BoolVar(&verifyExitsStructural, "structural-check", false, "Also check exits against the rules the chain applies when they are submitted, not only their signatures (only the exit epoch without --state)")
+	verifyVoluntaryExitsCmd.Flags().IntVar(&verifyExitsWorkers, "workers", defaultWorkers, "Number of parallel signature verification workers (default: number of CPU cores)")
+	verifyVoluntaryExitsCmd.Flags().IntVar(&verifyExitsBatchSize, "batch-size", validator.DefaultVerifyBatchSize, "Number of signatures verified at once; a failing batch is rechecked one signature at a time")
+	verifyVoluntaryExitsCmd.Flags().BoolVar(&verifyExitsCollectAll, "collect-all", false, "Run every check instead of stopping at the first problem, and print all findings as JSON")
+	verifyVoluntaryExitsCmd.Flags().StringVar(&verifyExitsDuplicates, "duplicates", string(validator.DuplicateFail), "What to do with duplicate or conflicting exits for the same validator index (fail or tolerate)")
+	verifyVoluntaryExitsCmd.Flags().StringVar(&verifyExitsManifest, "manifest", "", "Path to a generation manifest.json to take the network, withdrawal credentials, pubkeys and count from")
 
 	err := verifyVoluntaryExitsCmd.MarkFlagRequired("input")
 	if err != nil {
 		log.WithError(err).Fatalf("Failed to mark flag %s as required", "input")
 	}
+}
+
+// applyVerifyExitsManifest fills in the expected values from --manifest, or
+// checks that they were given as flags when no manifest is used.
+func applyVerifyExitsManifest(cmd *cobra.Command) error {
+	if verifyExitsManifest == "" {
+		for _, name := range []string{"network", "withdrawal-credentials", "pubkeys"} {
+			if !cmd.Flags().Changed(name) {
+				return errors.Errorf("required flag \"%s\" not set (or use --manifest)", name)
+			}
+		}
+
+		return nil
+	}
 
-	err = verifyVoluntaryExitsCmd.MarkFlagRequired("network")
+	manifest, err := validator.LoadManifest(verifyExitsManifest)
 	if err != nil {
-		log.WithError(err).Fatalf("Failed to mark flag %s as required", "network")
+		return errors.Wrap(err, "failed to load manifest")
 	}
 
-	err = verifyVoluntaryExitsCmd.MarkFlagRequired("withdrawal-credentials")
+	// Ephemery's genesis changes with every iteration, so the current one is
+	// only loaded once the manifest turns out to be for Ephemery
+	if manifest.Ephemery() && !validator.EphemeryLoaded() {
+		if err := loadEphemery(); err != nil {
+			return errors.Wrap(err, "failed to load the current Ephemery iteration for the manifest, check --ephemery-source")
+		}
+	}
+
+	network, err := manifest.Network()
 	if err != nil {
-		log.WithError(err).Fatalf("Failed to mark flag %s as required", "withdrawal-credentials")
+		return errors.Wrap(err, "failed to determine network from manifest")
+	}
+
+	if cmd.Flags().Changed("network") && verifyExitsNetwork != network {
+		return errors.Errorf("--network %s conflicts with manifest network %s", verifyExitsNetwork, network)
+	}
+
+	if cmd.Flags().Changed("withdrawal-credentials") && normalizeHex(verifyExitsWithdrawalCreds) != normalizeHex(manifest.WithdrawalCredentials) {
+		return errors.Errorf("--withdrawal-credentials %s conflicts with manifest withdrawal credentials %s",
+			verifyExitsWithdrawalCreds, manifest.WithdrawalCredentials)
+	}
+
+	if cmd.Flags().Changed("pubkeys") && !samePubkeys(verifyExitsPubkeys, manifest.Pubkeys) {
+		return errors.New("--pubkeys conflicts with manifest pubkeys")
+	}
+
+	if cmd.Flags().Changed("count") && verifyExitsNumExits != manifest.Count {
+		return errors.Errorf("--count %d conflicts with manifest count %d", verifyExitsNumExits, manifest.Count)
 	}
 
-	err = verifyVoluntaryExitsCmd.MarkFlagRequired("pubkeys")
+	verifyExitsNetwork = network
+	verifyExitsWithdrawalCreds = manifest.WithdrawalCredentials
+	verifyExitsPubkeys = manifest.Pubkeys
+	verifyExitsNumExits = manifest.Count
+	verifyExitsFirstIndex = manifest.StartIndex + 1
+
+	log.WithFields(logrus.Fields{
+		"manifest":      verifyExitsManifest,
+		"network":       network,
+		"pubkeys":       len(manifest.Pubkeys),
+		"count":         manifest.Count,
+		"first_index":   verifyExitsFirstIndex,
+		"tool_version":  manifest.ToolVersion.Release,
+		"ethdo_version": manifest.EthdoVersion,
+	}).Info("Using expected values from manifest")
+
+	return nil
+}
+
+// reportFindings logs the findings, prints them as JSON grouped by pubkey and
+// file and fails if there are any
+func reportFindings(findings *validator.Findings) error {
+	report := findings.Report()
+	if report.Total == 0 {
+		return nil
+	}
+
+	findings.Log()
+
+	out, err := json.MarshalIndent(report, "", "  ")
 	if err != nil {
-		log.WithError(err).Fatalf("Failed to mark flag %s as required", "pubkeys")
+		return errors.Wrap(err, "failed to marshal findings")
 	}
+
+	fmt.Println(string(out))
+
+	return report.Err()
+}
+
+// normalizeHex lowercases a hex string and strips any 0x prefix
+func normalizeHex(s string) string {
+	return strings.ToLower(strings.TrimPrefix(s, "0x"))
+}
+
+// samePubkeys reports whether two pubkey lists contain the same keys
+func samePubkeys(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	set := make(map[string]bool, len(a))
+	for _, pubkey := range a {
+		set[normalizeHex(pubkey)] = true
+	}
+
+	for _, pubkey := range b {
+		if !set[normalizeHex(pubkey)] {
+			return false
+		}
+	}
+
+	return true
 }
@@ -0,0 +1,87 @@
+// Package atomicfile writes files so that readers never observe a partial
+// file and a completed write survives a crash.
+package atomicfile
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+)
+
+// Write streams the content produced by write to a temporary file in the
+// destination directory, fsyncs it, renames it into place and fsyncs the
+// directory, so the rename is durable as well.
+func Write(path string, perm os.FileMode, write func(io.Writer) error) (err error) {
+	dir := filepath.Dir(path)
+
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return errors.Wrapf(err, "failed to create temporary file for %s", path)
+	}
+
+	tmpPath := tmp.Name()
+
+	defer func() {
+		if err != nil {
+			tmp.Close()
+			os.Remove(tmpPath)
+		}
+	}()
+
+	buf := bufio.NewWriter(tmp)
+
+	if err = write(buf); err != nil {
+		return err
+	}
+
+	if err = buf.Flush(); err != nil {
+		return errors.Wrapf(err, "failed to write temporary file for %s", path)
+	}
+
+	if err = tmp.Chmod(perm); err != nil {
+		return errors.Wrapf(err, "failed to set permissions on temporary file for %s", path)
+	}
+
+	if err = tmp.Sync(); err != nil {
+		return errors.Wrapf(err, "failed to sync temporary file for %s", path)
+	}
+
+	if err = tmp.Close(); err != nil {
+		return errors.Wrapf(err, "failed to close temporary file for %s", path)
+	}
+
+	if err = os.Rename(tmpPath, path); err != nil {
+		return errors.Wrapf(err, "failed to rename temporary file to %s", path)
+	}
+
+	return syncDir(dir)
+}
+
+// WriteFile atomically writes data to path
+func WriteFile(path string, data []byte, perm os.FileMode) error {
+	return Write(path, perm, func(w io.Writer) error {
+		if _, err := w.Write(data); err != nil {
+			return errors.Wrapf(err, "failed to write temporary file for %s", path)
+		}
+
+		return nil
+	})
+}
+
+// syncDir fsyncs a directory so a preceding rename survives a crash.
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return errors.Wrapf(err, "failed to open directory %s", dir)
+	}
+	defer d.Close()
+
+	if err := d.Sync(); err != nil {
+		return errors.Wrapf(err, "failed to sync directory %s", dir)
+	}
+
+	return nil
+}
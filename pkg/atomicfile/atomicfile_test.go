@@ -0,0 +1,77 @@
+package atomicfile
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "1-abc.json")
+
+	require.NoError(t, WriteFile(path, []byte(`{"first": true}`), 0o600))
+	require.NoError(t, WriteFile(path, []byte(`{"second": true}`), 0o600))
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, `{"second": true}`, string(content))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	// No temporary files should be left behind
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func TestWriteFileFailureCleansUp(t *testing.T) {
+	dir := t.TempDir()
+
+	// Renaming a file over a directory fails
+	target := filepath.Join(dir, "target")
+	require.NoError(t, os.Mkdir(target, 0o755))
+
+	err := WriteFile(target, []byte("data"), 0o600)
+	require.Error(t, err)
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+
+	// Missing destination directory
+	err = WriteFile(filepath.Join(dir, "missing", "file.json"), []byte("data"), 0o600)
+	assert.Error(t, err)
+}
+
+func TestWriteStreamFailureCleansUp(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "validators.json")
+
+	require.NoError(t, WriteFile(path, []byte("recorded"), 0o600))
+
+	// A failing stream leaves the previous file in place
+	err := Write(path, 0o600, func(w io.Writer) error {
+		if _, err := w.Write([]byte("partial")); err != nil {
+			return err
+		}
+
+		return errors.New("stream interrupted")
+	})
+	require.EqualError(t, err, "stream interrupted")
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "recorded", string(content))
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
@@ -0,0 +1,360 @@
+package beacon
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultTimeout is the timeout of a single beacon API request. Fetching the
+// full validator set of a large network can take minutes.
+const DefaultTimeout = 10 * time.Minute
+
+// Client is the subset of the beacon node API used by validator-tools
+type Client interface {
+	// Genesis returns the genesis of the chain
+	Genesis(ctx context.Context) (*Genesis, error)
+	// Fork returns the fork of a state, e.g. "head" or "finalized"
+	Fork(ctx context.Context, stateID string) (*Fork, error)
+	// Spec returns the node's config spec
+	Spec(ctx context.Context) (Spec, error)
+	// Validators returns the validators of a state, filtered by index or
+	// pubkey when ids are given
+	Validators(ctx context.Context, stateID string, ids []string) ([]*Validator, error)
+	// StreamValidators calls fn for each validator of a state as it is
+	// decoded, filtered by index or pubkey when ids are given
+	StreamValidators(ctx context.Context, stateID string, ids []string, fn func(*Validator) error) error
+	// Validator returns a single validator of a state by index or pubkey
+	Validator(ctx context.Context, stateID, id string) (*Validator, error)
+	// NodeSyncing returns the sync status of the node
+	NodeSyncing(ctx context.Context) (*SyncStatus, error)
+	// VoluntaryExits returns the voluntary exits in the node's pool
+	VoluntaryExits(ctx context.Context) ([]*SignedVoluntaryExit, error)
+	// SubmitVoluntaryExit submits a voluntary exit to the node's pool
+	SubmitVoluntaryExit(ctx context.Context, exit *SignedVoluntaryExit) error
+	// BLSToExecutionChanges returns the BLS to execution changes in the node's pool
+	BLSToExecutionChanges(ctx context.Context) ([]*SignedBLSToExecutionChange, error)
+	// SubmitBLSToExecutionChanges submits BLS to execution changes to the node's pool
+	SubmitBLSToExecutionChanges(ctx context.Context, changes []*SignedBLSToExecutionChange) error
+	// Events subscribes to topics and calls handler for every event until ctx
+	// is done, the stream ends or handler returns an error
+	Events(ctx context.Context, topics []string, handler func(*Event) error) error
+}
+
+// APIError is a non-200 response of the beacon node
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	if e.Message == "" {
+		return "HTTP request failed with status: " + http.StatusText(e.StatusCode)
+	}
+
+	return "HTTP request failed with status: " + http.StatusText(e.StatusCode) + ": " + e.Message
+}
+
+// IsNotFound reports whether err is a 404 response of the beacon node
+func IsNotFound(err error) bool {
+	var apiErr *APIError
+
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
+
+// HTTPClient is a Client talking to a beacon node over its REST API
+type HTTPClient struct {
+	baseURL string
+	name    string
+	headers http.Header
+	client  *http.Client
+}
+
+var _ Client = (*HTTPClient)(nil)
+
+// NewClient returns a client for the beacon node at baseURL. Basic auth
+// credentials in baseURL are sent in the Authorization header. opts may be nil.
+func NewClient(baseURL string, opts *Options) (*HTTPClient, error) {
+	if opts == nil {
+		opts = &Options{}
+	}
+
+	name := RedactURL(baseURL)
+
+	u, err := url.Parse(baseURL)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return nil, errors.Errorf("invalid beacon node URL %s", name)
+	}
+
+	headers := http.Header{}
+	for key, value := range opts.Headers {
+		headers.Set(key, value)
+	}
+
+	if u.User != nil {
+		if opts.BearerToken != "" {
+			return nil, errors.Errorf("beacon node URL %s has basic auth credentials and a bearer token is set", name)
+		}
+
+		password, _ := u.User.Password()
+		req := &http.Request{Header: http.Header{}}
+		req.SetBasicAuth(u.User.Username(), password)
+		headers.Set("Authorization", req.Header.Get("Authorization"))
+
+		u.User = nil
+	}
+
+	if opts.BearerToken != "" {
+		headers.Set("Authorization", "Bearer "+opts.BearerToken)
+	}
+
+	timeout := opts.Timeout
+	if timeout == 0 {
+		timeout = DefaultTimeout
+	}
+
+	transport, err := opts.transport()
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to configure TLS for beacon node %s", name)
+	}
+
+	return &HTTPClient{
+		baseURL: strings.TrimSuffix(u.String(), "/"),
+		name:    name,
+		headers: headers,
+		client:  &http.Client{Timeout: timeout, Transport: transport},
+	}, nil
+}
+
+// Name returns the URL of the beacon node with credentials and path redacted
+func (c *HTTPClient) Name() string {
+	return c.name
+}
+
+func (c *HTTPClient) Genesis(ctx context.Context) (*Genesis, error) {
+	genesis := &Genesis{}
+	if err := c.get(ctx, "/eth/v1/beacon/genesis", nil, genesis); err != nil {
+		return nil, errors.Wrap(err, "failed to fetch genesis")
+	}
+
+	return genesis, nil
+}
+
+func (c *HTTPClient) Fork(ctx context.Context, stateID string) (*Fork, error) {
+	fork := &Fork{}
+	if err := c.get(ctx, "/eth/v1/beacon/states/"+url.PathEscape(stateID)+"/fork", nil, fork); err != nil {
+		return nil, errors.Wrap(err, "failed to fetch fork")
+	}
+
+	return fork, nil
+}
+
+func (c *HTTPClient) Spec(ctx context.Context) (Spec, error) {
+	spec := Spec{}
+	if err := c.get(ctx, "/eth/v1/config/spec", nil, &spec); err != nil {
+		return nil, errors.Wrap(err, "failed to fetch spec")
+	}
+
+	return spec, nil
+}
+
+func (c *HTTPClient) Validators(ctx context.Context, stateID string, ids []string) ([]*Validator, error) {
+	var validators []*Validator
+
+	err := c.StreamValidators(ctx, stateID, ids, func(v *Validator) error {
+		validators = append(validators, v)
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return validators, nil
+}
+
+func (c *HTTPClient) Validator(ctx context.Context, stateID, id string) (*Validator, error) {
+	validator := &Validator{}
+	if err := c.get(ctx, "/eth/v1/beacon/states/"+url.PathEscape(stateID)+"/validators/"+url.PathEscape(id), nil, validator); err != nil {
+		return nil, errors.Wrapf(err, "failed to fetch validator %s", id)
+	}
+
+	return validator, nil
+}
+
+func (c *HTTPClient) NodeSyncing(ctx context.Context) (*SyncStatus, error) {
+	status := &SyncStatus{}
+	if err := c.get(ctx, "/eth/v1/node/syncing", nil, status); err != nil {
+		return nil, errors.Wrap(err, "failed to fetch sync status")
+	}
+
+	return status, nil
+}
+
+func (c *HTTPClient) VoluntaryExits(ctx context.Context) ([]*SignedVoluntaryExit, error) {
+	var exits []*SignedVoluntaryExit
+	if err := c.get(ctx, "/eth/v1/beacon/pool/voluntary_exits", nil, &exits); err != nil {
+		return nil, errors.Wrap(err, "failed to fetch voluntary exit pool")
+	}
+
+	return exits, nil
+}
+
+func (c *HTTPClient) SubmitVoluntaryExit(ctx context.Context, exit *SignedVoluntaryExit) error {
+	return errors.Wrap(c.post(ctx, "/eth/v1/beacon/pool/voluntary_exits", exit), "failed to submit voluntary exit")
+}
+
+func (c *HTTPClient) BLSToExecutionChanges(ctx context.Context) ([]*SignedBLSToExecutionChange, error) {
+	var changes []*SignedBLSToExecutionChange
+	if err := c.get(ctx, "/eth/v1/beacon/pool/bls_to_execution_changes", nil, &changes); err != nil {
+		return nil, errors.Wrap(err, "failed to fetch BLS to execution change pool")
+	}
+
+	return changes, nil
+}
+
+func (c *HTTPClient) SubmitBLSToExecutionChanges(ctx context.Context, changes []*SignedBLSToExecutionChange) error {
+	return errors.Wrap(c.post(ctx, "/eth/v1/beacon/pool/bls_to_execution_changes", changes), "failed to submit BLS to execution changes")
+}
+
+// get fetches path and decodes the data field of the response into out
+func (c *HTTPClient) get(ctx context.Context, path string, query url.Values, out interface{}) error {
+	body, err := c.do(ctx, http.MethodGet, path, query, nil)
+	if err != nil {
+		return err
+	}
+
+	var resp struct {
+		Data json.RawMessage `json:"data"`
+	}
+
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return errors.Wrap(err, "failed to parse response")
+	}
+
+	if err := json.Unmarshal(resp.Data, out); err != nil {
+		return errors.Wrap(err, "failed to parse response data")
+	}
+
+	return nil
+}
+
+// post sends in as the JSON body of a request to path
+func (c *HTTPClient) post(ctx context.Context, path string, in interface{}) error {
+	body, err := json.Marshal(in)
+	if err != nil {
+		return errors.Wrap(err, "failed to encode request")
+	}
+
+	_, err = c.do(ctx, http.MethodPost, path, nil, body)
+
+	return err
+}
+
+// stream sends a request and passes the response body to read
+func (c *HTTPClient) stream(ctx context.Context, method, path string, query url.Values, body []byte, read func(io.Reader) error) error {
+	resp, err := c.send(ctx, c.client, method, path, query, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return read(resp.Body)
+}
+
+func (c *HTTPClient) do(ctx context.Context, method, path string, query url.Values, body []byte) ([]byte, error) {
+	resp, err := c.send(ctx, c.client, method, path, query, body)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read response body")
+	}
+
+	log.WithFields(logrus.Fields{
+		"method": method,
+		"path":   path,
+		"bytes":  len(data),
+	}).Debug("Beacon API request completed")
+
+	return data, nil
+}
+
+// send sends a request and returns the response if it has a 200 status
+func (c *HTTPClient) send(ctx context.Context, client *http.Client, method, path string, query url.Values, body []byte) (*http.Response, error) {
+	target := c.baseURL + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	var reader io.Reader = http.NoBody
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, target, reader)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create request")
+	}
+
+	for key, values := range c.headers {
+		req.Header[key] = values
+	}
+
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	log.WithFields(logrus.Fields{
+		"method": method,
+		"path":   path,
+	}).Debug("Sending beacon API request")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		// Transport errors include the request URL, which may hold secrets
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			urlErr.URL = c.name + path
+		}
+
+		return nil, errors.Wrap(err, "failed to send request")
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+
+		return nil, &APIError{StatusCode: resp.StatusCode, Message: errorMessage(resp.Body)}
+	}
+
+	return resp, nil
+}
+
+// errorMessage extracts the message of a beacon API error response
+func errorMessage(body io.Reader) string {
+	data, err := io.ReadAll(io.LimitReader(body, 4096))
+	if err != nil {
+		return ""
+	}
+
+	var apiErr struct {
+		Message string `json:"message"`
+	}
+
+	if err := json.Unmarshal(data, &apiErr); err == nil && apiErr.Message != "" {
+		return apiErr.Message
+	}
+
+	return strings.TrimSpace(string(data))
+}
@@ -0,0 +1,185 @@
+package beacon
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestServer serves fixed responses keyed by request path
+func newTestServer(t *testing.T, responses map[string]string) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		resp, ok := responses[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			_, err := w.Write([]byte(`{"code":404,"message":"not found"}`))
+			require.NoError(t, err)
+
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+
+		_, err := w.Write([]byte(resp))
+		require.NoError(t, err)
+	}))
+
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func newTestClient(t *testing.T, url string, opts *Options) *HTTPClient {
+	t.Helper()
+
+	client, err := NewClient(url, opts)
+	require.NoError(t, err)
+
+	return client
+}
+
+func TestClientGenesisForkSpec(t *testing.T) {
+	server := newTestServer(t, map[string]string{
+		"/eth/v1/beacon/genesis": `{"data":{
+			"genesis_time":"1742213400",
+			"genesis_validators_root":"0x212f13fc4df078b6cb7db228f1c8307566dcecf900867401a92023d7ba99cb5f",
+			"genesis_fork_version":"0x10000910"}}`,
+		"/eth/v1/beacon/states/head/fork": `{"data":{"previous_version":"0x60000910","current_version":"0x70000910","epoch":"50688"}}`,
+		"/eth/v1/config/spec":             `{"data":{"CAPELLA_FORK_EPOCH":"0","CONFIG_NAME":"hoodi","BLOB_SCHEDULE":[{"EPOCH":"1"}]}}`,
+	})
+
+	client := newTestClient(t, server.URL+"/", nil)
+	ctx := context.Background()
+
+	genesis, err := client.Genesis(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1742213400), genesis.GenesisTime)
+	assert.Equal(t, "0x10000910", genesis.GenesisForkVersion)
+
+	fork, err := client.Fork(ctx, "head")
+	require.NoError(t, err)
+	assert.Equal(t, &Fork{PreviousVersion: "0x60000910", CurrentVersion: "0x70000910", Epoch: 50688}, fork)
+
+	spec, err := client.Spec(ctx)
+	require.NoError(t, err)
+
+	epoch, err := spec.Uint64("CAPELLA_FORK_EPOCH")
+	require.NoError(t, err)
+	assert.Equal(t, uint64(0), epoch)
+
+	name, err := spec.String("CONFIG_NAME")
+	require.NoError(t, err)
+	assert.Equal(t, "hoodi", name)
+
+	_, err = spec.String("BLOB_SCHEDULE")
+	assert.Error(t, err)
+
+	_, err = spec.Uint64("CONFIG_NAME")
+	assert.Error(t, err)
+
+	_, err = spec.String("MISSING")
+	assert.Error(t, err)
+}
+
+func TestClientErrors(t *testing.T) {
+	server := newTestServer(t, map[string]string{
+		"/eth/v1/beacon/genesis": `invalid json`,
+	})
+
+	client := newTestClient(t, server.URL, nil)
+	ctx := context.Background()
+
+	_, err := client.Genesis(ctx)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to parse response")
+	assert.False(t, IsNotFound(err))
+
+	_, err = client.NodeSyncing(ctx)
+	require.Error(t, err)
+	assert.True(t, IsNotFound(err))
+	assert.Contains(t, err.Error(), "not found")
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+
+	_, err = client.Genesis(cancelled)
+	require.Error(t, err)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestClientSyncing(t *testing.T) {
+	server := newTestServer(t, map[string]string{
+		"/eth/v1/node/syncing": `{"data":{"head_slot":"100","sync_distance":"2","is_syncing":true,"is_optimistic":false,"el_offline":false}}`,
+	})
+
+	status, err := newTestClient(t, server.URL, nil).NodeSyncing(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, &SyncStatus{HeadSlot: 100, SyncDistance: 2, IsSyncing: true}, status)
+}
+
+func TestClientPools(t *testing.T) {
+	var submitted []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+
+			body, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+
+			submitted = append(submitted, r.URL.Path+" "+string(body))
+
+			return
+		}
+
+		var err error
+
+		switch r.URL.Path {
+		case "/eth/v1/beacon/pool/voluntary_exits":
+			_, err = w.Write([]byte(`{"data":[{"message":{"epoch":"194048","validator_index":"1"},"signature":"0x01"}]}`))
+		case "/eth/v1/beacon/pool/bls_to_execution_changes":
+			_, err = w.Write([]byte(`{"data":[{"message":{"validator_index":"2","from_bls_pubkey":"0xaa","to_execution_address":"0xbb"},"signature":"0x02"}]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+
+		require.NoError(t, err)
+	}))
+	defer server.Close()
+
+	client := newTestClient(t, server.URL, nil)
+	ctx := context.Background()
+
+	exits, err := client.VoluntaryExits(ctx)
+	require.NoError(t, err)
+	require.Len(t, exits, 1)
+	assert.Equal(t, VoluntaryExit{Epoch: 194048, ValidatorIndex: 1}, exits[0].Message)
+
+	changes, err := client.BLSToExecutionChanges(ctx)
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	assert.Equal(t, uint64(2), changes[0].Message.ValidatorIndex)
+
+	require.NoError(t, client.SubmitVoluntaryExit(ctx, exits[0]))
+	require.NoError(t, client.SubmitBLSToExecutionChanges(ctx, changes))
+
+	exitJSON, err := json.Marshal(exits[0])
+	require.NoError(t, err)
+
+	changesJSON, err := json.Marshal(changes)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"/eth/v1/beacon/pool/voluntary_exits " + string(exitJSON),
+		"/eth/v1/beacon/pool/bls_to_execution_changes " + string(changesJSON),
+	}, submitted)
+	assert.Contains(t, string(exitJSON), `"validator_index":"1"`)
+}
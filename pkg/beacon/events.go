@@ -0,0 +1,65 @@
+package beacon
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// maxEventSize is the largest server-sent event line accepted
+const maxEventSize = 16 * 1024 * 1024
+
+func (c *HTTPClient) Events(ctx context.Context, topics []string, handler func(*Event) error) error {
+	if len(topics) == 0 {
+		return errors.New("at least one event topic is required")
+	}
+
+	// The stream is long-lived, so only the context bounds it
+	client := &http.Client{Transport: c.client.Transport}
+
+	resp, err := c.send(ctx, client, http.MethodGet, "/eth/v1/events", url.Values{"topics": {strings.Join(topics, ",")}}, nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to subscribe to events")
+	}
+	defer resp.Body.Close()
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxEventSize)
+
+	event := &Event{}
+
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		switch {
+		case line == "":
+			if event.Topic == "" && len(event.Data) == 0 {
+				continue
+			}
+
+			if err := handler(event); err != nil {
+				return err
+			}
+
+			event = &Event{}
+		case strings.HasPrefix(line, "event:"):
+			event.Topic = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			if len(event.Data) > 0 {
+				event.Data = append(event.Data, '\n')
+			}
+
+			event.Data = append(event.Data, strings.TrimSpace(strings.TrimPrefix(line, "data:"))...)
+		}
+	}
+
+	if ctx.Err() != nil {
+		return nil
+	}
+
+	return errors.Wrap(scanner.Err(), "failed to read event stream")
+}
@@ -0,0 +1,51 @@
+package beacon
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientEvents(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "head,voluntary_exit", r.URL.Query().Get("topics"))
+
+		w.Header().Set("Content-Type", "text/event-stream")
+
+		_, err := w.Write([]byte(": keepalive\n\n" +
+			"event: head\ndata: {\"slot\":\"10\"}\n\n" +
+			"event: voluntary_exit\ndata: {\"message\":{\"epoch\":\"1\",\"validator_index\":\"2\"},\"signature\":\"0x01\"}\n\n"))
+		require.NoError(t, err)
+	}))
+	defer server.Close()
+
+	client := newTestClient(t, server.URL, nil)
+
+	var events []*Event
+
+	err := client.Events(context.Background(), []string{"head", "voluntary_exit"}, func(event *Event) error {
+		events = append(events, event)
+
+		return nil
+	})
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	assert.Equal(t, "head", events[0].Topic)
+	assert.JSONEq(t, `{"slot":"10"}`, string(events[0].Data))
+	assert.Equal(t, "voluntary_exit", events[1].Topic)
+
+	// A handler error stops the subscription
+	stop := errors.New("stop")
+
+	err = client.Events(context.Background(), []string{"head", "voluntary_exit"}, func(*Event) error {
+		return stop
+	})
+	assert.ErrorIs(t, err, stop)
+
+	assert.Error(t, client.Events(context.Background(), nil, nil))
+}
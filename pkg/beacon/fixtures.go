@@ -0,0 +1,472 @@
+package beacon
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/ethpandaops/validator-tools/pkg/atomicfile"
+)
+
+// Fixtures are stored as beacon API responses, {"data": ...}, one file per
+// request. Error responses, such as the 404s of validator index probes, are
+// stored as {"code": ..., "message": ...} in a .error.json file.
+
+var unsafeFixtureChars = regexp.MustCompile(`[^A-Za-z0-9]+`)
+
+func genesisFixture() string { return "genesis.json" }
+
+func specFixture() string { return "spec.json" }
+
+func syncingFixture() string { return "node_syncing.json" }
+
+func voluntaryExitsFixture() string { return "pool_voluntary_exits.json" }
+
+func blsToExecutionChangesFixture() string { return "pool_bls_to_execution_changes.json" }
+
+func forkFixture(stateID string) string {
+	return "fork_" + fixturePart(stateID) + ".json"
+}
+
+func validatorFixture(stateID, id string) string {
+	return "validator_" + fixturePart(stateID) + "_" + fixturePart(id) + ".json"
+}
+
+// validatorsFixture names a validators request; id filters are hashed to keep
+// names short
+func validatorsFixture(stateID string, ids []string) string {
+	if len(ids) == 0 {
+		return "validators_" + fixturePart(stateID) + ".json"
+	}
+
+	encoded, _ := json.Marshal(ids)
+	sum := sha256.Sum256(encoded)
+
+	return "validators_" + fixturePart(stateID) + "_" + hex.EncodeToString(sum[:8]) + ".json"
+}
+
+func fixturePart(value string) string {
+	return strings.Trim(unsafeFixtureChars.ReplaceAllString(value, "_"), "_")
+}
+
+func errorFixture(name string) string {
+	return strings.TrimSuffix(name, ".json") + ".error.json"
+}
+
+// Recorder is a Client that records every response of another client to a
+// fixture directory, for later replay. Events and submissions are passed
+// through without being recorded.
+type Recorder struct {
+	client Client
+	dir    string
+}
+
+var _ Client = (*Recorder)(nil)
+
+// NewRecorder returns a client recording the responses of client to dir
+func NewRecorder(client Client, dir string) (*Recorder, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, errors.Wrap(err, "failed to create fixture directory")
+	}
+
+	return &Recorder{client: client, dir: dir}, nil
+}
+
+func (r *Recorder) Genesis(ctx context.Context) (*Genesis, error) {
+	genesis, err := r.client.Genesis(ctx)
+
+	return genesis, r.record(genesisFixture(), genesis, err)
+}
+
+func (r *Recorder) Fork(ctx context.Context, stateID string) (*Fork, error) {
+	fork, err := r.client.Fork(ctx, stateID)
+
+	return fork, r.record(forkFixture(stateID), fork, err)
+}
+
+func (r *Recorder) Spec(ctx context.Context) (Spec, error) {
+	spec, err := r.client.Spec(ctx)
+
+	return spec, r.record(specFixture(), spec, err)
+}
+
+func (r *Recorder) Validators(ctx context.Context, stateID string, ids []string) ([]*Validator, error) {
+	var validators []*Validator
+
+	err := r.StreamValidators(ctx, stateID, ids, func(v *Validator) error {
+		validators = append(validators, v)
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return validators, nil
+}
+
+// StreamValidators writes validators to the fixture as they stream in
+func (r *Recorder) StreamValidators(ctx context.Context, stateID string, ids []string, fn func(*Validator) error) error {
+	name := validatorsFixture(stateID, ids)
+
+	var streamErr error
+
+	err := atomicfile.Write(filepath.Join(r.dir, name), 0o600, func(w io.Writer) error {
+		if _, err := io.WriteString(w, `{"data":[`); err != nil {
+			return err
+		}
+
+		enc := json.NewEncoder(w)
+		first := true
+
+		streamErr = r.client.StreamValidators(ctx, stateID, ids, func(v *Validator) error {
+			if !first {
+				if _, err := io.WriteString(w, ","); err != nil {
+					return err
+				}
+			}
+
+			first = false
+
+			if err := enc.Encode(v); err != nil {
+				return err
+			}
+
+			return fn(v)
+		})
+		if streamErr != nil {
+			return streamErr
+		}
+
+		_, err := io.WriteString(w, "]}\n")
+
+		return err
+	})
+	if streamErr != nil {
+		return r.record(name, nil, streamErr)
+	}
+
+	if err != nil {
+		return errors.Wrapf(err, "failed to record %s", name)
+	}
+
+	logFixture("Recorded beacon response", filepath.Join(r.dir, name))
+
+	return nil
+}
+
+func (r *Recorder) Validator(ctx context.Context, stateID, id string) (*Validator, error) {
+	validator, err := r.client.Validator(ctx, stateID, id)
+
+	return validator, r.record(validatorFixture(stateID, id), validator, err)
+}
+
+func (r *Recorder) NodeSyncing(ctx context.Context) (*SyncStatus, error) {
+	status, err := r.client.NodeSyncing(ctx)
+
+	return status, r.record(syncingFixture(), status, err)
+}
+
+func (r *Recorder) VoluntaryExits(ctx context.Context) ([]*SignedVoluntaryExit, error) {
+	exits, err := r.client.VoluntaryExits(ctx)
+
+	return exits, r.record(voluntaryExitsFixture(), exits, err)
+}
+
+func (r *Recorder) SubmitVoluntaryExit(ctx context.Context, exit *SignedVoluntaryExit) error {
+	return r.client.SubmitVoluntaryExit(ctx, exit)
+}
+
+func (r *Recorder) BLSToExecutionChanges(ctx context.Context) ([]*SignedBLSToExecutionChange, error) {
+	changes, err := r.client.BLSToExecutionChanges(ctx)
+
+	return changes, r.record(blsToExecutionChangesFixture(), changes, err)
+}
+
+func (r *Recorder) SubmitBLSToExecutionChanges(ctx context.Context, changes []*SignedBLSToExecutionChange) error {
+	return r.client.SubmitBLSToExecutionChanges(ctx, changes)
+}
+
+func (r *Recorder) Events(ctx context.Context, topics []string, handler func(*Event) error) error {
+	return r.client.Events(ctx, topics, handler)
+}
+
+// record writes a response, or an API error, to the fixture name and returns
+// the request's error. Other errors, such as connection failures, aren't
+// responses and are not recorded.
+func (r *Recorder) record(name string, value interface{}, err error) error {
+	var apiErr *APIError
+
+	switch {
+	case err == nil:
+		path := filepath.Join(r.dir, name)
+
+		if writeErr := atomicfile.Write(path, 0o600, func(w io.Writer) error {
+			enc := json.NewEncoder(w)
+			enc.SetIndent("", "  ")
+
+			return enc.Encode(map[string]interface{}{"data": value})
+		}); writeErr != nil {
+			return errors.Wrapf(writeErr, "failed to record %s", name)
+		}
+
+		// A successful response replaces an earlier error
+		_ = os.Remove(filepath.Join(r.dir, errorFixture(name)))
+
+		logFixture("Recorded beacon response", path)
+
+		return nil
+	case errors.As(err, &apiErr):
+		path := filepath.Join(r.dir, errorFixture(name))
+
+		if writeErr := atomicfile.Write(path, 0o600, func(w io.Writer) error {
+			return json.NewEncoder(w).Encode(map[string]interface{}{"code": apiErr.StatusCode, "message": apiErr.Message})
+		}); writeErr != nil {
+			return errors.Wrapf(writeErr, "failed to record %s", name)
+		}
+
+		logFixture("Recorded beacon error response", path)
+
+		return err
+	default:
+		return err
+	}
+}
+
+// Replayer is a Client answering from a fixture directory written by a
+// Recorder, without any network access
+type Replayer struct {
+	dir string
+}
+
+var _ Client = (*Replayer)(nil)
+
+// NewReplayer returns a client replaying the fixtures in dir
+func NewReplayer(dir string) (*Replayer, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open fixture directory")
+	}
+
+	if !info.IsDir() {
+		return nil, errors.Errorf("fixture path %s is not a directory", dir)
+	}
+
+	return &Replayer{dir: dir}, nil
+}
+
+func (r *Replayer) Genesis(_ context.Context) (*Genesis, error) {
+	genesis := &Genesis{}
+
+	return genesis, r.load(genesisFixture(), genesis)
+}
+
+func (r *Replayer) Fork(_ context.Context, stateID string) (*Fork, error) {
+	fork := &Fork{}
+
+	return fork, r.load(forkFixture(stateID), fork)
+}
+
+func (r *Replayer) Spec(_ context.Context) (Spec, error) {
+	spec := Spec{}
+
+	return spec, r.load(specFixture(), &spec)
+}
+
+func (r *Replayer) Validators(ctx context.Context, stateID string, ids []string) ([]*Validator, error) {
+	var validators []*Validator
+
+	err := r.StreamValidators(ctx, stateID, ids, func(v *Validator) error {
+		validators = append(validators, v)
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return validators, nil
+}
+
+func (r *Replayer) StreamValidators(_ context.Context, stateID string, ids []string, fn func(*Validator) error) error {
+	return r.open(validatorsFixture(stateID, ids), func(body io.Reader) error {
+		return decodeValidators(body, fn)
+	})
+}
+
+func (r *Replayer) Validator(_ context.Context, stateID, id string) (*Validator, error) {
+	validator := &Validator{}
+
+	return validator, r.load(validatorFixture(stateID, id), validator)
+}
+
+func (r *Replayer) NodeSyncing(_ context.Context) (*SyncStatus, error) {
+	status := &SyncStatus{}
+
+	return status, r.load(syncingFixture(), status)
+}
+
+func (r *Replayer) VoluntaryExits(_ context.Context) ([]*SignedVoluntaryExit, error) {
+	var exits []*SignedVoluntaryExit
+
+	return exits, r.load(voluntaryExitsFixture(), &exits)
+}
+
+func (r *Replayer) SubmitVoluntaryExit(context.Context, *SignedVoluntaryExit) error {
+	return errors.New("cannot submit voluntary exits while replaying beacon fixtures")
+}
+
+func (r *Replayer) BLSToExecutionChanges(_ context.Context) ([]*SignedBLSToExecutionChange, error) {
+	var changes []*SignedBLSToExecutionChange
+
+	return changes, r.load(blsToExecutionChangesFixture(), &changes)
+}
+
+func (r *Replayer) SubmitBLSToExecutionChanges(context.Context, []*SignedBLSToExecutionChange) error {
+	return errors.New("cannot submit BLS to execution changes while replaying beacon fixtures")
+}
+
+func (r *Replayer) Events(context.Context, []string, func(*Event) error) error {
+	return errors.New("events cannot be replayed from beacon fixtures")
+}
+
+// RecordedValidators calls fn with every validator in the recorded
+// validators responses, of any state and filter, e.g. to seed a mock beacon
+// node. Validators recorded more than once are passed once per response.
+func (r *Replayer) RecordedValidators(fn func(*Validator) error) error {
+	entries, err := os.ReadDir(r.dir)
+	if err != nil {
+		return errors.Wrap(err, "failed to read fixture directory")
+	}
+
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || strings.HasSuffix(name, ".error.json") || !strings.HasSuffix(name, ".json") {
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(name, "validators_"):
+			err = r.open(name, func(body io.Reader) error {
+				return decodeValidators(body, fn)
+			})
+		case strings.HasPrefix(name, "validator_"):
+			validator := &Validator{}
+			if err = r.load(name, validator); err == nil {
+				err = fn(validator)
+			}
+		default:
+			continue
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// load decodes the data of the fixture name into out
+func (r *Replayer) load(name string, out interface{}) error {
+	return r.open(name, func(body io.Reader) error {
+		var resp struct {
+			Data json.RawMessage `json:"data"`
+		}
+
+		if err := json.NewDecoder(body).Decode(&resp); err != nil {
+			return errors.Wrapf(err, "failed to parse fixture %s", name)
+		}
+
+		return errors.Wrapf(json.Unmarshal(resp.Data, out), "failed to parse fixture %s", name)
+	})
+}
+
+// open passes the body of the fixture name to read, or returns the recorded
+// API error. Each fixture used is logged with its SHA-256, so it can be shown
+// afterwards which data a run was based on.
+func (r *Replayer) open(name string, read func(io.Reader) error) error {
+	path := filepath.Join(r.dir, name)
+
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return r.replayError(name)
+	}
+
+	if err != nil {
+		return errors.Wrapf(err, "failed to open fixture %s", name)
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if err := read(bufio.NewReader(io.TeeReader(file, hash))); err != nil {
+		return err
+	}
+
+	// Hash anything the decoder didn't need to read
+	if _, err := io.Copy(hash, file); err != nil {
+		return errors.Wrapf(err, "failed to read fixture %s", name)
+	}
+
+	log.WithFields(logrus.Fields{
+		"fixture": path,
+		"sha256":  hex.EncodeToString(hash.Sum(nil)),
+	}).Info("Replayed beacon response")
+
+	return nil
+}
+
+func (r *Replayer) replayError(name string) error {
+	path := filepath.Join(r.dir, errorFixture(name))
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return errors.Errorf("no recorded beacon response %s in %s", name, r.dir)
+	}
+
+	if err != nil {
+		return errors.Wrapf(err, "failed to read fixture %s", path)
+	}
+
+	apiErr := &APIError{}
+
+	var recorded struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	}
+
+	if err := json.Unmarshal(data, &recorded); err != nil {
+		return errors.Wrapf(err, "failed to parse fixture %s", path)
+	}
+
+	apiErr.StatusCode, apiErr.Message = recorded.Code, recorded.Message
+
+	log.WithField("fixture", path).Debug("Replayed beacon error response")
+
+	return apiErr
+}
+
+// logFixture logs a fixture file with its SHA-256
+func logFixture(msg, path string) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return
+	}
+
+	sum := sha256.Sum256(data)
+
+	log.WithFields(logrus.Fields{
+		"fixture": path,
+		"sha256":  hex.EncodeToString(sum[:]),
+	}).Info(msg)
+}
@@ -0,0 +1,152 @@
+package beacon
+
+import (
+	"context"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordReplay(t *testing.T) {
+	server := newTestServer(t, map[string]string{
+		"/eth/v1/beacon/genesis": `{"data":{
+			"genesis_time":"1742213400",
+			"genesis_validators_root":"0x212f13fc4df078b6cb7db228f1c8307566dcecf900867401a92023d7ba99cb5f",
+			"genesis_fork_version":"0x10000910"}}`,
+		"/eth/v1/beacon/states/head/fork": `{"data":{"previous_version":"0x60000910","current_version":"0x70000910","epoch":"50688"}}`,
+		"/eth/v1/config/spec":             `{"data":{"CAPELLA_FORK_EPOCH":"0","CONFIG_NAME":"hoodi"}}`,
+	})
+
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	recorder, err := NewRecorder(newTestClient(t, server.URL, nil), dir)
+	require.NoError(t, err)
+
+	genesis, err := recorder.Genesis(ctx)
+	require.NoError(t, err)
+
+	fork, err := recorder.Fork(ctx, "head")
+	require.NoError(t, err)
+
+	spec, err := recorder.Spec(ctx)
+	require.NoError(t, err)
+
+	_, err = recorder.NodeSyncing(ctx)
+	require.True(t, IsNotFound(err))
+
+	server.Close()
+
+	replayer, err := NewReplayer(dir)
+	require.NoError(t, err)
+
+	replayedGenesis, err := replayer.Genesis(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, genesis, replayedGenesis)
+
+	replayedFork, err := replayer.Fork(ctx, "head")
+	require.NoError(t, err)
+	assert.Equal(t, fork, replayedFork)
+
+	replayedSpec, err := replayer.Spec(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, spec, replayedSpec)
+
+	// Recorded API errors are replayed as such
+	_, err = replayer.NodeSyncing(ctx)
+	assert.True(t, IsNotFound(err))
+
+	// Requests that weren't recorded fail
+	_, err = replayer.Fork(ctx, "finalized")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no recorded beacon response fork_finalized.json")
+
+	assert.Error(t, replayer.SubmitVoluntaryExit(ctx, &SignedVoluntaryExit{}))
+}
+
+func TestRecordReplayValidators(t *testing.T) {
+	registry := &validatorServer{count: 100}
+	server := httptest.NewServer(registry)
+
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	recorder, err := NewRecorder(newTestClient(t, server.URL, nil), dir)
+	require.NoError(t, err)
+
+	all, err := recorder.Validators(ctx, "finalized", nil)
+	require.NoError(t, err)
+	require.Len(t, all, 100)
+
+	some, err := recorder.Validators(ctx, "finalized", []string{"3", "7", "1000"})
+	require.NoError(t, err)
+	require.Len(t, some, 2)
+
+	highest, err := HighestValidatorIndex(ctx, recorder, "finalized")
+	require.NoError(t, err)
+
+	server.Close()
+
+	replayer, err := NewReplayer(dir)
+	require.NoError(t, err)
+
+	replayedAll, err := replayer.Validators(ctx, "finalized", nil)
+	require.NoError(t, err)
+	assert.Equal(t, all, replayedAll)
+
+	replayedSome, err := replayer.Validators(ctx, "finalized", []string{"3", "7", "1000"})
+	require.NoError(t, err)
+	assert.Equal(t, some, replayedSome)
+
+	// The same probes are answered, including the 404s of missing validators
+	replayedHighest, err := HighestValidatorIndex(ctx, replayer, "finalized")
+	require.NoError(t, err)
+	assert.Equal(t, highest, replayedHighest)
+
+	_, err = replayer.Validators(ctx, "finalized", []string{"3"})
+	assert.Error(t, err)
+}
+
+func TestRecordReplayFailedStream(t *testing.T) {
+	dir := t.TempDir()
+
+	recorder, err := NewRecorder(&stubClient{err: &APIError{StatusCode: 500, Message: "internal error"}}, dir)
+	require.NoError(t, err)
+
+	_, err = recorder.Validators(context.Background(), "head", nil)
+	require.Error(t, err)
+
+	// No partial response is left behind, only the recorded error
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "validators_head.error.json", entries[0].Name())
+
+	replayer, err := NewReplayer(dir)
+	require.NoError(t, err)
+
+	_, err = replayer.Validators(context.Background(), "head", nil)
+
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, 500, apiErr.StatusCode)
+}
+
+func TestNewReplayer(t *testing.T) {
+	_, err := NewReplayer(filepath.Join(t.TempDir(), "missing"))
+	assert.Error(t, err)
+}
+
+func TestFixtureNames(t *testing.T) {
+	assert.Equal(t, "fork_head.json", forkFixture("head"))
+	assert.Equal(t, "validator_finalized_0x12ab.json", validatorFixture("finalized", "0x12ab"))
+	assert.Equal(t, "validators_0x1234.json", validatorsFixture("0x1234", nil))
+	assert.Equal(t, "validators_head.error.json", errorFixture(validatorsFixture("head", nil)))
+	assert.NotEqual(t, validatorsFixture("head", []string{"1", "2"}), validatorsFixture("head", []string{"1,2"}))
+	assert.NotEqual(t, validatorsFixture("head", []string{"1"}), validatorsFixture("head", []string{"2"}))
+	assert.Equal(t, "fork_a_b.json", forkFixture("../a/b"))
+}
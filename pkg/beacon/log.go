@@ -0,0 +1,12 @@
+package beacon
+
+import (
+	"github.com/sirupsen/logrus"
+)
+
+var log = logrus.New()
+
+// SetLogger sets the logger used by the beacon client
+func SetLogger(logger *logrus.Logger) {
+	log = logger
+}
@@ -0,0 +1,304 @@
+package mock
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"math"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+
+	"github.com/ethpandaops/validator-tools/pkg/beacon"
+)
+
+const (
+	// DefaultBalance is the balance of seeded validators without one, in Gwei
+	DefaultBalance = 32000000000
+	// DefaultStatus is the status of seeded validators without one
+	DefaultStatus = "active_ongoing"
+
+	farFutureEpoch = math.MaxUint64
+)
+
+// Config is the YAML a mock beacon node is seeded from. Hex values may be
+// written unquoted.
+type Config struct {
+	Genesis struct {
+		GenesisTime           uint64 `yaml:"genesis_time"`
+		GenesisValidatorsRoot string `yaml:"genesis_validators_root"`
+		GenesisForkVersion    string `yaml:"genesis_fork_version"`
+	} `yaml:"genesis"`
+	Fork struct {
+		PreviousVersion string `yaml:"previous_version"`
+		CurrentVersion  string `yaml:"current_version"`
+		Epoch           uint64 `yaml:"epoch"`
+	} `yaml:"fork"`
+	// Spec is served as /eth/v1/config/spec, with every value as a string
+	Spec    map[string]yaml.Node `yaml:"spec"`
+	Syncing struct {
+		HeadSlot     uint64 `yaml:"head_slot"`
+		SyncDistance uint64 `yaml:"sync_distance"`
+		IsSyncing    bool   `yaml:"is_syncing"`
+		IsOptimistic bool   `yaml:"is_optimistic"`
+		ELOffline    bool   `yaml:"el_offline"`
+	} `yaml:"syncing"`
+	// Count adds validators with default values and generated pubkeys after
+	// the listed Validators
+	Count      int               `yaml:"count"`
+	Validators []ValidatorConfig `yaml:"validators"`
+}
+
+// ValidatorConfig is a validator of a mock beacon node. Validators without an
+// index follow the previous one, without a pubkey get a generated one, and
+// are active with a 32 ETH balance by default.
+type ValidatorConfig struct {
+	Index                 *uint64 `yaml:"index"`
+	Pubkey                string  `yaml:"pubkey"`
+	WithdrawalCredentials string  `yaml:"withdrawal_credentials"`
+	Status                string  `yaml:"status"`
+	Balance               *uint64 `yaml:"balance"`
+	EffectiveBalance      *uint64 `yaml:"effective_balance"`
+	Slashed               bool    `yaml:"slashed"`
+	ActivationEpoch       uint64  `yaml:"activation_epoch"`
+	ExitEpoch             *uint64 `yaml:"exit_epoch"`
+	WithdrawableEpoch     *uint64 `yaml:"withdrawable_epoch"`
+}
+
+// LoadConfig reads the YAML config at path into the state of a mock beacon
+// node
+func LoadConfig(path string) (*State, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read mock beacon config")
+	}
+
+	config := &Config{}
+	if err := yaml.Unmarshal(data, config); err != nil {
+		return nil, errors.Wrap(err, "failed to parse mock beacon config")
+	}
+
+	return config.State()
+}
+
+// State returns the state of a mock beacon node seeded from the config
+func (c *Config) State() (*State, error) {
+	state := &State{
+		Genesis: beacon.Genesis{
+			GenesisTime:           c.Genesis.GenesisTime,
+			GenesisValidatorsRoot: c.Genesis.GenesisValidatorsRoot,
+			GenesisForkVersion:    c.Genesis.GenesisForkVersion,
+		},
+		Fork: beacon.Fork{
+			PreviousVersion: c.Fork.PreviousVersion,
+			CurrentVersion:  c.Fork.CurrentVersion,
+			Epoch:           c.Fork.Epoch,
+		},
+		Spec: beacon.Spec{},
+		Syncing: beacon.SyncStatus{
+			HeadSlot:     c.Syncing.HeadSlot,
+			SyncDistance: c.Syncing.SyncDistance,
+			IsSyncing:    c.Syncing.IsSyncing,
+			IsOptimistic: c.Syncing.IsOptimistic,
+			ELOffline:    c.Syncing.ELOffline,
+		},
+	}
+
+	for key, node := range c.Spec {
+		value, err := json.Marshal(specValue(&node))
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid spec value %s", key)
+		}
+
+		state.Spec[key] = value
+	}
+
+	configs := append([]ValidatorConfig{}, c.Validators...)
+	for i := 0; i < c.Count; i++ {
+		configs = append(configs, ValidatorConfig{})
+	}
+
+	seen := make(map[uint64]bool, len(configs))
+	next := uint64(0)
+
+	for _, vc := range configs {
+		v := vc.validator(next)
+		if seen[v.Index] {
+			return nil, errors.Errorf("duplicate validator index %d", v.Index)
+		}
+
+		seen[v.Index] = true
+		next = v.Index + 1
+
+		state.Validators = append(state.Validators, v)
+	}
+
+	sort.Slice(state.Validators, func(i, j int) bool {
+		return state.Validators[i].Index < state.Validators[j].Index
+	})
+
+	return state, nil
+}
+
+// validator returns the validator of the config, with index if none is set
+func (vc *ValidatorConfig) validator(index uint64) *beacon.Validator {
+	if vc.Index != nil {
+		index = *vc.Index
+	}
+
+	v := &beacon.Validator{
+		Index:   index,
+		Balance: valueOr(vc.Balance, DefaultBalance),
+		Status:  vc.Status,
+		Validator: beacon.ValidatorData{
+			Pubkey:                vc.Pubkey,
+			WithdrawalCredentials: vc.WithdrawalCredentials,
+			EffectiveBalance:      valueOr(vc.EffectiveBalance, valueOr(vc.Balance, DefaultBalance)),
+			Slashed:               vc.Slashed,
+			ActivationEpoch:       vc.ActivationEpoch,
+			ExitEpoch:             valueOr(vc.ExitEpoch, farFutureEpoch),
+			WithdrawableEpoch:     valueOr(vc.WithdrawableEpoch, farFutureEpoch),
+		},
+	}
+
+	if v.Status == "" {
+		v.Status = DefaultStatus
+	}
+
+	if v.Validator.Pubkey == "" {
+		v.Validator.Pubkey = generatedPubkey(index)
+	}
+
+	if v.Validator.WithdrawalCredentials == "" {
+		v.Validator.WithdrawalCredentials = "0x" + strings.Repeat("00", 32)
+	}
+
+	return v
+}
+
+// LoadFixtures returns the state of a mock beacon node seeded from a fixture
+// directory written by beacon.Recorder. The fork is taken from the head,
+// finalized or justified state, whichever was recorded, and the validators
+// from every recorded validators response.
+func LoadFixtures(dir string) (*State, error) {
+	replayer, err := beacon.NewReplayer(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := context.Background()
+	state := &State{}
+
+	genesis, err := replayer.Genesis(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	state.Genesis = *genesis
+
+	for _, stateID := range []string{"head", "finalized", "justified"} {
+		fork, err := replayer.Fork(ctx, stateID)
+		if err == nil {
+			state.Fork = *fork
+
+			break
+		}
+
+		if stateID == "justified" {
+			return nil, err
+		}
+	}
+
+	if state.Spec, err = replayer.Spec(ctx); err != nil {
+		return nil, err
+	}
+
+	// Optional, a node is synced without pools by default
+	if syncing, err := replayer.NodeSyncing(ctx); err == nil {
+		state.Syncing = *syncing
+	}
+
+	if exits, err := replayer.VoluntaryExits(ctx); err == nil {
+		state.VoluntaryExits = exits
+	}
+
+	if changes, err := replayer.BLSToExecutionChanges(ctx); err == nil {
+		state.BLSToExecutionChanges = changes
+	}
+
+	byIndex := map[uint64]*beacon.Validator{}
+
+	if err := replayer.RecordedValidators(func(v *beacon.Validator) error {
+		byIndex[v.Index] = v
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	for _, v := range byIndex {
+		state.Validators = append(state.Validators, v)
+	}
+
+	sort.Slice(state.Validators, func(i, j int) bool {
+		return state.Validators[i].Index < state.Validators[j].Index
+	})
+
+	return state, nil
+}
+
+// specValue converts a YAML spec value to its beacon API form, in which
+// scalars are strings
+func specValue(node *yaml.Node) interface{} {
+	switch node.Kind {
+	case yaml.DocumentNode, yaml.AliasNode:
+		if len(node.Content) > 0 {
+			return specValue(node.Content[0])
+		}
+
+		if node.Alias != nil {
+			return specValue(node.Alias)
+		}
+
+		return nil
+	case yaml.SequenceNode:
+		values := make([]interface{}, 0, len(node.Content))
+		for _, item := range node.Content {
+			values = append(values, specValue(item))
+		}
+
+		return values
+	case yaml.MappingNode:
+		values := make(map[string]interface{}, len(node.Content)/2)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			values[node.Content[i].Value] = specValue(node.Content[i+1])
+		}
+
+		return values
+	default:
+		return node.Value
+	}
+}
+
+// generatedPubkey returns a recognisable placeholder pubkey for index
+func generatedPubkey(index uint64) string {
+	pubkey := make([]byte, 48)
+	for i := 0; i < 8; i++ {
+		pubkey[47-i] = byte(index >> (8 * i))
+	}
+
+	pubkey[0] = 0xaa
+
+	return "0x" + hex.EncodeToString(pubkey)
+}
+
+func valueOr(value *uint64, fallback uint64) uint64 {
+	if value == nil {
+		return fallback
+	}
+
+	return *value
+}
@@ -0,0 +1,163 @@
+package mock
+
+import (
+	"context"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethpandaops/validator-tools/pkg/beacon"
+)
+
+const testConfig = `
+genesis:
+  genesis_time: 1742213400
+  genesis_validators_root: 0x212f13fc4df078b6cb7db228f1c8307566dcecf900867401a92023d7ba99cb5f
+  genesis_fork_version: 0x10000910
+fork:
+  previous_version: 0x60000910
+  current_version: 0x70000910
+  epoch: 50688
+spec:
+  CONFIG_NAME: hoodi
+  CAPELLA_FORK_VERSION: 0x40000910
+  SECONDS_PER_SLOT: 12
+  BLOB_SCHEDULE:
+    - EPOCH: 1
+      MAX_BLOBS_PER_BLOCK: 6
+validators:
+  - pubkey: 0xa1d1ad0714035353258038e964ae9675dc0252ee22cea896825c01458e1807bfad2f9969338798548d9858a571f7425c
+    withdrawal_credentials: 0x010000000000000000000000abcdef0123456789abcdef0123456789abcdef01
+  - status: exited_unslashed
+    exit_epoch: 100
+  - index: 10
+count: 2
+`
+
+func writeConfig(t *testing.T, config string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "mock.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(config), 0o600))
+
+	return path
+}
+
+func TestLoadConfig(t *testing.T) {
+	state, err := LoadConfig(writeConfig(t, testConfig))
+	require.NoError(t, err)
+
+	assert.Equal(t, beacon.Genesis{
+		GenesisTime:           1742213400,
+		GenesisValidatorsRoot: "0x212f13fc4df078b6cb7db228f1c8307566dcecf900867401a92023d7ba99cb5f",
+		GenesisForkVersion:    "0x10000910",
+	}, state.Genesis)
+	assert.Equal(t, beacon.Fork{PreviousVersion: "0x60000910", CurrentVersion: "0x70000910", Epoch: 50688}, state.Fork)
+
+	version, err := state.Spec.String("CAPELLA_FORK_VERSION")
+	require.NoError(t, err)
+	assert.Equal(t, "0x40000910", version)
+
+	seconds, err := state.Spec.Uint64("SECONDS_PER_SLOT")
+	require.NoError(t, err)
+	assert.Equal(t, uint64(12), seconds)
+
+	assert.JSONEq(t, `[{"EPOCH":"1","MAX_BLOBS_PER_BLOCK":"6"}]`, string(state.Spec["BLOB_SCHEDULE"]))
+
+	indices := make([]uint64, 0, len(state.Validators))
+	for _, v := range state.Validators {
+		indices = append(indices, v.Index)
+	}
+
+	assert.Equal(t, []uint64{0, 1, 10, 11, 12}, indices)
+
+	first := state.Validators[0]
+	assert.Equal(t, DefaultStatus, first.Status)
+	assert.Equal(t, uint64(DefaultBalance), first.Balance)
+	assert.Equal(t, uint64(DefaultBalance), first.Validator.EffectiveBalance)
+	assert.Equal(t, "0x010000000000000000000000abcdef0123456789abcdef0123456789abcdef01", first.Validator.WithdrawalCredentials)
+	assert.Equal(t, uint64(farFutureEpoch), first.Validator.ExitEpoch)
+
+	exited := state.Validators[1]
+	assert.Equal(t, "exited_unslashed", exited.Status)
+	assert.Equal(t, uint64(100), exited.Validator.ExitEpoch)
+	assert.Len(t, exited.Validator.Pubkey, 98)
+	assert.NotEqual(t, exited.Validator.Pubkey, state.Validators[2].Validator.Pubkey)
+}
+
+func TestLoadConfigErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		errMsg string
+	}{
+		{
+			name:   "invalid yaml",
+			config: "validators: {",
+			errMsg: "failed to parse mock beacon config",
+		},
+		{
+			name:   "duplicate index",
+			config: "validators:\n  - index: 1\n  - index: 1\n",
+			errMsg: "duplicate validator index 1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadConfig(writeConfig(t, tt.config))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errMsg)
+		})
+	}
+
+	_, err := LoadConfig(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.Error(t, err)
+}
+
+func TestLoadFixtures(t *testing.T) {
+	state, err := LoadConfig(writeConfig(t, testConfig))
+	require.NoError(t, err)
+
+	server := httptest.NewServer(NewServer(state))
+	defer server.Close()
+
+	client, err := beacon.NewClient(server.URL, nil)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	recorder, err := beacon.NewRecorder(client, dir)
+	require.NoError(t, err)
+
+	_, err = recorder.Genesis(ctx)
+	require.NoError(t, err)
+
+	_, err = recorder.Fork(ctx, "finalized")
+	require.NoError(t, err)
+
+	_, err = recorder.Spec(ctx)
+	require.NoError(t, err)
+
+	_, err = recorder.Validators(ctx, "finalized", []string{"0", "10"})
+	require.NoError(t, err)
+
+	_, err = recorder.Validator(ctx, "head", "12")
+	require.NoError(t, err)
+
+	loaded, err := LoadFixtures(dir)
+	require.NoError(t, err)
+
+	assert.Equal(t, state.Genesis, loaded.Genesis)
+	assert.Equal(t, state.Fork, loaded.Fork)
+	for key, value := range state.Spec {
+		assert.JSONEq(t, string(value), string(loaded.Spec[key]), key)
+	}
+
+	assert.Equal(t, []*beacon.Validator{state.Validators[0], state.Validators[2], state.Validators[4]}, loaded.Validators)
+}
@@ -0,0 +1,323 @@
+// Package mock implements a mock beacon node serving the subset of the beacon
+// API used by validator-tools, for testing without a real node.
+package mock
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/ethpandaops/validator-tools/pkg/beacon"
+)
+
+// State is the data served by a mock beacon node. Every state id (head,
+// finalized, a slot, ...) is answered from the same state.
+type State struct {
+	Genesis               beacon.Genesis
+	Fork                  beacon.Fork
+	Spec                  beacon.Spec
+	Syncing               beacon.SyncStatus
+	Validators            []*beacon.Validator
+	VoluntaryExits        []*beacon.SignedVoluntaryExit
+	BLSToExecutionChanges []*beacon.SignedBLSToExecutionChange
+}
+
+// Server is a mock beacon node. Submitted voluntary exits and BLS to
+// execution changes are added to its pools.
+type Server struct {
+	mu       sync.RWMutex
+	state    *State
+	byIndex  map[uint64]*beacon.Validator
+	byPubkey map[string]*beacon.Validator
+	mux      *http.ServeMux
+}
+
+var _ http.Handler = (*Server)(nil)
+
+// NewServer returns a mock beacon node serving state
+func NewServer(state *State) *Server {
+	s := &Server{
+		state:    state,
+		byIndex:  make(map[uint64]*beacon.Validator, len(state.Validators)),
+		byPubkey: make(map[string]*beacon.Validator, len(state.Validators)),
+		mux:      http.NewServeMux(),
+	}
+
+	for _, v := range state.Validators {
+		s.byIndex[v.Index] = v
+		s.byPubkey[strings.ToLower(v.Validator.Pubkey)] = v
+	}
+
+	s.mux.HandleFunc("GET /eth/v1/beacon/genesis", s.handleGenesis)
+	s.mux.HandleFunc("GET /eth/v1/beacon/states/{state}/fork", s.handleFork)
+	s.mux.HandleFunc("GET /eth/v1/config/spec", s.handleSpec)
+	s.mux.HandleFunc("GET /eth/v1/beacon/states/{state}/validators", s.handleValidators)
+	s.mux.HandleFunc("POST /eth/v1/beacon/states/{state}/validators", s.handleValidators)
+	s.mux.HandleFunc("GET /eth/v1/beacon/states/{state}/validators/{id}", s.handleValidator)
+	s.mux.HandleFunc("GET /eth/v1/node/syncing", s.handleSyncing)
+	s.mux.HandleFunc("GET /eth/v1/beacon/pool/voluntary_exits", s.handleVoluntaryExits)
+	s.mux.HandleFunc("POST /eth/v1/beacon/pool/voluntary_exits", s.handleSubmitVoluntaryExit)
+	s.mux.HandleFunc("GET /eth/v1/beacon/pool/bls_to_execution_changes", s.handleBLSToExecutionChanges)
+	s.mux.HandleFunc("POST /eth/v1/beacon/pool/bls_to_execution_changes", s.handleSubmitBLSToExecutionChanges)
+
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// VoluntaryExits returns the voluntary exit pool, including submitted exits
+func (s *Server) VoluntaryExits() []*beacon.SignedVoluntaryExit {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return append([]*beacon.SignedVoluntaryExit{}, s.state.VoluntaryExits...)
+}
+
+// BLSToExecutionChanges returns the BLS to execution change pool, including
+// submitted changes
+func (s *Server) BLSToExecutionChanges() []*beacon.SignedBLSToExecutionChange {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return append([]*beacon.SignedBLSToExecutionChange{}, s.state.BLSToExecutionChanges...)
+}
+
+func (s *Server) handleGenesis(w http.ResponseWriter, _ *http.Request) {
+	writeData(w, s.state.Genesis)
+}
+
+func (s *Server) handleFork(w http.ResponseWriter, r *http.Request) {
+	if !validStateID(w, r) {
+		return
+	}
+
+	writeData(w, s.state.Fork)
+}
+
+func (s *Server) handleSpec(w http.ResponseWriter, _ *http.Request) {
+	writeData(w, s.state.Spec)
+}
+
+func (s *Server) handleSyncing(w http.ResponseWriter, _ *http.Request) {
+	writeData(w, s.state.Syncing)
+}
+
+// handleValidators serves the validators endpoint, filtered by id (index or
+// pubkey) and status from the query string or, for POST, the request body
+func (s *Server) handleValidators(w http.ResponseWriter, r *http.Request) {
+	if !validStateID(w, r) {
+		return
+	}
+
+	var filter struct {
+		IDs      []string `json:"ids"`
+		Statuses []string `json:"statuses"`
+	}
+
+	if r.Method == http.MethodPost {
+		if err := json.NewDecoder(r.Body).Decode(&filter); err != nil && err != io.EOF {
+			writeError(w, http.StatusBadRequest, "invalid request body: "+err.Error())
+
+			return
+		}
+	} else {
+		filter.IDs = splitQuery(r.URL.Query()["id"])
+		filter.Statuses = splitQuery(r.URL.Query()["status"])
+	}
+
+	validators := s.state.Validators
+
+	if len(filter.IDs) > 0 {
+		validators = make([]*beacon.Validator, 0, len(filter.IDs))
+
+		for _, id := range filter.IDs {
+			v, err := s.lookup(id)
+			if err != nil {
+				writeError(w, http.StatusBadRequest, err.Error())
+
+				return
+			}
+
+			if v != nil {
+				validators = append(validators, v)
+			}
+		}
+	}
+
+	matching := make([]*beacon.Validator, 0, len(validators))
+
+	for _, v := range validators {
+		if matchesStatus(v.Status, filter.Statuses) {
+			matching = append(matching, v)
+		}
+	}
+
+	writeData(w, matching)
+}
+
+func (s *Server) handleValidator(w http.ResponseWriter, r *http.Request) {
+	if !validStateID(w, r) {
+		return
+	}
+
+	v, err := s.lookup(r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+
+		return
+	}
+
+	if v == nil {
+		writeError(w, http.StatusNotFound, "Validator not found")
+
+		return
+	}
+
+	writeData(w, v)
+}
+
+func (s *Server) handleVoluntaryExits(w http.ResponseWriter, _ *http.Request) {
+	writeData(w, s.VoluntaryExits())
+}
+
+func (s *Server) handleSubmitVoluntaryExit(w http.ResponseWriter, r *http.Request) {
+	exit := &beacon.SignedVoluntaryExit{}
+	if err := json.NewDecoder(r.Body).Decode(exit); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid voluntary exit: "+err.Error())
+
+		return
+	}
+
+	if _, ok := s.byIndex[exit.Message.ValidatorIndex]; !ok {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("validator %d not found", exit.Message.ValidatorIndex))
+
+		return
+	}
+
+	s.mu.Lock()
+	s.state.VoluntaryExits = append(s.state.VoluntaryExits, exit)
+	s.mu.Unlock()
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Server) handleBLSToExecutionChanges(w http.ResponseWriter, _ *http.Request) {
+	writeData(w, s.BLSToExecutionChanges())
+}
+
+func (s *Server) handleSubmitBLSToExecutionChanges(w http.ResponseWriter, r *http.Request) {
+	var changes []*beacon.SignedBLSToExecutionChange
+	if err := json.NewDecoder(r.Body).Decode(&changes); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid BLS to execution changes: "+err.Error())
+
+		return
+	}
+
+	for _, change := range changes {
+		if _, ok := s.byIndex[change.Message.ValidatorIndex]; !ok {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("validator %d not found", change.Message.ValidatorIndex))
+
+			return
+		}
+	}
+
+	s.mu.Lock()
+	s.state.BLSToExecutionChanges = append(s.state.BLSToExecutionChanges, changes...)
+	s.mu.Unlock()
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// lookup returns the validator with the index or 0x prefixed pubkey id, or
+// nil if there is none
+func (s *Server) lookup(id string) (*beacon.Validator, error) {
+	if strings.HasPrefix(id, "0x") {
+		return s.byPubkey[strings.ToLower(id)], nil
+	}
+
+	index, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid validator id %q", id)
+	}
+
+	return s.byIndex[index], nil
+}
+
+// validStateID rejects state ids that no beacon node would accept
+func validStateID(w http.ResponseWriter, r *http.Request) bool {
+	id := r.PathValue("state")
+
+	switch id {
+	case "head", "genesis", "finalized", "justified":
+		return true
+	}
+
+	if _, err := strconv.ParseUint(id, 10, 64); err == nil {
+		return true
+	}
+
+	if strings.HasPrefix(id, "0x") && len(id) == 66 {
+		return true
+	}
+
+	writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid state id %q", id))
+
+	return false
+}
+
+// matchesStatus reports whether status matches one of the statuses, which
+// may also be the general statuses active, pending, exited and withdrawal
+func matchesStatus(status string, statuses []string) bool {
+	if len(statuses) == 0 {
+		return true
+	}
+
+	for _, filter := range statuses {
+		if status == filter || strings.HasPrefix(status, filter+"_") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// splitQuery splits repeated and comma-separated query values
+func splitQuery(values []string) []string {
+	var split []string
+
+	for _, value := range values {
+		for _, part := range strings.Split(value, ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				split = append(split, part)
+			}
+		}
+	}
+
+	return split
+}
+
+func writeData(w http.ResponseWriter, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"execution_optimistic": false,
+		"finalized":            true,
+		"data":                 data,
+	})
+}
+
+func writeError(w http.ResponseWriter, code int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"code":    code,
+		"message": message,
+	})
+}
@@ -0,0 +1,172 @@
+package mock
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethpandaops/validator-tools/pkg/beacon"
+)
+
+func newTestServer(t *testing.T) (*Server, *beacon.HTTPClient) {
+	t.Helper()
+
+	state, err := LoadConfig(writeConfig(t, testConfig))
+	require.NoError(t, err)
+
+	mock := NewServer(state)
+
+	server := httptest.NewServer(mock)
+	t.Cleanup(server.Close)
+
+	client, err := beacon.NewClient(server.URL, nil)
+	require.NoError(t, err)
+
+	return mock, client
+}
+
+func indices(validators []*beacon.Validator) []uint64 {
+	result := make([]uint64, 0, len(validators))
+	for _, v := range validators {
+		result = append(result, v.Index)
+	}
+
+	return result
+}
+
+func TestServerValidators(t *testing.T) {
+	mock, client := newTestServer(t)
+	ctx := context.Background()
+
+	all, err := client.Validators(ctx, "head", nil)
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{0, 1, 10, 11, 12}, indices(all))
+
+	pubkey := mock.state.Validators[0].Validator.Pubkey
+
+	// Filtered by index and pubkey through POST, unknown ids are left out
+	some, err := client.Validators(ctx, "finalized", []string{"10", pubkey, "999"})
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{10, 0}, indices(some))
+
+	v, err := client.Validator(ctx, "head", "11")
+	require.NoError(t, err)
+	assert.Equal(t, uint64(11), v.Index)
+
+	_, err = client.Validator(ctx, "head", "13")
+	assert.True(t, beacon.IsNotFound(err))
+
+	_, err = client.Validators(ctx, "finalized", []string{"not-an-id"})
+	assert.Error(t, err)
+
+	_, err = client.Validators(ctx, "nonsense", nil)
+	assert.Error(t, err)
+}
+
+func TestServerHighestValidatorIndex(t *testing.T) {
+	state, err := (&Config{Count: 1000}).State()
+	require.NoError(t, err)
+
+	server := httptest.NewServer(NewServer(state))
+	defer server.Close()
+
+	client, err := beacon.NewClient(server.URL, nil)
+	require.NoError(t, err)
+
+	highest, err := beacon.HighestValidatorIndex(context.Background(), client, "head")
+	require.NoError(t, err)
+	assert.Equal(t, uint64(999), highest)
+}
+
+func TestServerStatusFilter(t *testing.T) {
+	mock, _ := newTestServer(t)
+
+	server := httptest.NewServer(mock)
+	defer server.Close()
+
+	tests := []struct {
+		query    string
+		expected string
+	}{
+		{query: "status=exited", expected: `"index":"1"`},
+		{query: "status=exited_unslashed&id=0,1", expected: `"index":"1"`},
+		{query: "status=active_ongoing&id=1", expected: `"data":[]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			resp, err := http.Get(server.URL + "/eth/v1/beacon/states/head/validators?" + tt.query)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+			assert.Contains(t, string(body), tt.expected)
+			assert.NotContains(t, string(body), `"index":"0"`)
+		})
+	}
+}
+
+func TestServerConfig(t *testing.T) {
+	_, client := newTestServer(t)
+	ctx := context.Background()
+
+	genesis, err := client.Genesis(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "0x10000910", genesis.GenesisForkVersion)
+
+	fork, err := client.Fork(ctx, "head")
+	require.NoError(t, err)
+	assert.Equal(t, uint64(50688), fork.Epoch)
+
+	spec, err := client.Spec(ctx)
+	require.NoError(t, err)
+
+	name, err := spec.String("CONFIG_NAME")
+	require.NoError(t, err)
+	assert.Equal(t, "hoodi", name)
+
+	status, err := client.NodeSyncing(ctx)
+	require.NoError(t, err)
+	assert.False(t, status.IsSyncing)
+}
+
+func TestServerPools(t *testing.T) {
+	mock, client := newTestServer(t)
+	ctx := context.Background()
+
+	exit := &beacon.SignedVoluntaryExit{
+		Message:   beacon.VoluntaryExit{Epoch: 256, ValidatorIndex: 10},
+		Signature: "0x" + strings.Repeat("ab", 96),
+	}
+
+	require.NoError(t, client.SubmitVoluntaryExit(ctx, exit))
+
+	exits, err := client.VoluntaryExits(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []*beacon.SignedVoluntaryExit{exit}, exits)
+	assert.Equal(t, exits, mock.VoluntaryExits())
+
+	// Exits of unknown validators are rejected
+	err = client.SubmitVoluntaryExit(ctx, &beacon.SignedVoluntaryExit{Message: beacon.VoluntaryExit{ValidatorIndex: 999}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "validator 999 not found")
+
+	change := &beacon.SignedBLSToExecutionChange{
+		Message: beacon.BLSToExecutionChange{ValidatorIndex: 11, FromBLSPubkey: "0x01", ToExecutionAddress: "0x02"},
+	}
+
+	require.NoError(t, client.SubmitBLSToExecutionChanges(ctx, []*beacon.SignedBLSToExecutionChange{change}))
+
+	changes, err := client.BLSToExecutionChanges(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []*beacon.SignedBLSToExecutionChange{change}, changes)
+}
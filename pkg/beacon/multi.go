@@ -0,0 +1,513 @@
+package beacon
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// maxReportedDifferences caps the differences listed per conflicting answer
+const maxReportedDifferences = 20
+
+// Endpoint is a named beacon node client. Names are used in logs and reports,
+// so they must not contain credentials.
+type Endpoint struct {
+	Name   string
+	Client Client
+}
+
+// MultiClient is a Client backed by several beacon nodes. Requests go to the
+// first node and fail over to the next one on errors. With a quorum set,
+// genesis, spec and validator answers are requested from every node and must
+// agree across at least that many nodes. A 404 is an answer, not a failure:
+// it is returned without failing over, and counts towards the quorum.
+type MultiClient struct {
+	endpoints []Endpoint
+	quorum    int
+}
+
+var _ Client = (*MultiClient)(nil)
+
+// NewMultiClient returns a client over endpoints, in failover order. A quorum
+// of 0 disables the agreement checks.
+func NewMultiClient(endpoints []Endpoint, quorum int) (*MultiClient, error) {
+	if len(endpoints) == 0 {
+		return nil, errors.New("at least one beacon node is required")
+	}
+
+	if quorum < 0 || quorum > len(endpoints) {
+		return nil, errors.Errorf("quorum must be between 0 and the number of beacon nodes (%d), got %d", len(endpoints), quorum)
+	}
+
+	return &MultiClient{endpoints: endpoints, quorum: quorum}, nil
+}
+
+func (m *MultiClient) Genesis(ctx context.Context) (*Genesis, error) {
+	call := func(c Client) (*Genesis, error) { return c.Genesis(ctx) }
+
+	if m.quorum > 0 {
+		return agree(ctx, m, "genesis", call, diffGenesis)
+	}
+
+	return failover(ctx, m, "genesis", call)
+}
+
+func (m *MultiClient) Fork(ctx context.Context, stateID string) (*Fork, error) {
+	return failover(ctx, m, "fork", func(c Client) (*Fork, error) { return c.Fork(ctx, stateID) })
+}
+
+func (m *MultiClient) Spec(ctx context.Context) (Spec, error) {
+	call := func(c Client) (Spec, error) { return c.Spec(ctx) }
+
+	if m.quorum > 0 {
+		return agree(ctx, m, "spec", call, diffSpec)
+	}
+
+	return failover(ctx, m, "spec", call)
+}
+
+func (m *MultiClient) Validators(ctx context.Context, stateID string, ids []string) ([]*Validator, error) {
+	call := func(c Client) ([]*Validator, error) { return c.Validators(ctx, stateID, ids) }
+
+	if m.quorum > 0 {
+		return agree(ctx, m, "validators", call, diffValidators)
+	}
+
+	return failover(ctx, m, "validators", call)
+}
+
+// StreamValidators streams from the first node that answers. With a quorum the
+// full answers are compared first.
+func (m *MultiClient) StreamValidators(ctx context.Context, stateID string, ids []string, fn func(*Validator) error) error {
+	if m.quorum > 0 {
+		validators, err := m.Validators(ctx, stateID, ids)
+		if err != nil {
+			return err
+		}
+
+		for _, v := range validators {
+			if err := fn(v); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	return failoverStream(ctx, m, "validators", func(c Client, delivered func()) error {
+		return c.StreamValidators(ctx, stateID, ids, func(v *Validator) error {
+			delivered()
+
+			return fn(v)
+		})
+	})
+}
+
+func (m *MultiClient) Validator(ctx context.Context, stateID, id string) (*Validator, error) {
+	call := func(c Client) (*Validator, error) { return c.Validator(ctx, stateID, id) }
+
+	if m.quorum > 0 {
+		return agree(ctx, m, "validator", call, func(a, b *Validator) []string {
+			return diffValidators([]*Validator{a}, []*Validator{b})
+		})
+	}
+
+	return failover(ctx, m, "validator", call)
+}
+
+func (m *MultiClient) NodeSyncing(ctx context.Context) (*SyncStatus, error) {
+	return failover(ctx, m, "node syncing", func(c Client) (*SyncStatus, error) { return c.NodeSyncing(ctx) })
+}
+
+func (m *MultiClient) VoluntaryExits(ctx context.Context) ([]*SignedVoluntaryExit, error) {
+	return failover(ctx, m, "voluntary exit pool", func(c Client) ([]*SignedVoluntaryExit, error) { return c.VoluntaryExits(ctx) })
+}
+
+func (m *MultiClient) SubmitVoluntaryExit(ctx context.Context, exit *SignedVoluntaryExit) error {
+	_, err := failover(ctx, m, "submit voluntary exit", func(c Client) (struct{}, error) {
+		return struct{}{}, c.SubmitVoluntaryExit(ctx, exit)
+	})
+
+	return err
+}
+
+func (m *MultiClient) BLSToExecutionChanges(ctx context.Context) ([]*SignedBLSToExecutionChange, error) {
+	return failover(ctx, m, "BLS to execution change pool", func(c Client) ([]*SignedBLSToExecutionChange, error) {
+		return c.BLSToExecutionChanges(ctx)
+	})
+}
+
+func (m *MultiClient) SubmitBLSToExecutionChanges(ctx context.Context, changes []*SignedBLSToExecutionChange) error {
+	_, err := failover(ctx, m, "submit BLS to execution changes", func(c Client) (struct{}, error) {
+		return struct{}{}, c.SubmitBLSToExecutionChanges(ctx, changes)
+	})
+
+	return err
+}
+
+func (m *MultiClient) Events(ctx context.Context, topics []string, handler func(*Event) error) error {
+	return failoverStream(ctx, m, "events", func(c Client, delivered func()) error {
+		return c.Events(ctx, topics, func(event *Event) error {
+			delivered()
+
+			return handler(event)
+		})
+	})
+}
+
+// failover calls each endpoint in turn until one succeeds
+func failover[T any](ctx context.Context, m *MultiClient, method string, call func(Client) (T, error)) (T, error) {
+	var (
+		zero    T
+		lastErr error
+	)
+
+	for i, endpoint := range m.endpoints {
+		value, err := call(endpoint.Client)
+		if err == nil {
+			return value, nil
+		}
+
+		if ctx.Err() != nil || IsNotFound(err) {
+			return zero, err
+		}
+
+		lastErr = err
+
+		if i < len(m.endpoints)-1 {
+			log.WithError(err).WithFields(logrus.Fields{
+				"endpoint": endpoint.Name,
+				"method":   method,
+			}).Warn("Beacon node request failed, failing over to the next node")
+		}
+	}
+
+	if len(m.endpoints) == 1 {
+		return zero, lastErr
+	}
+
+	return zero, errors.Wrapf(lastErr, "all %d beacon nodes failed", len(m.endpoints))
+}
+
+// failoverStream is failover for streaming calls. Once a node has delivered
+// data, its errors are returned instead of failing over, so callbacks never
+// see the same data twice.
+func failoverStream(ctx context.Context, m *MultiClient, method string, call func(c Client, delivered func()) error) error {
+	var err error
+
+	for i, endpoint := range m.endpoints {
+		delivered := false
+
+		err = call(endpoint.Client, func() { delivered = true })
+		if err == nil || delivered || ctx.Err() != nil {
+			return err
+		}
+
+		if i < len(m.endpoints)-1 {
+			log.WithError(err).WithFields(logrus.Fields{
+				"endpoint": endpoint.Name,
+				"method":   method,
+			}).Warn("Beacon node request failed, failing over to the next node")
+		}
+	}
+
+	if len(m.endpoints) == 1 {
+		return err
+	}
+
+	return errors.Wrapf(err, "all %d beacon nodes failed", len(m.endpoints))
+}
+
+// answer is the response of a single endpoint
+type answer[T any] struct {
+	endpoint string
+	value    T
+	err      error
+}
+
+// agree calls every endpoint concurrently and returns the answer shared by the
+// most endpoints, provided at least m.quorum of them agree
+func agree[T any](ctx context.Context, m *MultiClient, method string, call func(Client) (T, error), diff func(a, b T) []string) (T, error) {
+	answers := make([]answer[T], len(m.endpoints))
+
+	var wg sync.WaitGroup
+
+	for i, endpoint := range m.endpoints {
+		wg.Add(1)
+
+		go func(i int, endpoint Endpoint) {
+			defer wg.Done()
+
+			value, err := call(endpoint.Client)
+			answers[i] = answer[T]{endpoint: endpoint.Name, value: value, err: err}
+		}(i, endpoint)
+	}
+
+	wg.Wait()
+
+	var zero T
+
+	if err := ctx.Err(); err != nil {
+		return zero, err
+	}
+
+	// Group agreeing answers, keeping endpoint order. Not found answers form
+	// a group of their own.
+	type group struct {
+		value     T
+		notFound  error
+		endpoints []string
+	}
+
+	differences := func(a, b *group) []string {
+		switch {
+		case a.notFound != nil && b.notFound != nil:
+			return nil
+		case a.notFound != nil:
+			return []string{"found"}
+		case b.notFound != nil:
+			return []string{"not found"}
+		default:
+			return diff(a.value, b.value)
+		}
+	}
+
+	var groups []*group
+
+	report := &DisagreementError{
+		Method:   method,
+		Quorum:   m.quorum,
+		Total:    len(m.endpoints),
+		Failures: map[string]string{},
+	}
+
+	for _, a := range answers {
+		if a.err != nil && !IsNotFound(a.err) {
+			report.Failures[a.endpoint] = a.err.Error()
+
+			continue
+		}
+
+		candidate := &group{value: a.value, notFound: a.err}
+
+		var matched *group
+
+		for _, g := range groups {
+			if len(differences(g, candidate)) == 0 {
+				matched = g
+
+				break
+			}
+		}
+
+		if matched == nil {
+			matched = candidate
+			groups = append(groups, matched)
+		}
+
+		matched.endpoints = append(matched.endpoints, a.endpoint)
+	}
+
+	var best *group
+
+	for _, g := range groups {
+		if best == nil || len(g.endpoints) > len(best.endpoints) {
+			best = g
+		}
+	}
+
+	if best != nil {
+		report.Agreeing = best.endpoints
+	}
+
+	for _, g := range groups {
+		if g == best {
+			continue
+		}
+
+		report.Conflicts = append(report.Conflicts, Conflict{
+			Endpoints:   g.endpoints,
+			Differences: limitDifferences(differences(best, g)),
+		})
+	}
+
+	if best == nil || len(best.endpoints) < m.quorum {
+		report.Log()
+
+		return zero, report
+	}
+
+	if len(report.Conflicts) > 0 || len(report.Failures) > 0 {
+		report.Log()
+	}
+
+	if best.notFound != nil {
+		return zero, best.notFound
+	}
+
+	return best.value, nil
+}
+
+// Conflict is an answer that differs from the majority answer
+type Conflict struct {
+	Endpoints   []string `json:"endpoints"`
+	Differences []string `json:"differences"`
+}
+
+// DisagreementError reports how the answers of beacon nodes to a request differ
+type DisagreementError struct {
+	Method    string            `json:"method"`
+	Quorum    int               `json:"quorum"`
+	Total     int               `json:"total"`
+	Agreeing  []string          `json:"agreeing"`
+	Conflicts []Conflict        `json:"conflicts"`
+	Failures  map[string]string `json:"failures"`
+}
+
+func (e *DisagreementError) Error() string {
+	parts := []string{fmt.Sprintf("%d of %d beacon nodes agree on %s, quorum is %d", len(e.Agreeing), e.Total, e.Method, e.Quorum)}
+
+	for _, conflict := range e.Conflicts {
+		parts = append(parts, fmt.Sprintf("%s differ: %s", strings.Join(conflict.Endpoints, ", "), strings.Join(conflict.Differences, ", ")))
+	}
+
+	endpoints := make([]string, 0, len(e.Failures))
+	for endpoint := range e.Failures {
+		endpoints = append(endpoints, endpoint)
+	}
+
+	sort.Strings(endpoints)
+
+	for _, endpoint := range endpoints {
+		parts = append(parts, fmt.Sprintf("%s failed: %s", endpoint, e.Failures[endpoint]))
+	}
+
+	return strings.Join(parts, "; ")
+}
+
+// Log logs every conflicting answer and failed endpoint
+func (e *DisagreementError) Log() {
+	for _, conflict := range e.Conflicts {
+		for _, difference := range conflict.Differences {
+			log.WithFields(logrus.Fields{
+				"method":    e.Method,
+				"endpoints": strings.Join(conflict.Endpoints, ","),
+				"agreeing":  strings.Join(e.Agreeing, ","),
+			}).Warn("Beacon node answer differs: " + difference)
+		}
+	}
+
+	for endpoint, failure := range e.Failures {
+		log.WithFields(logrus.Fields{
+			"method":   e.Method,
+			"endpoint": endpoint,
+		}).Warn("Beacon node request failed: " + failure)
+	}
+}
+
+func limitDifferences(differences []string) []string {
+	if len(differences) <= maxReportedDifferences {
+		return differences
+	}
+
+	return append(differences[:maxReportedDifferences:maxReportedDifferences], fmt.Sprintf("and %d more", len(differences)-maxReportedDifferences))
+}
+
+func diffGenesis(a, b *Genesis) []string {
+	var differences []string
+
+	if a.GenesisTime != b.GenesisTime {
+		differences = append(differences, fmt.Sprintf("genesis_time: %d != %d", a.GenesisTime, b.GenesisTime))
+	}
+
+	if !strings.EqualFold(a.GenesisValidatorsRoot, b.GenesisValidatorsRoot) {
+		differences = append(differences, fmt.Sprintf("genesis_validators_root: %s != %s", a.GenesisValidatorsRoot, b.GenesisValidatorsRoot))
+	}
+
+	if !strings.EqualFold(a.GenesisForkVersion, b.GenesisForkVersion) {
+		differences = append(differences, fmt.Sprintf("genesis_fork_version: %s != %s", a.GenesisForkVersion, b.GenesisForkVersion))
+	}
+
+	return differences
+}
+
+// diffSpec compares the values both specs have. Clients expose different sets
+// of keys, so keys missing from one of them are not a disagreement.
+func diffSpec(a, b Spec) []string {
+	var differences []string
+
+	for key, av := range a {
+		bv, ok := b[key]
+		if !ok {
+			continue
+		}
+
+		if !strings.EqualFold(compactJSON(av), compactJSON(bv)) {
+			differences = append(differences, fmt.Sprintf("%s: %s != %s", key, compactJSON(av), compactJSON(bv)))
+		}
+	}
+
+	sort.Strings(differences)
+
+	return differences
+}
+
+// diffValidators compares the index, pubkey, withdrawal credentials and status
+// of each validator
+func diffValidators(a, b []*Validator) []string {
+	byIndex := make(map[uint64]*Validator, len(b))
+	for _, v := range b {
+		byIndex[v.Index] = v
+	}
+
+	var differences []string
+
+	seen := make(map[uint64]bool, len(a))
+
+	for _, av := range a {
+		seen[av.Index] = true
+
+		bv, ok := byIndex[av.Index]
+		if !ok {
+			differences = append(differences, fmt.Sprintf("validator %d: not returned", av.Index))
+
+			continue
+		}
+
+		if !strings.EqualFold(av.Validator.Pubkey, bv.Validator.Pubkey) {
+			differences = append(differences, fmt.Sprintf("validator %d pubkey: %s != %s", av.Index, av.Validator.Pubkey, bv.Validator.Pubkey))
+		}
+
+		if !strings.EqualFold(av.Validator.WithdrawalCredentials, bv.Validator.WithdrawalCredentials) {
+			differences = append(differences, fmt.Sprintf("validator %d withdrawal_credentials: %s != %s", av.Index, av.Validator.WithdrawalCredentials, bv.Validator.WithdrawalCredentials))
+		}
+
+		if av.Status != bv.Status {
+			differences = append(differences, fmt.Sprintf("validator %d status: %s != %s", av.Index, av.Status, bv.Status))
+		}
+	}
+
+	for _, bv := range b {
+		if !seen[bv.Index] {
+			differences = append(differences, fmt.Sprintf("validator %d: not in the agreeing answer", bv.Index))
+		}
+	}
+
+	return differences
+}
+
+func compactJSON(raw json.RawMessage) string {
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, raw); err != nil {
+		return string(raw)
+	}
+
+	return buf.String()
+}
@@ -0,0 +1,268 @@
+package beacon
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubClient answers genesis, spec and validator requests with fixed values
+type stubClient struct {
+	Client
+
+	genesis    *Genesis
+	spec       Spec
+	validators []*Validator
+	err        error
+	calls      int
+}
+
+func (s *stubClient) Genesis(context.Context) (*Genesis, error) {
+	s.calls++
+
+	return s.genesis, s.err
+}
+
+func (s *stubClient) Spec(context.Context) (Spec, error) {
+	s.calls++
+
+	return s.spec, s.err
+}
+
+func (s *stubClient) Validators(context.Context, string, []string) ([]*Validator, error) {
+	s.calls++
+
+	return s.validators, s.err
+}
+
+func (s *stubClient) StreamValidators(_ context.Context, _ string, _ []string, fn func(*Validator) error) error {
+	s.calls++
+
+	for _, v := range s.validators {
+		if err := fn(v); err != nil {
+			return err
+		}
+	}
+
+	return s.err
+}
+
+func endpoints(clients ...*stubClient) []Endpoint {
+	result := make([]Endpoint, 0, len(clients))
+	for i, c := range clients {
+		result = append(result, Endpoint{Name: string(rune('a' + i)), Client: c})
+	}
+
+	return result
+}
+
+func TestNewMultiClient(t *testing.T) {
+	_, err := NewMultiClient(nil, 0)
+	assert.Error(t, err)
+
+	_, err = NewMultiClient(endpoints(&stubClient{}), 2)
+	assert.Error(t, err)
+
+	_, err = NewMultiClient(endpoints(&stubClient{}), -1)
+	assert.Error(t, err)
+
+	_, err = NewMultiClient(endpoints(&stubClient{}, &stubClient{}), 2)
+	assert.NoError(t, err)
+}
+
+func TestMultiClientFailover(t *testing.T) {
+	genesis := &Genesis{GenesisForkVersion: "0x10000910"}
+
+	t.Run("next node on error", func(t *testing.T) {
+		first := &stubClient{err: errors.New("connection refused")}
+		second := &stubClient{genesis: genesis}
+		third := &stubClient{genesis: genesis}
+
+		client, err := NewMultiClient(endpoints(first, second, third), 0)
+		require.NoError(t, err)
+
+		got, err := client.Genesis(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, genesis, got)
+		assert.Equal(t, []int{1, 1, 0}, []int{first.calls, second.calls, third.calls})
+	})
+
+	t.Run("all nodes fail", func(t *testing.T) {
+		client, err := NewMultiClient(endpoints(
+			&stubClient{err: errors.New("connection refused")},
+			&stubClient{err: &APIError{StatusCode: http.StatusServiceUnavailable}},
+		), 0)
+		require.NoError(t, err)
+
+		_, err = client.Genesis(context.Background())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "all 2 beacon nodes failed")
+
+		var apiErr *APIError
+		require.ErrorAs(t, err, &apiErr)
+		assert.Equal(t, http.StatusServiceUnavailable, apiErr.StatusCode)
+	})
+
+	t.Run("not found is an answer", func(t *testing.T) {
+		first := &stubClient{err: errors.New("connection refused")}
+		second := &stubClient{err: &APIError{StatusCode: http.StatusNotFound}}
+		third := &stubClient{genesis: genesis}
+
+		client, err := NewMultiClient(endpoints(first, second, third), 0)
+		require.NoError(t, err)
+
+		_, err = client.Genesis(context.Background())
+		assert.True(t, IsNotFound(err))
+		assert.Equal(t, []int{1, 1, 0}, []int{first.calls, second.calls, third.calls})
+	})
+
+	t.Run("cancelled context stops failover", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		first := &stubClient{err: context.Canceled}
+		second := &stubClient{genesis: genesis}
+
+		client, err := NewMultiClient(endpoints(first, second), 0)
+		require.NoError(t, err)
+
+		_, err = client.Genesis(ctx)
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, 0, second.calls)
+	})
+}
+
+func TestMultiClientStreamValidators(t *testing.T) {
+	validators := []*Validator{{Index: 1}, {Index: 2}}
+
+	collect := func(client *MultiClient) ([]uint64, error) {
+		var indices []uint64
+
+		err := client.StreamValidators(context.Background(), "head", nil, func(v *Validator) error {
+			indices = append(indices, v.Index)
+
+			return nil
+		})
+
+		return indices, err
+	}
+
+	t.Run("fails over before any validator", func(t *testing.T) {
+		client, err := NewMultiClient(endpoints(&stubClient{err: errors.New("connection refused")}, &stubClient{validators: validators}), 0)
+		require.NoError(t, err)
+
+		indices, err := collect(client)
+		require.NoError(t, err)
+		assert.Equal(t, []uint64{1, 2}, indices)
+	})
+
+	t.Run("no failover once validators were delivered", func(t *testing.T) {
+		second := &stubClient{validators: validators}
+
+		client, err := NewMultiClient(endpoints(&stubClient{validators: validators[:1], err: errors.New("connection reset")}, second), 0)
+		require.NoError(t, err)
+
+		indices, err := collect(client)
+		require.Error(t, err)
+		assert.Equal(t, []uint64{1}, indices)
+		assert.Equal(t, 0, second.calls)
+	})
+
+	t.Run("quorum", func(t *testing.T) {
+		client, err := NewMultiClient(endpoints(&stubClient{validators: validators}, &stubClient{validators: validators}), 2)
+		require.NoError(t, err)
+
+		indices, err := collect(client)
+		require.NoError(t, err)
+		assert.Equal(t, []uint64{1, 2}, indices)
+	})
+}
+
+func TestMultiClientQuorum(t *testing.T) {
+	hoodi := &Genesis{GenesisTime: 1742213400, GenesisValidatorsRoot: "0x212f", GenesisForkVersion: "0x10000910"}
+	other := &Genesis{GenesisTime: 1742213400, GenesisValidatorsRoot: "0xdead", GenesisForkVersion: "0x10000910"}
+
+	t.Run("majority meets quorum", func(t *testing.T) {
+		client, err := NewMultiClient(endpoints(
+			&stubClient{genesis: other},
+			&stubClient{genesis: hoodi},
+			&stubClient{genesis: hoodi},
+		), 2)
+		require.NoError(t, err)
+
+		got, err := client.Genesis(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, hoodi, got)
+	})
+
+	t.Run("quorum not met", func(t *testing.T) {
+		client, err := NewMultiClient(endpoints(
+			&stubClient{genesis: hoodi},
+			&stubClient{genesis: other},
+			&stubClient{err: errors.New("timeout")},
+		), 2)
+		require.NoError(t, err)
+
+		_, err = client.Genesis(context.Background())
+		require.Error(t, err)
+
+		var disagreement *DisagreementError
+		require.ErrorAs(t, err, &disagreement)
+		assert.Equal(t, "genesis", disagreement.Method)
+		assert.Equal(t, []string{"a"}, disagreement.Agreeing)
+		assert.Equal(t, []Conflict{{
+			Endpoints:   []string{"b"},
+			Differences: []string{"genesis_validators_root: 0x212f != 0xdead"},
+		}}, disagreement.Conflicts)
+		assert.Equal(t, map[string]string{"c": "timeout"}, disagreement.Failures)
+		assert.Contains(t, err.Error(), "1 of 3 beacon nodes agree on genesis, quorum is 2")
+	})
+
+	t.Run("spec keys missing on one node are not a disagreement", func(t *testing.T) {
+		client, err := NewMultiClient(endpoints(
+			&stubClient{spec: Spec{"SLOTS_PER_EPOCH": json.RawMessage(`"32"`), "PRESET_BASE": json.RawMessage(`"mainnet"`)}},
+			&stubClient{spec: Spec{"SLOTS_PER_EPOCH": json.RawMessage(` "32" `)}},
+		), 2)
+		require.NoError(t, err)
+
+		_, err = client.Spec(context.Background())
+		require.NoError(t, err)
+	})
+
+	t.Run("validator disagreement", func(t *testing.T) {
+		validators := []*Validator{
+			{Index: 1, Status: "active_ongoing", Validator: ValidatorData{Pubkey: "0xaa"}},
+			{Index: 2, Status: "active_ongoing", Validator: ValidatorData{Pubkey: "0xbb"}},
+		}
+		differing := []*Validator{
+			{Index: 1, Status: "active_exiting", Validator: ValidatorData{Pubkey: "0xaa"}},
+			{Index: 3, Status: "active_ongoing", Validator: ValidatorData{Pubkey: "0xcc"}},
+		}
+
+		client, err := NewMultiClient(endpoints(&stubClient{validators: validators}, &stubClient{validators: differing}), 2)
+		require.NoError(t, err)
+
+		_, err = client.Validators(context.Background(), "finalized", nil)
+
+		var disagreement *DisagreementError
+		require.ErrorAs(t, err, &disagreement)
+		assert.Equal(t, []string{
+			"validator 1 status: active_ongoing != active_exiting",
+			"validator 2: not returned",
+			"validator 3: not in the agreeing answer",
+		}, disagreement.Conflicts[0].Differences)
+	})
+}
+
+func TestLimitDifferences(t *testing.T) {
+	differences := make([]string, maxReportedDifferences+5)
+
+	limited := limitDifferences(differences)
+	assert.Len(t, limited, maxReportedDifferences+1)
+	assert.Equal(t, "and 5 more", limited[maxReportedDifferences])
+}
@@ -0,0 +1,135 @@
+package beacon
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Options configures how a client connects to a beacon node. Token and header
+// values are secrets and are never logged.
+type Options struct {
+	// Timeout of a single request, DefaultTimeout when zero
+	Timeout time.Duration
+	// BearerToken is sent in the Authorization header of every request
+	BearerToken string
+	// Headers are added to every request
+	Headers map[string]string
+	// CACertFile is a PEM bundle of certificate authorities trusted in
+	// addition to the system roots
+	CACertFile string
+	// ClientCertFile and ClientKeyFile are a PEM certificate and key for mutual TLS
+	ClientCertFile string
+	ClientKeyFile  string
+}
+
+// HTTPClient returns an HTTP client with the timeout, TLS and header options,
+// for files published alongside a network such as Ephemery's config. The
+// bearer token authenticates with the beacon nodes only and is not sent.
+func (o *Options) HTTPClient() (*http.Client, error) {
+	transport, err := o.transport()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(o.Headers) > 0 {
+		transport = &headerTransport{base: transport, headers: o.Headers}
+	}
+
+	timeout := o.Timeout
+	if timeout == 0 {
+		timeout = DefaultTimeout
+	}
+
+	return &http.Client{Timeout: timeout, Transport: transport}, nil
+}
+
+// headerTransport adds headers to every request
+type headerTransport struct {
+	base    http.RoundTripper
+	headers map[string]string
+}
+
+func (t *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	for key, value := range t.headers {
+		req.Header.Set(key, value)
+	}
+
+	return t.base.RoundTrip(req)
+}
+
+// transport returns the HTTP transport for the TLS options
+func (o *Options) transport() (http.RoundTripper, error) {
+	if o.CACertFile == "" && o.ClientCertFile == "" && o.ClientKeyFile == "" {
+		return http.DefaultTransport, nil
+	}
+
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if o.CACertFile != "" {
+		pem, err := os.ReadFile(o.CACertFile)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read CA bundle")
+		}
+
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, errors.Errorf("no certificates found in CA bundle %s", o.CACertFile)
+		}
+
+		tlsConfig.RootCAs = pool
+	}
+
+	if o.ClientCertFile != "" || o.ClientKeyFile != "" {
+		if o.ClientCertFile == "" || o.ClientKeyFile == "" {
+			return nil, errors.New("a client certificate and key are both required for mutual TLS")
+		}
+
+		cert, err := tls.LoadX509KeyPair(o.ClientCertFile, o.ClientKeyFile)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to load client certificate")
+		}
+
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	transport, ok := http.DefaultTransport.(*http.Transport)
+	if !ok {
+		return nil, errors.New("unexpected default HTTP transport")
+	}
+
+	transport = transport.Clone()
+	transport.TLSClientConfig = tlsConfig
+
+	return transport, nil
+}
+
+// RedactURL returns a beacon node URL that is safe to log. Credentials and
+// the path, which some providers use for API keys, are removed.
+func RedactURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return "<invalid beacon URL>"
+	}
+
+	redacted := u.Scheme + "://" + u.Host
+	if u.User != nil {
+		redacted = u.Scheme + "://***@" + u.Host
+	}
+
+	if (u.Path != "" && u.Path != "/") || u.RawQuery != "" {
+		redacted += "/***"
+	}
+
+	return redacted
+}
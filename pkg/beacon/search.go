@@ -0,0 +1,80 @@
+package beacon
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// HighestValidatorIndex returns the highest validator index in a state. The
+// registry only grows, so instead of downloading it, the index is found by
+// probing single validators: doubling until an index doesn't exist, then
+// bisecting. This takes about 2*log2(n) requests.
+func HighestValidatorIndex(ctx context.Context, client Client, stateID string) (uint64, error) {
+	probes := 0
+
+	exists := func(index uint64) (bool, error) {
+		probes++
+
+		_, err := client.Validator(ctx, stateID, strconv.FormatUint(index, 10))
+		if err == nil {
+			return true, nil
+		}
+
+		if IsNotFound(err) {
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	found, err := exists(0)
+	if err != nil {
+		return 0, err
+	}
+
+	if !found {
+		return 0, errors.Errorf("no validators found in state %s", stateID)
+	}
+
+	// low always exists, high never does
+	low, high := uint64(0), uint64(1)
+
+	for {
+		found, err := exists(high)
+		if err != nil {
+			return 0, err
+		}
+
+		if !found {
+			break
+		}
+
+		low, high = high, high*2
+	}
+
+	for high-low > 1 {
+		mid := low + (high-low)/2
+
+		found, err := exists(mid)
+		if err != nil {
+			return 0, err
+		}
+
+		if found {
+			low = mid
+		} else {
+			high = mid
+		}
+	}
+
+	log.WithFields(logrus.Fields{
+		"state":  stateID,
+		"index":  low,
+		"probes": probes,
+	}).Debug("Found highest validator index")
+
+	return low, nil
+}
@@ -0,0 +1,116 @@
+package beacon
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHighestValidatorIndex(t *testing.T) {
+	for _, count := range []int{1, 2, 3, 5, 64, 65, 1000, 1025, 4097} {
+		registry := &validatorServer{count: count}
+		server := httptest.NewServer(registry)
+
+		client := newTestClient(t, server.URL, nil)
+		ctx := context.Background()
+
+		index, err := HighestValidatorIndex(ctx, client, "finalized")
+		require.NoError(t, err)
+
+		probes := registry.probes
+
+		// The same answer as scanning the full registry
+		highest := uint64(0)
+		require.NoError(t, client.StreamValidators(ctx, "finalized", nil, func(v *Validator) error {
+			highest = max(highest, v.Index)
+
+			return nil
+		}))
+
+		assert.Equal(t, highest, index, "count %d", count)
+		assert.LessOrEqual(t, probes, 2*bitLength(count)+2, "count %d", count)
+
+		server.Close()
+	}
+}
+
+func TestHighestValidatorIndexErrors(t *testing.T) {
+	registry := &validatorServer{count: 0}
+	server := httptest.NewServer(registry)
+	defer server.Close()
+
+	_, err := HighestValidatorIndex(context.Background(), newTestClient(t, server.URL, nil), "finalized")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no validators found in state finalized")
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer failing.Close()
+
+	_, err = HighestValidatorIndex(context.Background(), newTestClient(t, failing.URL, nil), "head")
+	require.Error(t, err)
+	assert.False(t, IsNotFound(err))
+}
+
+func TestHighestValidatorIndexMultiClient(t *testing.T) {
+	for _, quorum := range []int{0, 2} {
+		first, second := &validatorServer{count: 100}, &validatorServer{count: 100}
+
+		firstServer, secondServer := httptest.NewServer(first), httptest.NewServer(second)
+
+		client, err := NewMultiClient([]Endpoint{
+			{Name: "a", Client: newTestClient(t, firstServer.URL, nil)},
+			{Name: "b", Client: newTestClient(t, secondServer.URL, nil)},
+		}, quorum)
+		require.NoError(t, err)
+
+		index, err := HighestValidatorIndex(context.Background(), client, "finalized")
+		require.NoError(t, err, "quorum %d", quorum)
+		assert.Equal(t, uint64(99), index, "quorum %d", quorum)
+
+		// Without a quorum, not found answers don't fail over
+		if quorum == 0 {
+			assert.Zero(t, second.probes)
+		} else {
+			assert.Equal(t, first.probes, second.probes)
+		}
+
+		firstServer.Close()
+		secondServer.Close()
+	}
+
+	t.Run("nodes disagree on a validator existing", func(t *testing.T) {
+		first := httptest.NewServer(&validatorServer{count: 100})
+		defer first.Close()
+
+		second := httptest.NewServer(&validatorServer{count: 101})
+		defer second.Close()
+
+		client, err := NewMultiClient([]Endpoint{
+			{Name: "a", Client: newTestClient(t, first.URL, nil)},
+			{Name: "b", Client: newTestClient(t, second.URL, nil)},
+		}, 2)
+		require.NoError(t, err)
+
+		_, err = HighestValidatorIndex(context.Background(), client, "finalized")
+
+		var disagreement *DisagreementError
+		require.ErrorAs(t, err, &disagreement)
+		assert.False(t, IsNotFound(err))
+		assert.Equal(t, []string{"found"}, disagreement.Conflicts[0].Differences)
+	})
+}
+
+func bitLength(n int) int {
+	bits := 0
+	for ; n > 0; n >>= 1 {
+		bits++
+	}
+
+	return bits
+}
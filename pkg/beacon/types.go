@@ -0,0 +1,123 @@
+package beacon
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Genesis is the response of /eth/v1/beacon/genesis
+type Genesis struct {
+	GenesisTime           uint64 `json:"genesis_time,string"`
+	GenesisValidatorsRoot string `json:"genesis_validators_root"`
+	GenesisForkVersion    string `json:"genesis_fork_version"`
+}
+
+// Fork is the response of /eth/v1/beacon/states/{state_id}/fork
+type Fork struct {
+	PreviousVersion string `json:"previous_version"`
+	CurrentVersion  string `json:"current_version"`
+	Epoch           uint64 `json:"epoch,string"`
+}
+
+// Spec is the response of /eth/v1/config/spec. Values are kept raw as most
+// are strings but some, such as BLOB_SCHEDULE, are not.
+type Spec map[string]json.RawMessage
+
+// String returns a string spec value
+func (s Spec) String(key string) (string, error) {
+	raw, ok := s[key]
+	if !ok {
+		return "", errors.Errorf("spec value %s not found", key)
+	}
+
+	var value string
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return "", errors.Wrapf(err, "spec value %s is not a string", key)
+	}
+
+	return value, nil
+}
+
+// Uint64 returns a numeric spec value
+func (s Spec) Uint64(key string) (uint64, error) {
+	value, err := s.String(key)
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to parse %s", key)
+	}
+
+	return n, nil
+}
+
+// Validator is an entry of /eth/v1/beacon/states/{state_id}/validators
+type Validator struct {
+	Index     uint64        `json:"index,string"`
+	Balance   uint64        `json:"balance,string"`
+	Status    string        `json:"status"`
+	Validator ValidatorData `json:"validator"`
+}
+
+// ValidatorData is the registry record of a validator
+type ValidatorData struct {
+	Pubkey                     string `json:"pubkey"`
+	WithdrawalCredentials      string `json:"withdrawal_credentials"`
+	EffectiveBalance           uint64 `json:"effective_balance,string"`
+	Slashed                    bool   `json:"slashed"`
+	ActivationEligibilityEpoch uint64 `json:"activation_eligibility_epoch,string"`
+	ActivationEpoch            uint64 `json:"activation_epoch,string"`
+	ExitEpoch                  uint64 `json:"exit_epoch,string"`
+	WithdrawableEpoch          uint64 `json:"withdrawable_epoch,string"`
+}
+
+// IsActive reports whether a validator is active or pending activation, i.e.
+// whether a voluntary exit for it can still be of use
+func (v *Validator) IsActive() bool {
+	return strings.HasPrefix(v.Status, "active") || v.Status == "pending_initialized" || v.Status == "pending_queued"
+}
+
+// SyncStatus is the response of /eth/v1/node/syncing
+type SyncStatus struct {
+	HeadSlot     uint64 `json:"head_slot,string"`
+	SyncDistance uint64 `json:"sync_distance,string"`
+	IsSyncing    bool   `json:"is_syncing"`
+	IsOptimistic bool   `json:"is_optimistic"`
+	ELOffline    bool   `json:"el_offline"`
+}
+
+// VoluntaryExit is an unsigned voluntary exit message
+type VoluntaryExit struct {
+	Epoch          uint64 `json:"epoch,string"`
+	ValidatorIndex uint64 `json:"validator_index,string"`
+}
+
+// SignedVoluntaryExit is a voluntary exit as found in the pool
+type SignedVoluntaryExit struct {
+	Message   VoluntaryExit `json:"message"`
+	Signature string        `json:"signature"`
+}
+
+// BLSToExecutionChange is an unsigned BLS to execution change message
+type BLSToExecutionChange struct {
+	ValidatorIndex     uint64 `json:"validator_index,string"`
+	FromBLSPubkey      string `json:"from_bls_pubkey"`
+	ToExecutionAddress string `json:"to_execution_address"`
+}
+
+// SignedBLSToExecutionChange is a BLS to execution change as found in the pool
+type SignedBLSToExecutionChange struct {
+	Message   BLSToExecutionChange `json:"message"`
+	Signature string               `json:"signature"`
+}
+
+// Event is a server-sent event of /eth/v1/events
+type Event struct {
+	Topic string
+	Data  json.RawMessage
+}
@@ -0,0 +1,138 @@
+package beacon
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// ValidatorBatchSize is the number of ids posted per validators request
+	ValidatorBatchSize = 1000
+	// validatorQueryBatchSize is the number of ids per GET request, for nodes
+	// without the POST endpoint, keeping URLs well below common length limits
+	validatorQueryBatchSize = 64
+)
+
+func (c *HTTPClient) StreamValidators(ctx context.Context, stateID string, ids []string, fn func(*Validator) error) error {
+	path := "/eth/v1/beacon/states/" + url.PathEscape(stateID) + "/validators"
+	read := func(r io.Reader) error { return decodeValidators(r, fn) }
+
+	if len(ids) == 0 {
+		return errors.Wrap(c.stream(ctx, http.MethodGet, path, nil, nil, read), "failed to fetch validators")
+	}
+
+	for start := 0; start < len(ids); start += ValidatorBatchSize {
+		batch := ids[start:min(start+ValidatorBatchSize, len(ids))]
+
+		body, err := json.Marshal(struct {
+			IDs []string `json:"ids"`
+		}{IDs: batch})
+		if err != nil {
+			return errors.Wrap(err, "failed to encode validator ids")
+		}
+
+		err = c.stream(ctx, http.MethodPost, path, nil, body, read)
+		if err == nil {
+			continue
+		}
+
+		if !isUnsupported(err) {
+			return errors.Wrap(err, "failed to fetch validators")
+		}
+
+		// Older nodes only support id filters on GET
+		log.WithError(err).Debug("POST validators not supported, falling back to GET with id filters")
+
+		for i := 0; i < len(batch); i += validatorQueryBatchSize {
+			query := url.Values{"id": {strings.Join(batch[i:min(i+validatorQueryBatchSize, len(batch))], ",")}}
+
+			if err := c.stream(ctx, http.MethodGet, path, query, nil, read); err != nil {
+				return errors.Wrap(err, "failed to fetch validators")
+			}
+		}
+	}
+
+	return nil
+}
+
+// isUnsupported reports whether err is a response of a node that doesn't
+// implement an endpoint or method
+func isUnsupported(err error) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+
+	switch apiErr.StatusCode {
+	case http.StatusNotFound, http.StatusMethodNotAllowed, http.StatusUnsupportedMediaType, http.StatusNotImplemented:
+		return true
+	default:
+		return false
+	}
+}
+
+// decodeValidators decodes the data array of a validators response one
+// validator at a time, so the full registry is never held in memory
+func decodeValidators(r io.Reader, fn func(*Validator) error) error {
+	dec := json.NewDecoder(r)
+
+	if err := expectDelim(dec, '{'); err != nil {
+		return err
+	}
+
+	for dec.More() {
+		token, err := dec.Token()
+		if err != nil {
+			return errors.Wrap(err, "failed to parse response")
+		}
+
+		if key, _ := token.(string); key != "data" {
+			var skip json.RawMessage
+			if err := dec.Decode(&skip); err != nil {
+				return errors.Wrap(err, "failed to parse response")
+			}
+
+			continue
+		}
+
+		if err := expectDelim(dec, '['); err != nil {
+			return err
+		}
+
+		for dec.More() {
+			validator := &Validator{}
+			if err := dec.Decode(validator); err != nil {
+				return errors.Wrap(err, "failed to parse response data")
+			}
+
+			if err := fn(validator); err != nil {
+				return err
+			}
+		}
+
+		if err := expectDelim(dec, ']'); err != nil {
+			return err
+		}
+	}
+
+	return expectDelim(dec, '}')
+}
+
+func expectDelim(dec *json.Decoder, delim json.Delim) error {
+	token, err := dec.Token()
+	if err != nil {
+		return errors.Wrap(err, "failed to parse response")
+	}
+
+	if token != delim {
+		return errors.Errorf("failed to parse response: expected %s, got %v", delim, token)
+	}
+
+	return nil
+}
@@ -0,0 +1,181 @@
+package beacon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// validatorServer serves a registry of count validators with pubkey 0x<index>,
+// answering id filters on GET and, unless postUnsupported, on POST, and single
+// validators by index
+type validatorServer struct {
+	count           int
+	postUnsupported bool
+
+	mu       sync.Mutex
+	requests []string
+	probes   int
+}
+
+func (s *validatorServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var ids []string
+
+	s.mu.Lock()
+	s.probes++
+	s.mu.Unlock()
+
+	switch {
+	case r.URL.Path == "/eth/v1/beacon/states/head/validators/0xaa":
+		_, _ = w.Write([]byte(`{"data":{"index":"5","balance":"0","status":"exited_unslashed","validator":{"pubkey":"0xaa"}}}`))
+
+		return
+	case strings.HasPrefix(r.URL.Path, "/eth/v1/beacon/states/finalized/validators/"):
+		index, err := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/eth/v1/beacon/states/finalized/validators/"))
+		if err != nil || index >= s.count {
+			w.WriteHeader(http.StatusNotFound)
+
+			return
+		}
+
+		_, _ = fmt.Fprintf(w, `{"data":{"index":"%d","balance":"0","status":"active_ongoing","validator":{"pubkey":"0x%x"}}}`, index, index)
+
+		return
+	case r.URL.Path != "/eth/v1/beacon/states/finalized/validators":
+		w.WriteHeader(http.StatusNotFound)
+
+		return
+	case r.Method == http.MethodPost && s.postUnsupported:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+
+		return
+	case r.Method == http.MethodPost:
+		var body struct {
+			IDs []string `json:"ids"`
+		}
+
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+
+			return
+		}
+
+		ids = body.IDs
+	case r.URL.Query().Get("id") != "":
+		ids = strings.Split(r.URL.Query().Get("id"), ",")
+	}
+
+	s.mu.Lock()
+	s.requests = append(s.requests, fmt.Sprintf("%s %d", r.Method, len(ids)))
+	s.mu.Unlock()
+
+	wanted := map[string]bool{}
+	for _, id := range ids {
+		wanted[id] = true
+	}
+
+	entries := make([]string, 0, s.count)
+
+	for i := 0; i < s.count; i++ {
+		if len(ids) > 0 && !wanted[strconv.Itoa(i)] && !wanted[fmt.Sprintf("0x%x", i)] {
+			continue
+		}
+
+		entries = append(entries, fmt.Sprintf(`{"index":"%d","balance":"32000000000","status":"active_ongoing","validator":{
+			"pubkey":"0x%x","withdrawal_credentials":"0x01","effective_balance":"32000000000","slashed":false,
+			"activation_eligibility_epoch":"0","activation_epoch":"0",
+			"exit_epoch":"18446744073709551615","withdrawable_epoch":"18446744073709551615"}}`, i, i))
+	}
+
+	_, _ = w.Write([]byte(`{"execution_optimistic":false,"finalized":true,"data":[` + strings.Join(entries, ",") + `]}`))
+}
+
+func TestClientValidators(t *testing.T) {
+	registry := &validatorServer{count: 10}
+	server := httptest.NewServer(registry)
+	defer server.Close()
+
+	client := newTestClient(t, server.URL, nil)
+	ctx := context.Background()
+
+	validators, err := client.Validators(ctx, "finalized", nil)
+	require.NoError(t, err)
+	require.Len(t, validators, 10)
+	assert.Equal(t, uint64(9), validators[9].Index)
+	assert.Equal(t, uint64(18446744073709551615), validators[0].Validator.ExitEpoch)
+	assert.True(t, validators[0].IsActive())
+
+	validators, err = client.Validators(ctx, "finalized", []string{"5", "0x7"})
+	require.NoError(t, err)
+	require.Len(t, validators, 2)
+	assert.Equal(t, []string{"GET 0", "POST 2"}, registry.requests)
+
+	validator, err := client.Validator(ctx, "head", "0xaa")
+	require.NoError(t, err)
+	assert.Equal(t, "exited_unslashed", validator.Status)
+	assert.False(t, validator.IsActive())
+
+	_, err = client.Validator(ctx, "head", "6")
+	require.Error(t, err)
+	assert.True(t, IsNotFound(err))
+}
+
+func TestClientValidatorBatches(t *testing.T) {
+	ids := make([]string, 0, ValidatorBatchSize+100)
+	for i := 0; i < ValidatorBatchSize+100; i++ {
+		ids = append(ids, strconv.Itoa(i))
+	}
+
+	t.Run("POST in batches", func(t *testing.T) {
+		registry := &validatorServer{count: len(ids)}
+		server := httptest.NewServer(registry)
+		defer server.Close()
+
+		validators, err := newTestClient(t, server.URL, nil).Validators(context.Background(), "finalized", ids)
+		require.NoError(t, err)
+		assert.Len(t, validators, len(ids))
+		assert.Equal(t, []string{fmt.Sprintf("POST %d", ValidatorBatchSize), "POST 100"}, registry.requests)
+	})
+
+	t.Run("GET id filters without POST support", func(t *testing.T) {
+		registry := &validatorServer{count: len(ids), postUnsupported: true}
+		server := httptest.NewServer(registry)
+		defer server.Close()
+
+		validators, err := newTestClient(t, server.URL, nil).Validators(context.Background(), "finalized", ids[:100])
+		require.NoError(t, err)
+		assert.Len(t, validators, 100)
+		assert.Equal(t, []string{fmt.Sprintf("GET %d", validatorQueryBatchSize), fmt.Sprintf("GET %d", 100-validatorQueryBatchSize)}, registry.requests)
+	})
+}
+
+func TestDecodeValidators(t *testing.T) {
+	var indices []uint64
+
+	err := decodeValidators(strings.NewReader(`{"finalized":true,"data":[{"index":"1"},{"index":"2"}],"execution_optimistic":false}`), func(v *Validator) error {
+		indices = append(indices, v.Index)
+
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{1, 2}, indices)
+
+	stop := errors.New("stop")
+	err = decodeValidators(strings.NewReader(`{"data":[{"index":"1"},{"index":"2"}]}`), func(*Validator) error { return stop })
+	assert.ErrorIs(t, err, stop)
+
+	for _, invalid := range []string{`invalid json`, `[]`, `{"data":{}}`, `{"data":[{"index":1}]}`, `{"data":[`} {
+		err := decodeValidators(strings.NewReader(invalid), func(*Validator) error { return nil })
+		assert.Error(t, err, invalid)
+	}
+}
@@ -0,0 +1,244 @@
+package validator
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/ethpandaops/validator-tools/pkg/atomicfile"
+)
+
+// ChecksumsFileName is the name of the checksum manifest kept in exit directories.
+const ChecksumsFileName = "SHA256SUMS"
+
+// ChecksumReport contains the result of checking a directory against its checksum manifest
+type ChecksumReport struct {
+	Missing  []string `json:"missing"`
+	Extra    []string `json:"extra"`
+	Modified []string `json:"modified"`
+}
+
+// OK reports whether the directory matched the manifest exactly
+func (r *ChecksumReport) OK() bool {
+	return len(r.Missing) == 0 && len(r.Extra) == 0 && len(r.Modified) == 0
+}
+
+// Err returns an error summarising the report, or nil if the directory matched
+func (r *ChecksumReport) Err() error {
+	if r.OK() {
+		return nil
+	}
+
+	return fmt.Errorf("checksum mismatch: %d missing, %d extra, %d modified files",
+		len(r.Missing), len(r.Extra), len(r.Modified))
+}
+
+// WriteChecksums computes the SHA-256 of every exit file in dir and atomically
+// writes them to dir/SHA256SUMS in the format used by sha256sum.
+func WriteChecksums(dir string) error {
+	files, err := checksumTargets(dir)
+	if err != nil {
+		return err
+	}
+
+	return updateChecksums(dir, map[string]string{}, files)
+}
+
+// UpdateChecksums computes the SHA-256 of the named files in dir and merges
+// them into dir/SHA256SUMS, keeping the digests of all other files, so that
+// only newly written files are hashed. A missing manifest is created.
+func UpdateChecksums(dir string, names []string) error {
+	sums := map[string]string{}
+
+	path := filepath.Join(dir, ChecksumsFileName)
+	if _, err := os.Stat(path); err == nil {
+		if sums, err = readChecksums(path); err != nil {
+			return err
+		}
+	} else if !os.IsNotExist(err) {
+		return errors.Wrap(err, "failed to stat checksum manifest")
+	}
+
+	return updateChecksums(dir, sums, names)
+}
+
+// updateChecksums hashes the named files into sums and atomically writes
+// them to dir/SHA256SUMS in the format used by sha256sum
+func updateChecksums(dir string, sums map[string]string, names []string) error {
+	for _, name := range names {
+		sum, err := fileSHA256(filepath.Join(dir, name))
+		if err != nil {
+			return err
+		}
+
+		sums[name] = sum
+	}
+
+	files := make([]string, 0, len(sums))
+	for name := range sums {
+		files = append(files, name)
+	}
+
+	sort.Strings(files)
+
+	var buf bytes.Buffer
+
+	for _, name := range files {
+		fmt.Fprintf(&buf, "%s  %s\n", sums[name], name)
+	}
+
+	if err := atomicfile.WriteFile(filepath.Join(dir, ChecksumsFileName), buf.Bytes(), 0o644); err != nil {
+		return errors.Wrap(err, "failed to write checksum manifest")
+	}
+
+	log.WithFields(logrus.Fields{
+		"dir":     dir,
+		"files":   len(files),
+		"updated": len(names),
+	}).Debug("Checksum manifest updated")
+
+	return nil
+}
+
+// VerifyChecksums compares the exit files in dir against dir/SHA256SUMS
+func VerifyChecksums(dir string) (*ChecksumReport, error) {
+	expected, err := readChecksums(filepath.Join(dir, ChecksumsFileName))
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := checksumTargets(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ChecksumReport{}
+	present := make(map[string]bool, len(files))
+
+	for _, name := range files {
+		present[name] = true
+
+		want, ok := expected[name]
+		if !ok {
+			report.Extra = append(report.Extra, name)
+
+			continue
+		}
+
+		got, err := fileSHA256(filepath.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+
+		if got != want {
+			report.Modified = append(report.Modified, name)
+		}
+	}
+
+	for name := range expected {
+		if !present[name] {
+			report.Missing = append(report.Missing, name)
+		}
+	}
+
+	sort.Strings(report.Missing)
+
+	for _, name := range report.Missing {
+		log.WithField("file", name).Error("File listed in checksum manifest is missing")
+	}
+
+	for _, name := range report.Extra {
+		log.WithField("file", name).Error("File is not listed in checksum manifest")
+	}
+
+	for _, name := range report.Modified {
+		log.WithField("file", name).Error("File does not match checksum manifest")
+	}
+
+	return report, nil
+}
+
+// checksumTargets returns the sorted names of the files covered by the checksum
+// manifest: every exit file plus the provenance manifest.
+func checksumTargets(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read directory %s", dir)
+	}
+
+	var files []string
+
+	for _, entry := range entries {
+		if isExitFile(entry) || (!entry.IsDir() && entry.Name() == ManifestFileName) {
+			files = append(files, entry.Name())
+		}
+	}
+
+	sort.Strings(files)
+
+	return files, nil
+}
+
+// readChecksums parses a sha256sum-style manifest into a map of file name to digest
+func readChecksums(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open checksum manifest")
+	}
+	defer f.Close()
+
+	sums := make(map[string]string)
+	scanner := bufio.NewScanner(f)
+
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		fields := strings.Fields(text)
+		if len(fields) != 2 {
+			return nil, errors.Errorf("invalid checksum manifest line %d", line)
+		}
+
+		sum := strings.ToLower(fields[0])
+		if _, err := hex.DecodeString(sum); err != nil || len(sum) != sha256.Size*2 {
+			return nil, errors.Errorf("invalid digest on checksum manifest line %d", line)
+		}
+
+		// sha256sum marks binary mode entries with a leading '*'
+		sums[strings.TrimPrefix(fields[1], "*")] = sum
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrap(err, "failed to read checksum manifest")
+	}
+
+	return sums, nil
+}
+
+// fileSHA256 returns the hex encoded SHA-256 digest of a file
+func fileSHA256(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to open %s", path)
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", errors.Wrapf(err, "failed to hash %s", path)
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
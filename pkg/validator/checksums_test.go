@@ -0,0 +1,130 @@
+package validator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChecksums(t *testing.T) {
+	tests := []struct {
+		name           string
+		mutate         func(t *testing.T, dir string)
+		expectMissing  []string
+		expectExtra    []string
+		expectModified []string
+	}{
+		{
+			name:   "unchanged directory",
+			mutate: func(t *testing.T, dir string) { t.Helper() },
+		},
+		{
+			name: "missing file",
+			mutate: func(t *testing.T, dir string) {
+				t.Helper()
+				require.NoError(t, os.Remove(filepath.Join(dir, "1-abc.json")))
+			},
+			expectMissing: []string{"1-abc.json"},
+		},
+		{
+			name: "extra file",
+			mutate: func(t *testing.T, dir string) {
+				t.Helper()
+				require.NoError(t, os.WriteFile(filepath.Join(dir, "3-abc.json"), []byte(`{}`), 0o600))
+			},
+			expectExtra: []string{"3-abc.json"},
+		},
+		{
+			name: "modified file",
+			mutate: func(t *testing.T, dir string) {
+				t.Helper()
+				require.NoError(t, os.WriteFile(filepath.Join(dir, "2-abc.json"), []byte(`{"tampered": true}`), 0o600))
+			},
+			expectModified: []string{"2-abc.json"},
+		},
+		{
+			name: "leftover temporary file is ignored",
+			mutate: func(t *testing.T, dir string) {
+				t.Helper()
+				require.NoError(t, os.WriteFile(filepath.Join(dir, ".4-abc.json.tmp-123"), []byte(`{"mess`), 0o600))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			require.NoError(t, os.WriteFile(filepath.Join(dir, "1-abc.json"), []byte(`{"index": 1}`), 0o600))
+			require.NoError(t, os.WriteFile(filepath.Join(dir, "2-abc.json"), []byte(`{"index": 2}`), 0o600))
+
+			require.NoError(t, WriteChecksums(dir))
+
+			tt.mutate(t, dir)
+
+			report, err := VerifyChecksums(dir)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.expectMissing, report.Missing)
+			assert.Equal(t, tt.expectExtra, report.Extra)
+			assert.Equal(t, tt.expectModified, report.Modified)
+
+			if tt.expectMissing == nil && tt.expectExtra == nil && tt.expectModified == nil {
+				assert.True(t, report.OK())
+				assert.NoError(t, report.Err())
+			} else {
+				assert.False(t, report.OK())
+				assert.Error(t, report.Err())
+			}
+		})
+	}
+}
+
+func TestWriteChecksumsFormat(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "1-abc.json"), []byte("hello\n"), 0o600))
+
+	require.NoError(t, WriteChecksums(dir))
+
+	content, err := os.ReadFile(filepath.Join(dir, ChecksumsFileName))
+	require.NoError(t, err)
+
+	// Output of `echo hello | sha256sum`
+	assert.Equal(t, "5891b5b522d5df086d0ff0b110fbd9d21bb4fc7163af34d08286a2e846f6be03  1-abc.json\n", string(content))
+}
+
+func TestUpdateChecksums(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "1-abc.json"), []byte(`{"index": 1}`), 0o600))
+
+	// A missing manifest is created
+	require.NoError(t, UpdateChecksums(dir, []string{"1-abc.json"}))
+
+	// Only the new file is hashed, the existing entry is kept as is
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "2-abc.json"), []byte(`{"index": 2}`), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "1-abc.json"), []byte(`{"tampered": true}`), 0o600))
+	require.NoError(t, UpdateChecksums(dir, []string{"2-abc.json"}))
+
+	report, err := VerifyChecksums(dir)
+	require.NoError(t, err)
+	assert.Empty(t, report.Missing)
+	assert.Empty(t, report.Extra)
+	assert.Equal(t, []string{"1-abc.json"}, report.Modified)
+
+	assert.Error(t, UpdateChecksums(dir, []string{"3-abc.json"}))
+}
+
+func TestVerifyChecksumsMissingManifest(t *testing.T) {
+	_, err := VerifyChecksums(t.TempDir())
+	assert.Error(t, err)
+}
+
+func TestReadChecksumsInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ChecksumsFileName)
+	require.NoError(t, os.WriteFile(path, []byte("nothex  1-abc.json\n"), 0o600))
+
+	_, err := readChecksums(path)
+	assert.Error(t, err)
+}
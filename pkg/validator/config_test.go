@@ -1,60 +1,18 @@
 package validator
 
 import (
+	"context"
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
-)
-
-func TestFetchJSON(t *testing.T) {
-	tests := []struct {
-		name           string
-		responseCode   int
-		responseBody   string
-		expectedError  bool
-		expectedOutput string
-	}{
-		{
-			name:           "successful fetch",
-			responseCode:   http.StatusOK,
-			responseBody:   `{"data": "test"}`,
-			expectedError:  false,
-			expectedOutput: `{"data": "test"}`,
-		},
-		{
-			name:          "non-200 status code",
-			responseCode:  http.StatusNotFound,
-			responseBody:  "not found",
-			expectedError: true,
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-				w.WriteHeader(tt.responseCode)
-				_, err := w.Write([]byte(tt.responseBody))
-				require.NoError(t, err)
-			}))
-			defer server.Close()
-
-			g := &VoluntaryExitGenerator{}
-			result, err := g.FetchJSON(server.URL)
-
-			if tt.expectedError {
-				assert.Error(t, err)
-
-				return
-			}
 
-			require.NoError(t, err)
-			assert.Equal(t, tt.expectedOutput, string(result))
-		})
-	}
-}
+	"github.com/ethpandaops/validator-tools/pkg/beacon"
+)
 
 func TestFetchBeaconConfig(t *testing.T) {
 	tests := []struct {
@@ -114,6 +72,31 @@ func TestFetchBeaconConfig(t *testing.T) {
 			}`,
 			expectedError: false,
 		},
+		{
+			name: "capella epoch compared as exits were always generated",
+			genesisResp: `{
+				"data": {
+					"genesis_validators_root": "0x1234",
+					"genesis_fork_version": "0x5678"
+				}
+			}`,
+			forkResp: `{
+				"data": {
+					"previous_version": "0x9abc",
+					"current_version": "0xdef0"
+				}
+			}`,
+			specResp: `{
+				"data": {
+					"DOMAIN_BLS_TO_EXECUTION_CHANGE": "0x0abc",
+					"DOMAIN_VOLUNTARY_EXIT": "0x0def",
+					"MIN_VALIDATOR_WITHDRAWABILITY_DELAY": "256",
+					"CAPELLA_FORK_VERSION": "0x9abc",
+					"CAPELLA_FORK_EPOCH": "194048"
+				}
+			}`,
+			expectedError: false,
+		},
 		{
 			name: "genesis fetch error",
 			genesisResp: `{
@@ -155,11 +138,10 @@ func TestFetchBeaconConfig(t *testing.T) {
 
 			defer server.Close()
 
-			g := &VoluntaryExitGenerator{
-				BeaconURL: server.URL,
-			}
+			client, err := beacon.NewClient(server.URL, nil)
+			require.NoError(t, err)
 
-			config, err := g.FetchBeaconConfig()
+			config, err := FetchBeaconConfig(context.Background(), client)
 			if tt.expectedError {
 				assert.Error(t, err)
 
@@ -176,6 +158,8 @@ func TestFetchBeaconConfig(t *testing.T) {
 				assert.Equal(t, "0xdef0", config.CurrentForkVersion)
 				assert.Equal(t, "0x0abc", config.BlsToExecutionChangeDomain)
 				assert.Equal(t, "0x0def", config.VoluntaryExitDomain)
+			} else if tt.name == "capella epoch compared as exits were always generated" {
+				assert.Equal(t, "256", config.Epoch)
 			} else if tt.name == "empty responses" {
 				assert.Equal(t, "", config.GenesisValidatorsRoot)
 				assert.Equal(t, "", config.GenesisVersion)
@@ -188,3 +172,71 @@ func TestFetchBeaconConfig(t *testing.T) {
 		})
 	}
 }
+
+// TestFetchBeaconConfigExitEpoch pins the exit epoch of every built-in network
+// when fetched from a beacon node. Earlier releases compared the spec values as
+// strings, so mainnet and chiado, whose Capella fork epochs sort before "256",
+// were given epoch 256.
+func TestFetchBeaconConfigExitEpoch(t *testing.T) {
+	// The epochs exits have always been generated with
+	tests := []struct {
+		network  string
+		expected string
+	}{
+		{network: "mainnet", expected: "256"},
+		{network: "holesky", expected: "256"},
+		{network: "hoodi", expected: "256"},
+		{network: "sepolia", expected: "56832"},
+		{network: "gnosis", expected: "648704"},
+		{network: "chiado", expected: "256"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.network, func(t *testing.T) {
+			cfg, err := networkConfig(tt.network)
+			require.NoError(t, err)
+
+			spec := map[string]string{
+				"DOMAIN_BLS_TO_EXECUTION_CHANGE":      "0x0a000000",
+				"DOMAIN_VOLUNTARY_EXIT":               "0x04000000",
+				"CAPELLA_FORK_VERSION":                "0x03000000",
+				"CAPELLA_FORK_EPOCH":                  strconv.FormatUint(uint64(cfg.CapellaForkEpoch), 10),
+				"MIN_VALIDATOR_WITHDRAWABILITY_DELAY": strconv.FormatUint(uint64(cfg.MinValidatorWithdrawabilityDelay), 10),
+			}
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var data interface{}
+
+				switch r.URL.Path {
+				case "/eth/v1/beacon/genesis":
+					data = map[string]string{"genesis_validators_root": "0x1234", "genesis_fork_version": "0x5678"}
+				case "/eth/v1/beacon/states/head/fork":
+					data = map[string]string{"previous_version": "0x9abc", "current_version": "0xdef0"}
+				case "/eth/v1/config/spec":
+					data = spec
+				default:
+					w.WriteHeader(http.StatusNotFound)
+
+					return
+				}
+
+				w.Header().Set("Content-Type", "application/json")
+				require.NoError(t, json.NewEncoder(w).Encode(map[string]interface{}{"data": data}))
+			}))
+
+			defer server.Close()
+
+			client, err := beacon.NewClient(server.URL, nil)
+			require.NoError(t, err)
+
+			config, err := FetchBeaconConfig(context.Background(), client)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, config.Epoch)
+
+			// Offline generation uses the same epoch
+			offline, err := NewBeaconConfigFromNetwork(tt.network)
+			require.NoError(t, err)
+			assert.Equal(t, config.Epoch, offline.Epoch)
+		})
+	}
+}
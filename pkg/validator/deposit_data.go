@@ -4,6 +4,7 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"os"
+	"strings"
 
 	"github.com/pkg/errors"
 	"github.com/prysmaticlabs/prysm/v5/beacon-chain/core/signing"
@@ -22,6 +23,7 @@ type ExpectedData struct {
 	Amount         uint64
 	WithdrawalCred string
 	Count          int
+	ForkVersion    string
 }
 
 type ParsedData struct {
@@ -41,6 +43,17 @@ type Deposit struct {
 	ForkVersion           string `json:"fork_version"`
 }
 
+// DefaultDepositAmount returns the deposit amount expected for a full validator
+// deposit on a network, in Gwei (mGNO on gnosis and chiado)
+func DefaultDepositAmount(network string) uint64 {
+	cfg, err := networkConfig(network)
+	if err != nil {
+		return params.MainnetConfig().MinActivationBalance
+	}
+
+	return cfg.MinActivationBalance
+}
+
 func NewData(path, expectedNetwork, expectedWithdrawalCred string, expectedAmount uint64, expectedCount int) (*Data, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
@@ -85,6 +98,17 @@ func NewData(path, expectedNetwork, expectedWithdrawalCred string, expectedAmoun
 
 	log.Printf("Deposit data: %v", depositData)
 
+	// Deposits are signed with the genesis fork version, so when the network is
+	// known the fork version can be checked as well as the network name
+	var expectedForkVersion string
+	if cfg, err := networkConfig(expectedNetwork); err == nil {
+		expectedForkVersion = hex.EncodeToString(cfg.GenesisForkVersion)
+	}
+
+	if expectedAmount == 0 {
+		expectedAmount = DefaultDepositAmount(expectedNetwork)
+	}
+
 	return &Data{
 		DepositData: depositData,
 		ExpectedData: &ExpectedData{
@@ -92,6 +116,7 @@ func NewData(path, expectedNetwork, expectedWithdrawalCred string, expectedAmoun
 			Amount:         expectedAmount,
 			WithdrawalCred: expectedWithdrawalCred,
 			Count:          expectedCount,
+			ForkVersion:    expectedForkVersion,
 		},
 	}, nil
 }
@@ -123,6 +148,10 @@ func (d *Deposit) Validate(expectedData *ExpectedData) error {
 		return errors.Errorf("withdrawal credentials mismatch: expected %s, got %s", expectedData.WithdrawalCred, d.WithdrawalCredentials)
 	}
 
+	if expectedData.ForkVersion != "" && !strings.EqualFold(strings.TrimPrefix(d.ForkVersion, "0x"), strings.TrimPrefix(expectedData.ForkVersion, "0x")) {
+		return errors.Errorf("fork version mismatch: expected %s, got %s", expectedData.ForkVersion, d.ForkVersion)
+	}
+
 	return nil
 }
 
@@ -2,6 +2,8 @@ package validator
 
 import (
 	"encoding/hex"
+	"os"
+	"path/filepath"
 	"testing"
 
 	ethpb "github.com/prysmaticlabs/prysm/v5/proto/prysm/v1alpha1"
@@ -76,6 +78,23 @@ func TestDeposit_Validate(t *testing.T) {
 			wantErr: true,
 			errMsg:  "withdrawal credentials mismatch: expected 0x010000000000000000000000844d391c4074c548b7c968739e717a949358c721, got 0x010000000000000000000000844d391c4074c548b7c968739e717a949358c722",
 		},
+		{
+			name: "fork version mismatch",
+			deposit: &Deposit{
+				NetworkName:           "mainnet",
+				Amount:                32000000000,
+				WithdrawalCredentials: "0x010000000000000000000000844d391c4074c548b7c968739e717a949358c721",
+				ForkVersion:           "10000038",
+			},
+			expectedData: &ExpectedData{
+				Network:        "mainnet",
+				Amount:         32000000000,
+				WithdrawalCred: "0x010000000000000000000000844d391c4074c548b7c968739e717a949358c721",
+				ForkVersion:    "00000000",
+			},
+			wantErr: true,
+			errMsg:  "fork version mismatch: expected 00000000, got 10000038",
+		},
 	}
 
 	for _, tt := range tests {
@@ -136,3 +155,34 @@ func TestData_Validate_Count(t *testing.T) {
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "count mismatch: expected 3, got 2")
 }
+
+func TestNewData_ExpectedForkVersion(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "deposit_data.json")
+	require.NoError(t, os.WriteFile(path, []byte(`[]`), 0o600))
+
+	data, err := NewData(path, "hoodi", "", 32000000000, 0)
+	require.NoError(t, err)
+	assert.Equal(t, "10000910", data.ExpectedData.ForkVersion)
+
+	data, err = NewData(path, "", "", 32000000000, 0)
+	require.NoError(t, err)
+	assert.Empty(t, data.ExpectedData.ForkVersion)
+}
+
+func TestNewData_DefaultAmount(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "deposit_data.json")
+	require.NoError(t, os.WriteFile(path, []byte(`[]`), 0o600))
+
+	data, err := NewData(path, "gnosis", "", 0, 0)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(32000000000), data.ExpectedData.Amount)
+	assert.Equal(t, "00000064", data.ExpectedData.ForkVersion)
+
+	data, err = NewData(path, "chiado", "", 0, 0)
+	require.NoError(t, err)
+	assert.Equal(t, "0000006f", data.ExpectedData.ForkVersion)
+
+	data, err = NewData(path, "mainnet", "", 1000000000, 0)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1000000000), data.ExpectedData.Amount)
+}
@@ -0,0 +1,322 @@
+package validator
+
+import (
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/prysm/v5/beacon-chain/core/signing"
+	"github.com/prysmaticlabs/prysm/v5/config/params"
+	"github.com/prysmaticlabs/prysm/v5/contracts/deposit"
+	"github.com/prysmaticlabs/prysm/v5/crypto/bls"
+	"github.com/sirupsen/logrus"
+)
+
+// SigningDomain identifies a network and fork version combination a signature was made for
+type SigningDomain struct {
+	Network     string `json:"network"`
+	Fork        string `json:"fork"`
+	ForkVersion string `json:"fork_version"`
+}
+
+// Diagnosis reports which signing domains verify the signature of a single exit or deposit
+type Diagnosis struct {
+	File           string          `json:"file"`
+	Pubkey         string          `json:"pubkey"`
+	ValidatorIndex *uint64         `json:"validator_index,omitempty"`
+	Matches        []SigningDomain `json:"matches"`
+	Problem        string          `json:"problem,omitempty"`
+}
+
+// signingFork is a named fork version of a network
+type signingFork struct {
+	name    string
+	version []byte
+}
+
+// networkForks returns every fork version of a network, whether or not it is scheduled
+func networkForks(cfg *params.BeaconChainConfig) []signingFork {
+	return []signingFork{
+		{"phase0", cfg.GenesisForkVersion},
+		{"altair", cfg.AltairForkVersion},
+		{"bellatrix", cfg.BellatrixForkVersion},
+		{"capella", cfg.CapellaForkVersion},
+		{"deneb", cfg.DenebForkVersion},
+		{"electra", cfg.ElectraForkVersion},
+		{"fulu", cfg.FuluForkVersion},
+	}
+}
+
+// DiagnoseExits tries every known network and fork version against each exit
+// in dir, diagnosing files on numWorkers goroutines. expectedNetwork is
+// optional; when set, exits that only verify for another network or fork are
+// flagged. Diagnoses are returned in the order of the files.
+func DiagnoseExits(dir, expectedNetwork string, numWorkers int) ([]*Diagnosis, error) {
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read directory")
+	}
+
+	var paths []string
+
+	for _, file := range files {
+		if isExitFile(file) {
+			paths = append(paths, filepath.Join(dir, file.Name()))
+		}
+	}
+
+	domains, err := exitDomains(expectedNetwork)
+	if err != nil {
+		return nil, err
+	}
+
+	diagnoses := make([]*Diagnosis, len(paths))
+	errs := make([]error, len(paths))
+
+	runParallel(len(paths), numWorkers, nil, func(i int) {
+		vexit, err := readExitFile(paths[i])
+		if err != nil {
+			diagnoses[i] = &Diagnosis{
+				File:    paths[i],
+				Problem: fmt.Sprintf("failed to read exit: %v", err),
+			}
+
+			return
+		}
+
+		diagnoses[i], errs[i] = diagnoseExit(vexit, domains, expectedNetwork)
+	})
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return diagnoses, nil
+}
+
+// candidateDomain is a voluntary exit signing domain to try
+type candidateDomain struct {
+	SigningDomain
+	domain []byte
+}
+
+// exitDomains returns the voluntary exit domain of every known network and
+// fork version. The Capella domain of expectedNetwork, the one exits must be
+// signed with, comes first.
+func exitDomains(expectedNetwork string) ([]candidateDomain, error) {
+	var domains []candidateDomain
+
+	for _, network := range networkNames() {
+		cfg, err := networkConfig(network)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, fork := range networkForks(cfg) {
+			domain, err := signing.ComputeDomain(cfg.DomainVoluntaryExit, fork.version, cfg.GenesisValidatorsRoot[:])
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to compute domain")
+			}
+
+			candidate := candidateDomain{
+				SigningDomain: SigningDomain{
+					Network:     network,
+					Fork:        fork.name,
+					ForkVersion: "0x" + hex.EncodeToString(fork.version),
+				},
+				domain: domain,
+			}
+
+			if network == expectedNetwork && fork.name == "capella" {
+				domains = append([]candidateDomain{candidate}, domains...)
+
+				continue
+			}
+
+			domains = append(domains, candidate)
+		}
+	}
+
+	return domains, nil
+}
+
+// DiagnoseExit reports which signing domains verify a voluntary exit signature.
+// Since Deneb (EIP-7044) exits must be signed with the Capella fork version.
+func DiagnoseExit(vexit *VoluntaryExit, expectedNetwork string) (*Diagnosis, error) {
+	domains, err := exitDomains(expectedNetwork)
+	if err != nil {
+		return nil, err
+	}
+
+	return diagnoseExit(vexit, domains, expectedNetwork)
+}
+
+// diagnoseExit tries domains, as returned by exitDomains, against the
+// signature of an exit. It stops at once when the exit verifies for the
+// Capella domain of expectedNetwork.
+func diagnoseExit(vexit *VoluntaryExit, domains []candidateDomain, expectedNetwork string) (*Diagnosis, error) {
+	index := uint64(vexit.PBExit.Exit.ValidatorIndex)
+	diagnosis := &Diagnosis{
+		File:           vexit.Path,
+		Pubkey:         "0x" + hex.EncodeToString(vexit.Pubkey),
+		ValidatorIndex: &index,
+		Matches:        []SigningDomain{},
+	}
+
+	pubkey, sig, err := parseSignature(vexit.Pubkey, vexit.PBExit.Signature)
+	if err != nil {
+		diagnosis.Problem = err.Error()
+
+		return diagnosis, nil
+	}
+
+	for i, candidate := range domains {
+		root, err := signing.ComputeSigningRoot(vexit.PBExit.Exit, candidate.domain)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to compute signing root")
+		}
+
+		if !sig.Verify(pubkey, root[:]) {
+			continue
+		}
+
+		diagnosis.Matches = append(diagnosis.Matches, candidate.SigningDomain)
+
+		if i == 0 && candidate.Network == expectedNetwork && candidate.Fork == "capella" {
+			break
+		}
+	}
+
+	diagnosis.Problem = domainProblem(diagnosis.Matches, expectedNetwork, "capella")
+
+	return diagnosis, nil
+}
+
+// DiagnoseDeposits tries every known network and fork version against each
+// deposit in a deposit data file. Deposits are signed with the genesis fork
+// version and no genesis validators root.
+func DiagnoseDeposits(path, expectedNetwork string) ([]*Diagnosis, error) {
+	data, err := NewData(path, expectedNetwork, "", 0, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	diagnoses := make([]*Diagnosis, 0, len(data.DepositData))
+
+	for _, set := range data.DepositData {
+		diagnosis := &Diagnosis{
+			File:    path,
+			Pubkey:  "0x" + set.Deposit.PubKey,
+			Matches: []SigningDomain{},
+		}
+
+		for _, network := range networkNames() {
+			cfg, err := networkConfig(network)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, fork := range networkForks(cfg) {
+				domain, err := signing.ComputeDomain(cfg.DomainDeposit, fork.version, nil)
+				if err != nil {
+					return nil, errors.Wrap(err, "failed to compute domain")
+				}
+
+				if deposit.VerifyDepositSignature(set.PBData, domain) == nil {
+					diagnosis.Matches = append(diagnosis.Matches, SigningDomain{
+						Network:     network,
+						Fork:        fork.name,
+						ForkVersion: "0x" + hex.EncodeToString(fork.version),
+					})
+				}
+			}
+		}
+
+		diagnosis.Problem = domainProblem(diagnosis.Matches, expectedNetwork, "phase0")
+		diagnoses = append(diagnoses, diagnosis)
+	}
+
+	return diagnoses, nil
+}
+
+// LogDiagnoses logs each diagnosis and returns the number with a problem
+func LogDiagnoses(diagnoses []*Diagnosis) int {
+	problems := 0
+
+	for _, d := range diagnoses {
+		fields := logrus.Fields{
+			"file":   d.File,
+			"pubkey": d.Pubkey,
+		}
+
+		if d.ValidatorIndex != nil {
+			fields["validator_index"] = *d.ValidatorIndex
+		}
+
+		for i, match := range d.Matches {
+			fields[fmt.Sprintf("match_%d", i)] = fmt.Sprintf("%s/%s (%s)", match.Network, match.Fork, match.ForkVersion)
+		}
+
+		if d.Problem != "" {
+			problems++
+
+			log.WithFields(fields).Warn(d.Problem)
+
+			continue
+		}
+
+		log.WithFields(fields).Info("Signature verifies for the expected domain")
+	}
+
+	return problems
+}
+
+// domainProblem describes what is wrong with the matched domains given the
+// expected network and fork, or returns an empty string if nothing is
+func domainProblem(matches []SigningDomain, expectedNetwork, expectedFork string) string {
+	if len(matches) == 0 {
+		return "signature does not verify for any known network and fork version"
+	}
+
+	if expectedNetwork == "" {
+		return ""
+	}
+
+	var onNetwork *SigningDomain
+
+	for i, match := range matches {
+		if match.Network != expectedNetwork {
+			continue
+		}
+
+		if match.Fork == expectedFork {
+			return ""
+		}
+
+		onNetwork = &matches[i]
+	}
+
+	if onNetwork != nil {
+		return fmt.Sprintf("signed with the %s fork version %s instead of the %s fork version", onNetwork.Fork, onNetwork.ForkVersion, expectedFork)
+	}
+
+	return fmt.Sprintf("signed for network %s instead of %s", matches[0].Network, expectedNetwork)
+}
+
+func parseSignature(pubkeyBytes, sigBytes []byte) (bls.PublicKey, bls.Signature, error) {
+	pubkey, err := bls.PublicKeyFromBytes(pubkeyBytes)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "invalid pubkey")
+	}
+
+	sig, err := bls.SignatureFromBytes(sigBytes)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "invalid signature")
+	}
+
+	return pubkey, sig, nil
+}
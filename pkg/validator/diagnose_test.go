@@ -0,0 +1,212 @@
+package validator
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/prysmaticlabs/prysm/v5/beacon-chain/core/signing"
+	"github.com/prysmaticlabs/prysm/v5/config/params"
+	"github.com/prysmaticlabs/prysm/v5/consensus-types/primitives"
+	"github.com/prysmaticlabs/prysm/v5/crypto/bls"
+	ethpb "github.com/prysmaticlabs/prysm/v5/proto/prysm/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeSignedExit signs an exit for validatorIndex with the given network's
+// fork version and writes it to dir using the generator's file naming
+func writeSignedExit(t *testing.T, dir string, key bls.SecretKey, cfg *params.BeaconChainConfig, forkVersion []byte, validatorIndex uint64) {
+	t.Helper()
+
+	exit := &ethpb.VoluntaryExit{
+		Epoch:          cfg.CapellaForkEpoch,
+		ValidatorIndex: primitives.ValidatorIndex(validatorIndex),
+	}
+
+	domain, err := signing.ComputeDomain(cfg.DomainVoluntaryExit, forkVersion, cfg.GenesisValidatorsRoot[:])
+	require.NoError(t, err)
+
+	root, err := signing.ComputeSigningRoot(exit, domain)
+	require.NoError(t, err)
+
+	signed := SignedVoluntaryExit{Signature: "0x" + hex.EncodeToString(key.Sign(root[:]).Marshal())}
+	signed.Message.Epoch = fmt.Sprintf("%d", exit.Epoch)
+	signed.Message.ValidatorIndex = fmt.Sprintf("%d", validatorIndex)
+
+	data, err := json.Marshal(signed)
+	require.NoError(t, err)
+
+	name := fmt.Sprintf("%d-0x%s.json", validatorIndex, hex.EncodeToString(key.PublicKey().Marshal()))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), data, 0o600))
+}
+
+func TestDiagnoseExits(t *testing.T) {
+	key, err := bls.RandKey()
+	require.NoError(t, err)
+
+	hoodi := params.HoodiConfig()
+
+	tests := []struct {
+		name            string
+		forkVersion     []byte
+		expectedNetwork string
+		problem         string
+	}{
+		{
+			name:            "capella on expected network",
+			forkVersion:     hoodi.CapellaForkVersion,
+			expectedNetwork: "hoodi",
+		},
+		{
+			name:        "no expected network",
+			forkVersion: hoodi.CapellaForkVersion,
+		},
+		{
+			name:            "wrong network",
+			forkVersion:     hoodi.CapellaForkVersion,
+			expectedNetwork: "mainnet",
+			problem:         "signed for network hoodi instead of mainnet",
+		},
+		{
+			name:            "current fork instead of capella",
+			forkVersion:     hoodi.ElectraForkVersion,
+			expectedNetwork: "hoodi",
+			problem:         "signed with the electra fork version 0x60000910 instead of the capella fork version",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeSignedExit(t, dir, key, hoodi, tt.forkVersion, 7)
+
+			diagnoses, err := DiagnoseExits(dir, tt.expectedNetwork, 1)
+			require.NoError(t, err)
+			require.Len(t, diagnoses, 1)
+
+			d := diagnoses[0]
+			require.NotNil(t, d.ValidatorIndex)
+			assert.Equal(t, uint64(7), *d.ValidatorIndex)
+			require.Len(t, d.Matches, 1)
+			assert.Equal(t, "hoodi", d.Matches[0].Network)
+			assert.Equal(t, tt.problem, d.Problem)
+		})
+	}
+}
+
+func TestDiagnoseExitsUnreadable(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "1-0xzz.json"), []byte(`{}`), 0o600))
+
+	diagnoses, err := DiagnoseExits(dir, "", 0)
+	require.NoError(t, err)
+	require.Len(t, diagnoses, 1)
+	assert.Contains(t, diagnoses[0].Problem, "failed to read exit")
+	assert.Equal(t, 1, LogDiagnoses(diagnoses))
+}
+
+func TestDiagnoseExitsParallel(t *testing.T) {
+	key, err := bls.RandKey()
+	require.NoError(t, err)
+
+	hoodi := params.HoodiConfig()
+
+	dir := t.TempDir()
+	for i := uint64(10); i < 30; i++ {
+		forkVersion := hoodi.CapellaForkVersion
+		if i%5 == 0 {
+			forkVersion = hoodi.ElectraForkVersion
+		}
+
+		writeSignedExit(t, dir, key, hoodi, forkVersion, i)
+	}
+
+	diagnoses, err := DiagnoseExits(dir, "hoodi", 4)
+	require.NoError(t, err)
+	require.Len(t, diagnoses, 20)
+
+	// In the order of the files, with only the electra ones flagged
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+
+	for i, d := range diagnoses {
+		assert.Equal(t, filepath.Join(dir, entries[i].Name()), d.File)
+		assert.Equal(t, *d.ValidatorIndex%5 == 0, d.Problem != "", d.File)
+	}
+
+	assert.Equal(t, 4, LogDiagnoses(diagnoses))
+}
+
+func TestExitDomains(t *testing.T) {
+	domains, err := exitDomains("hoodi")
+	require.NoError(t, err)
+
+	// The domain exits must be signed with is tried first
+	assert.Equal(t, SigningDomain{Network: "hoodi", Fork: "capella", ForkVersion: "0x40000910"}, domains[0].SigningDomain)
+	assert.Len(t, domains, len(networkNames())*len(networkForks(params.MainnetConfig())))
+
+	domains, err = exitDomains("")
+	require.NoError(t, err)
+	assert.Equal(t, "mainnet", domains[0].Network)
+	assert.Equal(t, "phase0", domains[0].Fork)
+}
+
+func TestDiagnoseDeposits(t *testing.T) {
+	key, err := bls.RandKey()
+	require.NoError(t, err)
+
+	cfg := params.MainnetConfig()
+	creds := make([]byte, 32)
+	creds[0] = 0x01
+
+	message := &ethpb.DepositMessage{
+		PublicKey:             key.PublicKey().Marshal(),
+		WithdrawalCredentials: creds,
+		Amount:                cfg.MinActivationBalance,
+	}
+
+	domain, err := signing.ComputeDomain(cfg.DomainDeposit, cfg.GenesisForkVersion, nil)
+	require.NoError(t, err)
+
+	root, err := signing.ComputeSigningRoot(message, domain)
+	require.NoError(t, err)
+
+	deposits := []*Deposit{{
+		PubKey:                hex.EncodeToString(message.PublicKey),
+		WithdrawalCredentials: hex.EncodeToString(creds),
+		Amount:                message.Amount,
+		Signature:             hex.EncodeToString(key.Sign(root[:]).Marshal()),
+		NetworkName:           "mainnet",
+		ForkVersion:           "00000000",
+	}}
+
+	data, err := json.Marshal(deposits)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "deposit_data.json")
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+
+	diagnoses, err := DiagnoseDeposits(path, "mainnet")
+	require.NoError(t, err)
+	require.Len(t, diagnoses, 1)
+	require.NotEmpty(t, diagnoses[0].Matches)
+	assert.Equal(t, SigningDomain{Network: "mainnet", Fork: "phase0", ForkVersion: "0x00000000"}, diagnoses[0].Matches[0])
+	assert.Empty(t, diagnoses[0].Problem)
+
+	diagnoses, err = DiagnoseDeposits(path, "hoodi")
+	require.NoError(t, err)
+	assert.Equal(t, "signed for network mainnet instead of hoodi", diagnoses[0].Problem)
+}
+
+func TestDomainProblem(t *testing.T) {
+	capella := SigningDomain{Network: "hoodi", Fork: "capella", ForkVersion: "0x40000910"}
+
+	assert.Equal(t, "signature does not verify for any known network and fork version", domainProblem(nil, "hoodi", "capella"))
+	assert.Empty(t, domainProblem([]SigningDomain{capella}, "hoodi", "capella"))
+	assert.Empty(t, domainProblem([]SigningDomain{capella}, "", "capella"))
+	assert.Equal(t, "signed for network hoodi instead of mainnet", domainProblem([]SigningDomain{capella}, "mainnet", "capella"))
+}
@@ -0,0 +1,192 @@
+package validator
+
+import (
+	"encoding/hex"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/prysm/v5/config/params"
+	"github.com/sirupsen/logrus"
+
+	"github.com/ethpandaops/validator-tools/pkg/beacon"
+)
+
+const (
+	// EphemeryNetwork is the network name under which the current Ephemery iteration is registered
+	EphemeryNetwork = "ephemery"
+
+	// DefaultEphemerySource publishes the config of the current Ephemery iteration
+	DefaultEphemerySource = "https://ephemery.dev/latest"
+
+	// ephemeryChainIDBase is the DEPOSIT_CHAIN_ID of iteration 0; each reset increments it
+	ephemeryChainIDBase = 39438000
+
+	// ephemeryGenesisForkVersion is the GENESIS_FORK_VERSION shared by every Ephemery iteration
+	ephemeryGenesisForkVersion = "1000101b"
+
+	ephemeryConfigFile = "config.yaml"
+	ephemeryRootFile   = "genesis_validators_root.txt"
+	ephemeryStateFile  = "genesis.ssz"
+)
+
+// LoadEphemeryConfig loads the current Ephemery iteration from source and
+// registers it as the ephemery network. Ephemery resets periodically, so its
+// genesis validators root can't be built in. source is a local directory or
+// base URL with config.yaml and either genesis_validators_root.txt or, for
+// local directories, genesis.ssz. URLs are fetched with the connection
+// options opts, which may be nil.
+func LoadEphemeryConfig(source string, opts *beacon.Options) (*params.BeaconChainConfig, error) {
+	if opts == nil {
+		opts = &beacon.Options{}
+	}
+
+	client, err := opts.HTTPClient()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to configure ephemery source connection")
+	}
+
+	data, err := readEphemeryFile(client, source, ephemeryConfigFile)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load ephemery config")
+	}
+
+	cfg, err := params.UnmarshalConfig(data, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse ephemery config")
+	}
+
+	cfg.ConfigName = EphemeryNetwork
+
+	var genesisValidatorsRoot, genesisStatePath string
+
+	root, err := readEphemeryFile(client, source, ephemeryRootFile)
+
+	switch {
+	case err == nil:
+		genesisValidatorsRoot = strings.TrimSpace(string(root))
+	case !isURL(source):
+		genesisStatePath = filepath.Join(source, ephemeryStateFile)
+	default:
+		return nil, errors.Wrap(err, "failed to load ephemery genesis validators root")
+	}
+
+	if err := registerNetwork(cfg, genesisValidatorsRoot, genesisStatePath); err != nil {
+		return nil, errors.Wrap(err, "failed to register ephemery network")
+	}
+
+	log.WithFields(logrus.Fields{
+		"iteration": ephemeryIteration(cfg),
+		"source":    source,
+	}).Info("Loaded current Ephemery iteration")
+
+	return cfg, nil
+}
+
+// EphemeryLoaded reports whether an Ephemery iteration has been loaded
+func EphemeryLoaded() bool {
+	_, ok := customNetworks[EphemeryNetwork]
+
+	return ok
+}
+
+// ephemeryIteration returns the Ephemery iteration of a config, derived from its deposit chain id
+func ephemeryIteration(cfg *params.BeaconChainConfig) uint64 {
+	if cfg.DepositChainID < ephemeryChainIDBase {
+		return 0
+	}
+
+	return cfg.DepositChainID - ephemeryChainIDBase
+}
+
+// isEphemeryConfig reports whether a beacon config was built for any Ephemery
+// iteration, loaded or not
+func isEphemeryConfig(config *BeaconConfig) bool {
+	return strings.EqualFold(strings.TrimPrefix(config.GenesisVersion, "0x"), ephemeryGenesisForkVersion)
+}
+
+// isEarlierEphemeryIteration reports whether a beacon config belongs to an
+// Ephemery iteration other than the loaded one: same genesis fork version, but
+// a different genesis validators root.
+func isEarlierEphemeryIteration(config *BeaconConfig) bool {
+	cfg, ok := customNetworks[EphemeryNetwork]
+	if !ok {
+		return false
+	}
+
+	if !strings.EqualFold(strings.TrimPrefix(config.GenesisVersion, "0x"), hex.EncodeToString(cfg.GenesisForkVersion)) {
+		return false
+	}
+
+	if strings.EqualFold(strings.TrimPrefix(config.GenesisValidatorsRoot, "0x"), hex.EncodeToString(cfg.GenesisValidatorsRoot[:])) {
+		return false
+	}
+
+	log.WithFields(logrus.Fields{
+		"current_iteration":               ephemeryIteration(cfg),
+		"current_genesis_validators_root": "0x" + hex.EncodeToString(cfg.GenesisValidatorsRoot[:]),
+		"signed_genesis_validators_root":  config.GenesisValidatorsRoot,
+	}).Warn("Exits were signed for an earlier Ephemery iteration and will not verify against the current one")
+
+	return true
+}
+
+// warnEphemeryIteration reports when an exit that failed to verify on Ephemery
+// was signed for another iteration: its signature is valid, but verifies for
+// none of the current iteration's fork versions nor any other known network.
+// It returns whether that is the case.
+func warnEphemeryIteration(vexit *VoluntaryExit) bool {
+	if _, _, err := parseSignature(vexit.Pubkey, vexit.PBExit.Signature); err != nil {
+		return false
+	}
+
+	diagnosis, err := DiagnoseExit(vexit, EphemeryNetwork)
+	if err != nil || len(diagnosis.Matches) > 0 {
+		return false
+	}
+
+	cfg := params.BeaconConfig()
+
+	log.WithFields(logrus.Fields{
+		"current_iteration":       ephemeryIteration(cfg),
+		"genesis_validators_root": "0x" + hex.EncodeToString(cfg.GenesisValidatorsRoot[:]),
+		"file":                    vexit.Path,
+	}).Warn("Exit was not signed for the current Ephemery iteration; exits signed before an Ephemery reset can't be used after it")
+
+	return true
+}
+
+func isURL(source string) bool {
+	return strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://")
+}
+
+// readEphemeryFile reads a file from a local directory or a base URL
+func readEphemeryFile(client *http.Client, source, name string) ([]byte, error) {
+	if !isURL(source) {
+		return os.ReadFile(filepath.Join(source, name))
+	}
+
+	url := strings.TrimSuffix(source, "/") + "/" + name
+
+	log.Infof("Fetching %s", url)
+
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to fetch URL")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("HTTP request for %s failed with status: %s", name, resp.Status)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read response body")
+	}
+
+	return body, nil
+}
@@ -0,0 +1,266 @@
+package validator
+
+import (
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/prysmaticlabs/prysm/v5/config/params"
+	"github.com/prysmaticlabs/prysm/v5/crypto/bls"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethpandaops/validator-tools/pkg/beacon"
+)
+
+const testEphemeryConfig = `PRESET_BASE: 'mainnet'
+CONFIG_NAME: 'testnet'
+MIN_GENESIS_TIME: 1700000000
+GENESIS_DELAY: 300
+GENESIS_FORK_VERSION: 0x1000101b
+ALTAIR_FORK_VERSION: 0x2000101b
+ALTAIR_FORK_EPOCH: 0
+BELLATRIX_FORK_VERSION: 0x3000101b
+BELLATRIX_FORK_EPOCH: 0
+CAPELLA_FORK_VERSION: 0x4000101b
+CAPELLA_FORK_EPOCH: 0
+DENEB_FORK_VERSION: 0x5000101b
+DENEB_FORK_EPOCH: 0
+ELECTRA_FORK_VERSION: 0x6000101b
+ELECTRA_FORK_EPOCH: 0
+FULU_FORK_VERSION: 0x7000101b
+FULU_FORK_EPOCH: 18446744073709551615
+DEPOSIT_CHAIN_ID: 39438150
+DEPOSIT_NETWORK_ID: 39438150
+`
+
+func cleanupEphemery(t *testing.T) {
+	t.Helper()
+
+	t.Cleanup(func() {
+		delete(customNetworks, EphemeryNetwork)
+		delete(customGenesisTimes, EphemeryNetwork)
+	})
+}
+
+func TestLoadEphemeryConfigFromDirectory(t *testing.T) {
+	cleanupEphemery(t)
+
+	dir := t.TempDir()
+	root := "0x" + strings.Repeat("cd", 32)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ephemeryConfigFile), []byte(testEphemeryConfig), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ephemeryRootFile), []byte(root+"\n"), 0o600))
+
+	cfg, err := LoadEphemeryConfig(dir, nil)
+	require.NoError(t, err)
+	assert.Equal(t, EphemeryNetwork, cfg.ConfigName)
+	assert.Equal(t, uint64(150), ephemeryIteration(cfg))
+
+	config, err := NewBeaconConfigFromNetwork(EphemeryNetwork)
+	require.NoError(t, err)
+	assert.Equal(t, root, config.GenesisValidatorsRoot)
+	assert.Equal(t, "0x4000101b", config.ExitForkVersion)
+}
+
+func TestLoadEphemeryConfigFromURL(t *testing.T) {
+	cleanupEphemery(t)
+
+	root := "0x" + strings.Repeat("ef", 32)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Api-Key") != "key" {
+			w.WriteHeader(http.StatusUnauthorized)
+
+			return
+		}
+
+		switch r.URL.Path {
+		case "/latest/" + ephemeryConfigFile:
+			_, _ = w.Write([]byte(testEphemeryConfig))
+		case "/latest/" + ephemeryRootFile:
+			_, _ = w.Write([]byte(root))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	// Not fetched on demand
+	_, err := NewBeaconConfigFromNetwork(EphemeryNetwork)
+	require.Error(t, err)
+
+	// Fetched with the beacon connection options
+	_, err = LoadEphemeryConfig(server.URL+"/latest/", nil)
+	require.Error(t, err)
+
+	opts := &beacon.Options{Headers: map[string]string{"X-Api-Key": "key"}}
+
+	_, err = LoadEphemeryConfig(server.URL+"/latest/", opts)
+	require.NoError(t, err)
+	assert.True(t, EphemeryLoaded())
+
+	config, err := NewBeaconConfigFromNetwork(EphemeryNetwork)
+	require.NoError(t, err)
+	assert.Equal(t, root, config.GenesisValidatorsRoot)
+
+	_, err = LoadEphemeryConfig(server.URL+"/missing", opts)
+	assert.Error(t, err)
+}
+
+func TestLoadEphemeryConfigMissingRoot(t *testing.T) {
+	cleanupEphemery(t)
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ephemeryConfigFile), []byte(testEphemeryConfig), 0o600))
+
+	// Neither genesis_validators_root.txt nor genesis.ssz
+	_, err := LoadEphemeryConfig(dir, nil)
+	assert.Error(t, err)
+}
+
+func TestIsEarlierEphemeryIteration(t *testing.T) {
+	cleanupEphemery(t)
+
+	dir := t.TempDir()
+	root := "0x" + strings.Repeat("cd", 32)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ephemeryConfigFile), []byte(testEphemeryConfig), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ephemeryRootFile), []byte(root), 0o600))
+
+	earlier := &BeaconConfig{
+		GenesisValidatorsRoot: "0x" + strings.Repeat("01", 32),
+		GenesisVersion:        "0x1000101b",
+	}
+
+	// Not loaded yet
+	assert.False(t, isEarlierEphemeryIteration(earlier))
+
+	_, err := LoadEphemeryConfig(dir, nil)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		config   *BeaconConfig
+		expected bool
+	}{
+		{
+			name:     "earlier iteration",
+			config:   earlier,
+			expected: true,
+		},
+		{
+			name: "current iteration",
+			config: &BeaconConfig{
+				GenesisValidatorsRoot: root,
+				GenesisVersion:        "0x1000101b",
+			},
+			expected: false,
+		},
+		{
+			name: "other network",
+			config: &BeaconConfig{
+				GenesisValidatorsRoot: "0x" + strings.Repeat("01", 32),
+				GenesisVersion:        "0x00000000",
+			},
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, isEarlierEphemeryIteration(tt.config))
+		})
+	}
+
+	manifest := &Manifest{BeaconConfig: earlier, Pubkeys: []string{"0xabc"}}
+
+	network, err := manifest.Network()
+	require.NoError(t, err)
+	assert.Equal(t, EphemeryNetwork, network)
+}
+
+func TestManifestEphemeryNetwork(t *testing.T) {
+	cleanupEphemery(t)
+
+	dir := t.TempDir()
+	root := "0x" + strings.Repeat("cd", 32)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ephemeryConfigFile), []byte(testEphemeryConfig), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ephemeryRootFile), []byte(root), 0o600))
+
+	current := &Manifest{
+		BeaconConfig: &BeaconConfig{GenesisValidatorsRoot: root, GenesisVersion: "0x1000101B"},
+		Pubkeys:      []string{"0xabc"},
+	}
+	earlier := &Manifest{
+		BeaconConfig: &BeaconConfig{GenesisValidatorsRoot: "0x" + strings.Repeat("01", 32), GenesisVersion: "0x1000101b"},
+		Pubkeys:      []string{"0xabc"},
+	}
+
+	hoodi, err := NewBeaconConfigFromNetwork("hoodi")
+	require.NoError(t, err)
+
+	assert.True(t, current.Ephemery())
+	assert.True(t, earlier.Ephemery())
+	assert.False(t, (&Manifest{BeaconConfig: hoodi}).Ephemery())
+
+	// With only a manifest, Ephemery has to be loaded first
+	_, err = current.Network()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "use --network ephemery")
+
+	_, err = LoadEphemeryConfig(dir, nil)
+	require.NoError(t, err)
+
+	for _, manifest := range []*Manifest{current, earlier} {
+		network, err := manifest.Network()
+		require.NoError(t, err)
+		assert.Equal(t, EphemeryNetwork, network)
+	}
+
+	assert.False(t, current.EarlierEphemeryIteration())
+	assert.True(t, earlier.EarlierEphemeryIteration())
+}
+
+func TestWarnEphemeryIteration(t *testing.T) {
+	cleanupEphemery(t)
+
+	defer params.OverrideBeaconConfig(params.MainnetConfig())
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ephemeryConfigFile), []byte(testEphemeryConfig), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ephemeryRootFile), []byte("0x"+strings.Repeat("cd", 32)), 0o600))
+
+	current, err := LoadEphemeryConfig(dir, nil)
+	require.NoError(t, err)
+	require.NoError(t, setNetwork(EphemeryNetwork))
+
+	key, err := bls.RandKey()
+	require.NoError(t, err)
+
+	earlier := current.Copy()
+	earlier.GenesisValidatorsRoot = [32]byte{0x01}
+
+	hoodi := params.HoodiConfig()
+
+	exitsDir := t.TempDir()
+	writeSignedExit(t, exitsDir, key, earlier, earlier.CapellaForkVersion, 1)
+	writeSignedExit(t, exitsDir, key, current, current.ElectraForkVersion, 2)
+	writeSignedExit(t, exitsDir, key, hoodi, hoodi.CapellaForkVersion, 3)
+
+	pubkey := "0x" + hex.EncodeToString(key.PublicKey().Marshal())
+
+	for index, expected := range map[int]bool{1: true, 2: false, 3: false} {
+		vexit, err := readExitFile(filepath.Join(exitsDir, fmt.Sprintf("%d-%s.json", index, pubkey)))
+		require.NoError(t, err)
+
+		// Only the exit of the earlier iteration verifies for no known domain
+		assert.Equal(t, expected, warnEphemeryIteration(vexit), "validator index %d", index)
+	}
+}
@@ -1,12 +1,13 @@
 package validator
 
 import (
-	"os"
 	"os/exec"
 	"strings"
 
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
+
+	"github.com/ethpandaops/validator-tools/pkg/atomicfile"
 )
 
 type commander interface {
@@ -57,7 +58,7 @@ func (g *VoluntaryExitGenerator) runEthdoCommand(keystorePath, outFile, workDir
 		return errors.Wrapf(err, "ethdo command failed: %s", string(output))
 	}
 
-	if err := os.WriteFile(outFile, output, 0o600); err != nil {
+	if err := atomicfile.WriteFile(outFile, output, 0o600); err != nil {
 		log.Errorf("Failed to write output file: %v", err)
 
 		return errors.Wrapf(err, "failed to write output file: %s", outFile)
@@ -67,3 +68,13 @@ func (g *VoluntaryExitGenerator) runEthdoCommand(keystorePath, outFile, workDir
 
 	return nil
 }
+
+// EthdoVersion returns the version reported by the installed ethdo binary
+func EthdoVersion() (string, error) {
+	output, err := execCommand("ethdo", "version").CombinedOutput()
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to get ethdo version: %s", string(output))
+	}
+
+	return strings.TrimSpace(string(output)), nil
+}
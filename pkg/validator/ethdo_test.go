@@ -175,3 +175,33 @@ func TestEthdoCommandRedaction(t *testing.T) {
 	assert.NotContains(t, logOutput, "super-secret-password")
 	assert.Contains(t, logOutput, "--passphrase=********")
 }
+
+func TestEthdoVersion(t *testing.T) {
+	origExecCommand := execCommand
+
+	defer func() { execCommand = origExecCommand }()
+
+	execCommand = func(name string, args ...string) commander {
+		assert.Equal(t, "ethdo", name)
+		assert.Equal(t, []string{"version"}, args)
+
+		return &mockCmd{
+			t:      t,
+			output: []byte("1.37.0\n"),
+		}
+	}
+
+	version, err := EthdoVersion()
+	require.NoError(t, err)
+	assert.Equal(t, "1.37.0", version)
+
+	execCommand = func(name string, args ...string) commander {
+		return &mockCmd{
+			t:          t,
+			shouldFail: true,
+		}
+	}
+
+	_, err = EthdoVersion()
+	assert.Error(t, err)
+}
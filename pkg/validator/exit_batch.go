@@ -0,0 +1,168 @@
+package validator
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/prysmaticlabs/prysm/v5/beacon-chain/core/signing"
+	"github.com/prysmaticlabs/prysm/v5/crypto/bls"
+)
+
+// DefaultVerifyBatchSize is the number of exit signatures verified at once
+const DefaultVerifyBatchSize = 128
+
+// signatureFailure is an exit whose signature did not verify
+type signatureFailure struct {
+	exit *VoluntaryExit
+	err  error
+}
+
+// verifyExitSignatures verifies the signatures of exits on numWorkers
+// goroutines, batchSize at a time. Each batch is checked with a single
+// random linear combination of its signatures; if that fails, its exits are
+// checked one by one to find the bad ones. Unless collectAll is set, no new
+// batches are started once a bad signature is found. Failures are returned in
+// the order of exits.
+func verifyExitSignatures(exits []*VoluntaryExit, domain []byte, numWorkers, batchSize int, collectAll bool) []signatureFailure {
+	if batchSize < 1 {
+		batchSize = DefaultVerifyBatchSize
+	}
+
+	results := make([][]signatureFailure, (len(exits)+batchSize-1)/batchSize)
+
+	var (
+		verified uint64
+		failed   atomic.Bool
+	)
+
+	stopProgress := make(chan struct{})
+	go reportVerifyProgress(&verified, len(exits), stopProgress)
+
+	stop := func() bool { return failed.Load() && !collectAll }
+
+	runParallel(len(results), numWorkers, stop, func(batch int) {
+		end := min((batch+1)*batchSize, len(exits))
+
+		results[batch] = verifyExitBatch(exits[batch*batchSize:end], domain)
+		if len(results[batch]) > 0 {
+			failed.Store(true)
+		}
+
+		atomic.AddUint64(&verified, uint64(end-batch*batchSize))
+	})
+
+	close(stopProgress)
+
+	var failures []signatureFailure
+	for _, result := range results {
+		failures = append(failures, result...)
+	}
+
+	return failures
+}
+
+// runParallel calls fn with 0 to count-1 on numWorkers goroutines, or one per
+// CPU when numWorkers is below 1. No new calls are started once stop, which
+// may be nil, returns true.
+func runParallel(count, numWorkers int, stop func() bool, fn func(i int)) {
+	if numWorkers < 1 {
+		numWorkers = runtime.NumCPU()
+	}
+
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+
+	for w := 0; w < numWorkers; w++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for i := range jobs {
+				fn(i)
+			}
+		}()
+	}
+
+	for i := 0; i < count; i++ {
+		if stop != nil && stop() {
+			break
+		}
+
+		jobs <- i
+	}
+
+	close(jobs)
+	wg.Wait()
+}
+
+// verifyExitBatch verifies a batch of exit signatures, falling back to
+// checking them one by one if the batch as a whole does not verify
+func verifyExitBatch(batch []*VoluntaryExit, domain []byte) []signatureFailure {
+	if batchSignaturesValid(batch, domain) {
+		return nil
+	}
+
+	var failures []signatureFailure
+
+	for _, exit := range batch {
+		if err := verifyExitSignature(exit, domain); err != nil {
+			failures = append(failures, signatureFailure{exit: exit, err: err})
+		}
+	}
+
+	return failures
+}
+
+// batchSignaturesValid reports whether all signatures of a batch verify. It
+// is false as well if any pubkey or signing root is invalid, leaving it to the
+// individual checks to report why.
+func batchSignaturesValid(batch []*VoluntaryExit, domain []byte) bool {
+	sigs := make([][]byte, 0, len(batch))
+	msgs := make([][32]byte, 0, len(batch))
+	pubkeys := make([]bls.PublicKey, 0, len(batch))
+
+	for _, exit := range batch {
+		pubkey, err := bls.PublicKeyFromBytes(exit.Pubkey)
+		if err != nil {
+			return false
+		}
+
+		root, err := signing.ComputeSigningRoot(exit.PBExit.Exit, domain)
+		if err != nil {
+			return false
+		}
+
+		sigs = append(sigs, exit.PBExit.Signature)
+		msgs = append(msgs, root)
+		pubkeys = append(pubkeys, pubkey)
+	}
+
+	valid, err := bls.VerifyMultipleSignatures(sigs, msgs, pubkeys)
+
+	return err == nil && valid
+}
+
+// reportVerifyProgress logs the number of verified signatures every 10 seconds
+func reportVerifyProgress(verified *uint64, total int, stop chan struct{}) {
+	start := time.Now()
+
+	ticker := time.NewTicker(10 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			done := atomic.LoadUint64(verified)
+			log.Infof("Progress: %d/%d exit signatures verified (%.1f%%) at %.1f signatures/s",
+				done, total,
+				float64(done)/float64(total)*100,
+				float64(done)/time.Since(start).Seconds())
+		case <-stop:
+			return
+		}
+	}
+}
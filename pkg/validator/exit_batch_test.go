@@ -0,0 +1,136 @@
+package validator
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/prysmaticlabs/prysm/v5/beacon-chain/core/signing"
+	"github.com/prysmaticlabs/prysm/v5/config/params"
+	"github.com/prysmaticlabs/prysm/v5/consensus-types/primitives"
+	"github.com/prysmaticlabs/prysm/v5/crypto/bls"
+	ethpb "github.com/prysmaticlabs/prysm/v5/proto/prysm/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testSignedExits signs count exits, starting at index 0, with key
+func testSignedExits(t *testing.T, key bls.SecretKey, domain []byte, count int) []*VoluntaryExit {
+	t.Helper()
+
+	exits := make([]*VoluntaryExit, 0, count)
+
+	for i := 0; i < count; i++ {
+		exit := &ethpb.VoluntaryExit{Epoch: 1, ValidatorIndex: primitives.ValidatorIndex(i)}
+
+		root, err := signing.ComputeSigningRoot(exit, domain)
+		require.NoError(t, err)
+
+		exits = append(exits, &VoluntaryExit{
+			PBExit: &ethpb.SignedVoluntaryExit{Exit: exit, Signature: key.Sign(root[:]).Marshal()},
+			Pubkey: key.PublicKey().Marshal(),
+			Path:   fmt.Sprintf("%d.json", i),
+		})
+	}
+
+	return exits
+}
+
+func TestVerifyExitSignatures(t *testing.T) {
+	hoodi := params.HoodiConfig()
+
+	domain, err := signing.ComputeDomain(hoodi.DomainVoluntaryExit, hoodi.CapellaForkVersion, hoodi.GenesisValidatorsRoot[:])
+	require.NoError(t, err)
+
+	key, err := bls.RandKey()
+	require.NoError(t, err)
+
+	otherKey, err := bls.RandKey()
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		numWorkers int
+		batchSize  int
+		collectAll bool
+		corrupt    func(exits []*VoluntaryExit)
+		expected   []string
+	}{
+		{
+			name:       "all valid",
+			numWorkers: 4,
+			batchSize:  8,
+		},
+		{
+			name:       "default workers and batch size",
+			numWorkers: 0,
+			batchSize:  0,
+		},
+		{
+			name:       "wrong message",
+			numWorkers: 4,
+			batchSize:  8,
+			corrupt: func(exits []*VoluntaryExit) {
+				exits[13].PBExit.Exit.Epoch = 2
+			},
+			expected: []string{"13.json"},
+		},
+		{
+			name:       "several bad signatures in one batch",
+			numWorkers: 1,
+			batchSize:  50,
+			corrupt: func(exits []*VoluntaryExit) {
+				exits[3].PBExit.Signature = exits[4].PBExit.Signature
+				exits[40].Pubkey = otherKey.PublicKey().Marshal()
+			},
+			expected: []string{"3.json", "40.json"},
+		},
+		{
+			name:       "undecodable signature",
+			numWorkers: 2,
+			batchSize:  1,
+			corrupt: func(exits []*VoluntaryExit) {
+				exits[49].PBExit.Signature = []byte{0x01}
+			},
+			expected: []string{"49.json"},
+		},
+		{
+			name:       "stops after the first failing batch",
+			numWorkers: 1,
+			batchSize:  10,
+			corrupt: func(exits []*VoluntaryExit) {
+				exits[3].PBExit.Exit.Epoch = 2
+				exits[45].PBExit.Exit.Epoch = 2
+			},
+			expected: []string{"3.json"},
+		},
+		{
+			name:       "collect all",
+			numWorkers: 1,
+			batchSize:  10,
+			collectAll: true,
+			corrupt: func(exits []*VoluntaryExit) {
+				exits[3].PBExit.Exit.Epoch = 2
+				exits[45].PBExit.Exit.Epoch = 2
+			},
+			expected: []string{"3.json", "45.json"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exits := testSignedExits(t, key, domain, 50)
+			if tt.corrupt != nil {
+				tt.corrupt(exits)
+			}
+
+			var files []string
+			for _, failure := range verifyExitSignatures(exits, domain, tt.numWorkers, tt.batchSize, tt.collectAll) {
+				require.Error(t, failure.err)
+
+				files = append(files, failure.exit.Path)
+			}
+
+			assert.Equal(t, tt.expected, files)
+		})
+	}
+}
@@ -0,0 +1,282 @@
+package validator
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/prysm/v5/consensus-types/primitives"
+	"github.com/sirupsen/logrus"
+)
+
+// DuplicatePolicy controls what happens when duplicate or conflicting exits are found
+type DuplicatePolicy string
+
+const (
+	DuplicateFail     DuplicatePolicy = "fail"
+	DuplicateTolerate DuplicatePolicy = "tolerate"
+)
+
+// ParseDuplicatePolicy validates a duplicate policy
+func ParseDuplicatePolicy(policy string) (DuplicatePolicy, error) {
+	switch DuplicatePolicy(policy) {
+	case DuplicateFail, DuplicateTolerate:
+		return DuplicatePolicy(policy), nil
+	default:
+		return "", fmt.Errorf("unknown duplicate policy: %s", policy)
+	}
+}
+
+// DuplicateKind describes how the exits of a duplicate relate to each other
+type DuplicateKind string
+
+const (
+	// DuplicateExact is several files with the same pubkey, validator index,
+	// epoch and signature
+	DuplicateExact DuplicateKind = "exact"
+	// DuplicateConflicting is several files with the same pubkey and
+	// validator index but a different epoch or signature
+	DuplicateConflicting DuplicateKind = "conflicting"
+	// DuplicateCrossPubkey is a validator index signed by one pubkey but
+	// filed under more than one. Every pubkey signs exits for the same
+	// indices, so each pubkey having its own exit for an index is expected;
+	// the same signature under another pubkey is a copied file.
+	DuplicateCrossPubkey DuplicateKind = "cross_pubkey"
+)
+
+// Duplicate is a group of exit files for the same validator index that
+// should have been a single file
+type Duplicate struct {
+	Kind           DuplicateKind `json:"kind"`
+	ValidatorIndex uint64        `json:"validator_index"`
+	Pubkeys        []string      `json:"pubkeys"`
+	Files          []string      `json:"files"`
+
+	exits []*VoluntaryExit
+}
+
+// String describes the duplicate
+func (d Duplicate) String() string {
+	switch d.Kind {
+	case DuplicateExact:
+		return fmt.Sprintf("exact duplicate exits for validator index %d of pubkey %s: %s",
+			d.ValidatorIndex, d.Pubkeys[0], strings.Join(d.Files, ", "))
+	case DuplicateConflicting:
+		return fmt.Sprintf("conflicting exits for validator index %d of pubkey %s: %s",
+			d.ValidatorIndex, d.Pubkeys[0], strings.Join(d.Files, ", "))
+	default:
+		return fmt.Sprintf("the same signed exit for validator index %d is filed under pubkeys %s: %s",
+			d.ValidatorIndex, strings.Join(d.Pubkeys, ", "), strings.Join(d.Files, ", "))
+	}
+}
+
+// newDuplicate returns the duplicate of exits of the same pubkey and index
+func newDuplicate(exits []*VoluntaryExit) Duplicate {
+	kind := DuplicateExact
+
+	for _, exit := range exits[1:] {
+		if exit.PBExit.Exit.Epoch != exits[0].PBExit.Exit.Epoch || !bytes.Equal(exit.PBExit.Signature, exits[0].PBExit.Signature) {
+			kind = DuplicateConflicting
+		}
+	}
+
+	duplicate := Duplicate{
+		Kind:           kind,
+		ValidatorIndex: uint64(exits[0].PBExit.Exit.ValidatorIndex),
+		Pubkeys:        []string{hex.EncodeToString(exits[0].Pubkey)},
+		exits:          exits,
+	}
+
+	for _, exit := range exits {
+		duplicate.Files = append(duplicate.Files, exit.Path)
+	}
+
+	return duplicate
+}
+
+// FindDuplicates returns the exits that were loaded more than once: several
+// files for the same pubkey and validator index, and validator indices whose
+// signed exit is filed under more than one pubkey. The first file of a
+// duplicate is the one kept; for an index filed under more than one pubkey
+// that is the file whose signature verifies for its pubkey, if any.
+func (e *VoluntaryExits) FindDuplicates() []Duplicate {
+	var duplicates []Duplicate
+
+	byIndex := make(map[primitives.ValidatorIndex][]*VoluntaryExit)
+
+	for _, pubkey := range sortedKeys(e.ExitsByPubkey) {
+		exits := e.ExitsByPubkey[pubkey].all()
+
+		sort.SliceStable(exits, func(i, j int) bool {
+			return exits[i].PBExit.Exit.ValidatorIndex < exits[j].PBExit.Exit.ValidatorIndex
+		})
+
+		for start := 0; start < len(exits); {
+			end := start + 1
+			for end < len(exits) && exits[end].PBExit.Exit.ValidatorIndex == exits[start].PBExit.Exit.ValidatorIndex {
+				end++
+			}
+
+			if end-start > 1 {
+				duplicates = append(duplicates, newDuplicate(exits[start:end]))
+			}
+
+			for i, exit := range exits[start:end] {
+				// An exact duplicate within a pubkey is only counted once
+				if i > 0 && bytes.Equal(exit.PBExit.Signature, exits[start].PBExit.Signature) {
+					continue
+				}
+
+				index := exit.PBExit.Exit.ValidatorIndex
+				byIndex[index] = append(byIndex[index], exit)
+			}
+
+			start = end
+		}
+	}
+
+	indices := make([]primitives.ValidatorIndex, 0, len(byIndex))
+	for index := range byIndex {
+		indices = append(indices, index)
+	}
+
+	sort.Slice(indices, func(i, j int) bool { return indices[i] < indices[j] })
+
+	for _, index := range indices {
+		duplicates = append(duplicates, crossPubkeyDuplicates(index, byIndex[index])...)
+	}
+
+	return duplicates
+}
+
+// crossPubkeyDuplicates returns the signed exits for a validator index that
+// are filed under more than one pubkey
+func crossPubkeyDuplicates(index primitives.ValidatorIndex, exits []*VoluntaryExit) []Duplicate {
+	bySignature := make(map[string][]*VoluntaryExit)
+	for _, exit := range exits {
+		signature := hex.EncodeToString(exit.PBExit.Signature)
+		bySignature[signature] = append(bySignature[signature], exit)
+	}
+
+	var duplicates []Duplicate
+
+	for _, signature := range sortedKeys(bySignature) {
+		exits := bySignature[signature]
+		if len(exits) < 2 || bytes.Equal(exits[0].Pubkey, exits[1].Pubkey) {
+			continue
+		}
+
+		exits = signerFirst(exits)
+
+		duplicate := Duplicate{
+			Kind:           DuplicateCrossPubkey,
+			ValidatorIndex: uint64(index),
+			exits:          exits,
+		}
+
+		for _, exit := range exits {
+			duplicate.Pubkeys = append(duplicate.Pubkeys, hex.EncodeToString(exit.Pubkey))
+			duplicate.Files = append(duplicate.Files, exit.Path)
+		}
+
+		duplicates = append(duplicates, duplicate)
+	}
+
+	return duplicates
+}
+
+// signerFirst moves the exit whose signature verifies for its own pubkey to
+// the front, so that the copies filed under other pubkeys follow it
+func signerFirst(exits []*VoluntaryExit) []*VoluntaryExit {
+	domain, err := exitDomain()
+	if err != nil {
+		return exits
+	}
+
+	for i, exit := range exits {
+		if verifyExitSignature(exit, domain) == nil {
+			return append(append([]*VoluntaryExit{exit}, exits[:i]...), exits[i+1:]...)
+		}
+	}
+
+	return exits
+}
+
+// ValidateDuplicates reports duplicate and conflicting exits. With
+// DuplicateFail they are an error, or findings when collecting. With
+// DuplicateTolerate they are logged. Unless an error is returned, only the
+// first file of each pubkey and validator index is kept for the count and
+// index checks, so the others are not reported again; their file names and
+// signatures are still checked. Files filed under another pubkey are kept,
+// as they are that pubkey's only exit for the index.
+func (e *VoluntaryExits) ValidateDuplicates(policy DuplicatePolicy) error {
+	duplicates := e.FindDuplicates()
+
+	for _, duplicate := range duplicates {
+		entry := log.WithFields(logrus.Fields{
+			"kind":            duplicate.Kind,
+			"validator_index": duplicate.ValidatorIndex,
+			"files":           strings.Join(duplicate.Files, ","),
+		})
+
+		if policy == DuplicateTolerate {
+			entry.Warn("Tolerating duplicate exits")
+
+			continue
+		}
+
+		entry.Error("Duplicate exits found")
+
+		if e.Findings == nil {
+			return errors.New(duplicate.String())
+		}
+
+		for _, exit := range duplicate.exits[1:] {
+			e.Findings.add(exitFinding(FindingDuplicate, exit, duplicate.String()))
+		}
+	}
+
+	e.dropDuplicates(duplicates)
+
+	return nil
+}
+
+// dropDuplicates keeps the first file of each pubkey and validator index in
+// Exits and moves the others to Duplicates
+func (e *VoluntaryExits) dropDuplicates(duplicates []Duplicate) {
+	dropped := make(map[*VoluntaryExit]bool)
+
+	for _, duplicate := range duplicates {
+		if duplicate.Kind == DuplicateCrossPubkey {
+			continue
+		}
+
+		for _, exit := range duplicate.exits[1:] {
+			dropped[exit] = true
+		}
+	}
+
+	for _, validatorExits := range e.ExitsByPubkey {
+		exits := validatorExits.all()
+
+		sort.SliceStable(exits, func(i, j int) bool {
+			return exits[i].PBExit.Exit.ValidatorIndex < exits[j].PBExit.Exit.ValidatorIndex
+		})
+
+		validatorExits.Exits = validatorExits.Exits[:0]
+		validatorExits.Duplicates = nil
+
+		for _, exit := range exits {
+			if dropped[exit] {
+				validatorExits.Duplicates = append(validatorExits.Duplicates, exit)
+
+				continue
+			}
+
+			validatorExits.Exits = append(validatorExits.Exits, exit)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package validator
+
+import (
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/prysmaticlabs/prysm/v5/config/params"
+	"github.com/prysmaticlabs/prysm/v5/crypto/bls"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDuplicatePolicy(t *testing.T) {
+	policy, err := ParseDuplicatePolicy("tolerate")
+	require.NoError(t, err)
+	assert.Equal(t, DuplicateTolerate, policy)
+
+	_, err = ParseDuplicatePolicy("ignore")
+	require.Error(t, err)
+}
+
+func TestValidateDuplicates(t *testing.T) {
+	defer params.OverrideBeaconConfig(params.MainnetConfig())
+
+	hoodi := params.HoodiConfig()
+
+	key, err := bls.RandKey()
+	require.NoError(t, err)
+
+	otherKey, err := bls.RandKey()
+	require.NoError(t, err)
+
+	pubkey := hex.EncodeToString(key.PublicKey().Marshal())
+	otherPubkey := hex.EncodeToString(otherKey.PublicKey().Marshal())
+
+	// copyExit copies the exit file src in dir to dst, optionally changing its content
+	copyExit := func(t *testing.T, dir, src, dst string, replace ...string) {
+		t.Helper()
+
+		data, err := os.ReadFile(filepath.Join(dir, src))
+		require.NoError(t, err)
+
+		require.NoError(t, os.WriteFile(filepath.Join(dir, dst), []byte(strings.NewReplacer(replace...).Replace(string(data))), 0o600))
+	}
+
+	tests := []struct {
+		name          string
+		setup         func(t *testing.T, dir string)
+		expectedKinds []DuplicateKind
+		// expectedFindings are the findings of every check when collecting
+		expectedFindings map[FindingKind]int
+		// verifyError is whether Verify fails once the duplicates are tolerated
+		verifyError bool
+	}{
+		{
+			name: "no duplicates",
+			setup: func(t *testing.T, dir string) {
+				t.Helper()
+			},
+			expectedFindings: map[FindingKind]int{},
+		},
+		{
+			name: "exact duplicate with another name variant",
+			setup: func(t *testing.T, dir string) {
+				t.Helper()
+				copyExit(t, dir, "1-0x"+pubkey+".json", "1-"+pubkey+".json")
+				copyExit(t, dir, "1-0x"+pubkey+".json", "exit-"+pubkey+".json")
+			},
+			expectedKinds:    []DuplicateKind{DuplicateExact},
+			expectedFindings: map[FindingKind]int{FindingDuplicate: 2},
+		},
+		{
+			name: "conflicting duplicate",
+			setup: func(t *testing.T, dir string) {
+				t.Helper()
+				copyExit(t, dir, "1-0x"+pubkey+".json", "exit-"+pubkey+".json",
+					`"epoch":"`, `"epoch":"1`)
+			},
+			expectedKinds:    []DuplicateKind{DuplicateConflicting},
+			expectedFindings: map[FindingKind]int{FindingDuplicate: 1, FindingSignature: 1},
+			verifyError:      true,
+		},
+		{
+			name: "same signed exit under another pubkey",
+			setup: func(t *testing.T, dir string) {
+				t.Helper()
+				require.NoError(t, os.Remove(filepath.Join(dir, "1-0x"+otherPubkey+".json")))
+				copyExit(t, dir, "1-0x"+pubkey+".json", "1-0x"+otherPubkey+".json")
+			},
+			expectedKinds: []DuplicateKind{DuplicateCrossPubkey},
+			// The copy is kept, so otherPubkey still has an exit for
+			// every index, but its signature does not verify
+			expectedFindings: map[FindingKind]int{FindingDuplicate: 1, FindingSignature: 1},
+			verifyError:      true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for _, k := range []bls.SecretKey{key, otherKey} {
+				writeSignedExit(t, dir, k, hoodi, hoodi.CapellaForkVersion, 0)
+				writeSignedExit(t, dir, k, hoodi, hoodi.CapellaForkVersion, 1)
+			}
+
+			tt.setup(t, dir)
+
+			load := func(collect bool) *VoluntaryExits {
+				load := NewVoluntaryExits
+				if collect {
+					load = CollectVoluntaryExits
+				}
+
+				exits, err := load(dir, "hoodi", "0x"+hex.EncodeToString(make([]byte, 32)), []string{"0x" + pubkey, "0x" + otherPubkey})
+				require.NoError(t, err)
+
+				return exits
+			}
+
+			var kinds []DuplicateKind
+			for _, duplicate := range load(false).FindDuplicates() {
+				kinds = append(kinds, duplicate.Kind)
+			}
+
+			assert.Equal(t, tt.expectedKinds, kinds)
+
+			// Failing on duplicates
+			err := load(false).ValidateDuplicates(DuplicateFail)
+			if len(tt.expectedKinds) == 0 {
+				require.NoError(t, err)
+			} else {
+				require.Error(t, err)
+			}
+
+			// Collecting duplicates as findings, without reporting them again
+			// in the later checks
+			exits := load(true)
+			require.NoError(t, exits.ValidateDuplicates(DuplicateFail))
+			require.NoError(t, exits.ValidateCount(2))
+			require.NoError(t, exits.ValidateFileNames())
+			require.NoError(t, exits.ValidateIndices())
+
+			_, err = exits.Verify()
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedFindings, exits.Findings.Report().Summary)
+
+			// Tolerating duplicates keeps one exit per index for the count, but
+			// still verifies the signatures of all of them
+			exits = load(false)
+			require.NoError(t, exits.ValidateDuplicates(DuplicateTolerate))
+			require.NoError(t, exits.ValidateFileNames())
+
+			require.NoError(t, exits.ValidateCount(2))
+			require.NoError(t, exits.ValidateRange(0, 2))
+			require.NoError(t, exits.ValidateIndices())
+
+			_, err = exits.Verify()
+			if tt.verifyError {
+				require.Error(t, err)
+
+				return
+			}
+
+			require.NoError(t, err)
+		})
+	}
+}
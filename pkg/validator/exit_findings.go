@@ -0,0 +1,199 @@
+package validator
+
+import (
+	"encoding/hex"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// FindingKind is the check a finding was reported by
+type FindingKind string
+
+const (
+	// FindingParse is a file that could not be read or parsed
+	FindingParse FindingKind = "parse"
+	// FindingPubkey is an unexpected or missing pubkey
+	FindingPubkey FindingKind = "pubkey"
+	// FindingCount is a gap in a pubkey's indices or an unexpected number of exits
+	FindingCount FindingKind = "count"
+	// FindingIndices is a pubkey whose index range differs from the others
+	FindingIndices FindingKind = "indices"
+	// FindingFileName is a file whose name is invalid or does not match its message
+	FindingFileName FindingKind = "file_name"
+	// FindingDuplicate is a duplicate or conflicting exit
+	FindingDuplicate FindingKind = "duplicate"
+	// FindingSignature is an exit whose signature does not verify
+	FindingSignature FindingKind = "signature"
+	// FindingStructure is an exit the chain would reject
+	FindingStructure FindingKind = "structure"
+	// FindingChecksum is a file that does not match the checksum manifest
+	FindingChecksum FindingKind = "checksum"
+)
+
+// Finding is a problem found while verifying a set of exits. Pubkey, File and
+// ValidatorIndex are set where the problem is specific to them.
+type Finding struct {
+	Kind           FindingKind `json:"kind"`
+	Pubkey         string      `json:"pubkey,omitempty"`
+	File           string      `json:"file,omitempty"`
+	ValidatorIndex *uint64     `json:"validator_index,omitempty"`
+	Message        string      `json:"message"`
+}
+
+// Error returns the message of the finding, so that outside of collect mode
+// it is returned as is
+func (f Finding) Error() string {
+	return f.Message
+}
+
+// exitFinding returns a finding about a single exit
+func exitFinding(kind FindingKind, vexit *VoluntaryExit, message string) Finding {
+	index := uint64(vexit.PBExit.Exit.ValidatorIndex)
+
+	return Finding{
+		Kind:           kind,
+		Pubkey:         hex.EncodeToString(vexit.Pubkey),
+		File:           vexit.Path,
+		ValidatorIndex: &index,
+		Message:        message,
+	}
+}
+
+// Findings collects the problems found by every check, instead of stopping
+// at the first one
+type Findings struct {
+	Findings []Finding
+}
+
+func (f *Findings) add(finding Finding) {
+	f.Findings = append(f.Findings, finding)
+}
+
+// AddChecksums records the mismatches of a checksum report of dir
+func (f *Findings) AddChecksums(dir string, report *ChecksumReport) {
+	for _, files := range []struct {
+		names   []string
+		message string
+	}{
+		{report.Missing, "file listed in checksum manifest is missing"},
+		{report.Extra, "file is not listed in checksum manifest"},
+		{report.Modified, "file does not match checksum manifest"},
+	} {
+		for _, name := range files.names {
+			f.add(Finding{Kind: FindingChecksum, File: filepath.Join(dir, name), Message: files.message})
+		}
+	}
+}
+
+// FindingsReport groups findings by pubkey and file
+type FindingsReport struct {
+	Total   int                        `json:"total"`
+	Summary map[FindingKind]int        `json:"summary"`
+	Pubkeys map[string]*PubkeyFindings `json:"pubkeys"`
+	// Files holds findings about files whose pubkey is not known, such as
+	// files with an invalid name
+	Files map[string][]Finding `json:"files"`
+	// Other holds findings about the set as a whole
+	Other []Finding `json:"other"`
+}
+
+// PubkeyFindings are the findings of one pubkey
+type PubkeyFindings struct {
+	// Findings are about the pubkey's exits as a whole
+	Findings []Finding `json:"findings"`
+	// Files holds findings about single files, keyed by path
+	Files map[string][]Finding `json:"files"`
+}
+
+// Report groups the findings by pubkey and file
+func (f *Findings) Report() *FindingsReport {
+	report := &FindingsReport{
+		Total:   len(f.Findings),
+		Summary: map[FindingKind]int{},
+		Pubkeys: map[string]*PubkeyFindings{},
+		Files:   map[string][]Finding{},
+		Other:   []Finding{},
+	}
+
+	for _, finding := range f.Findings {
+		report.Summary[finding.Kind]++
+
+		switch {
+		case finding.Pubkey != "":
+			pubkey, ok := report.Pubkeys[finding.Pubkey]
+			if !ok {
+				pubkey = &PubkeyFindings{Findings: []Finding{}, Files: map[string][]Finding{}}
+				report.Pubkeys[finding.Pubkey] = pubkey
+			}
+
+			if finding.File != "" {
+				pubkey.Files[finding.File] = append(pubkey.Files[finding.File], finding)
+			} else {
+				pubkey.Findings = append(pubkey.Findings, finding)
+			}
+		case finding.File != "":
+			report.Files[finding.File] = append(report.Files[finding.File], finding)
+		default:
+			report.Other = append(report.Other, finding)
+		}
+	}
+
+	return report
+}
+
+// Err returns an error summarising the report, or nil if nothing was found
+func (r *FindingsReport) Err() error {
+	if r.Total == 0 {
+		return nil
+	}
+
+	kinds := make([]string, 0, len(r.Summary))
+	for kind := range r.Summary {
+		kinds = append(kinds, string(kind))
+	}
+
+	sort.Strings(kinds)
+
+	counts := make([]string, 0, len(kinds))
+	for _, kind := range kinds {
+		counts = append(counts, fmt.Sprintf("%d %s", r.Summary[FindingKind(kind)], kind))
+	}
+
+	return fmt.Errorf("%d problems found across %d pubkeys and %d files: %s",
+		r.Total, len(r.Pubkeys), r.fileCount(), strings.Join(counts, ", "))
+}
+
+// fileCount returns the number of distinct files with findings
+func (r *FindingsReport) fileCount() int {
+	count := len(r.Files)
+	for _, pubkey := range r.Pubkeys {
+		count += len(pubkey.Files)
+	}
+
+	return count
+}
+
+// Log logs every finding
+func (f *Findings) Log() {
+	for _, finding := range f.Findings {
+		fields := logrus.Fields{"check": finding.Kind}
+
+		if finding.Pubkey != "" {
+			fields["pubkey"] = finding.Pubkey
+		}
+
+		if finding.File != "" {
+			fields["file"] = finding.File
+		}
+
+		if finding.ValidatorIndex != nil {
+			fields["validator_index"] = *finding.ValidatorIndex
+		}
+
+		log.WithFields(fields).Error(finding.Message)
+	}
+}
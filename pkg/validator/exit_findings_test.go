@@ -0,0 +1,117 @@
+package validator
+
+import (
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/prysmaticlabs/prysm/v5/config/params"
+	"github.com/prysmaticlabs/prysm/v5/crypto/bls"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindingsReport(t *testing.T) {
+	index := uint64(7)
+
+	findings := &Findings{}
+	findings.add(Finding{Kind: FindingParse, File: "/exits/bad.json", Message: "invalid file name format"})
+	findings.add(Finding{Kind: FindingCount, Pubkey: "aa", Message: "3 files found but expected 4 for pubkey aa"})
+	findings.add(Finding{Kind: FindingSignature, Pubkey: "aa", File: "/exits/7-aa.json", ValidatorIndex: &index, Message: "signature did not verify"})
+	findings.add(Finding{Kind: FindingStructure, Pubkey: "aa", File: "/exits/7-aa.json", ValidatorIndex: &index, Message: "non-active validator cannot exit"})
+	findings.add(Finding{Kind: FindingCount, Message: "no voluntary exits found"})
+	findings.AddChecksums("/exits", &ChecksumReport{Modified: []string{"8-aa.json"}})
+
+	report := findings.Report()
+
+	assert.Equal(t, 6, report.Total)
+	assert.Equal(t, map[FindingKind]int{
+		FindingParse:     1,
+		FindingCount:     2,
+		FindingSignature: 1,
+		FindingStructure: 1,
+		FindingChecksum:  1,
+	}, report.Summary)
+
+	require.Contains(t, report.Pubkeys, "aa")
+	assert.Len(t, report.Pubkeys["aa"].Findings, 1)
+	assert.Len(t, report.Pubkeys["aa"].Files["/exits/7-aa.json"], 2)
+	assert.Len(t, report.Files, 2)
+	assert.Contains(t, report.Files, "/exits/bad.json")
+	assert.Contains(t, report.Files, "/exits/8-aa.json")
+	assert.Len(t, report.Other, 1)
+
+	assert.EqualError(t, report.Err(), "6 problems found across 1 pubkeys and 3 files: 1 checksum, 2 count, 1 parse, 1 signature, 1 structure")
+
+	assert.NoError(t, (&Findings{}).Report().Err())
+}
+
+func TestCollectVoluntaryExits(t *testing.T) {
+	defer params.OverrideBeaconConfig(params.MainnetConfig())
+
+	hoodi := params.HoodiConfig()
+
+	key, err := bls.RandKey()
+	require.NoError(t, err)
+
+	otherKey, err := bls.RandKey()
+	require.NoError(t, err)
+
+	missingKey, err := bls.RandKey()
+	require.NoError(t, err)
+
+	pubkey := hex.EncodeToString(key.PublicKey().Marshal())
+	otherPubkey := hex.EncodeToString(otherKey.PublicKey().Marshal())
+	missingPubkey := hex.EncodeToString(missingKey.PublicKey().Marshal())
+
+	dir := t.TempDir()
+
+	// Indices 0, 1 and 3 of key, with 1 signed for the wrong fork, index 0
+	// of a key that is not expected, a file that doesn't parse and a file
+	// with an invalid name
+	writeSignedExit(t, dir, key, hoodi, hoodi.CapellaForkVersion, 0)
+	writeSignedExit(t, dir, key, hoodi, hoodi.ElectraForkVersion, 1)
+	writeSignedExit(t, dir, key, hoodi, hoodi.CapellaForkVersion, 3)
+	writeSignedExit(t, dir, otherKey, hoodi, hoodi.CapellaForkVersion, 0)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "7-0x"+pubkey+".json"), []byte(`{}`), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "broken.json"), []byte(`{}`), 0o600))
+
+	expected := []string{"0x" + pubkey, "0x" + missingPubkey}
+
+	// Without collecting, loading stops at the first problem
+	_, err = NewVoluntaryExits(dir, "hoodi", "0x"+hex.EncodeToString(make([]byte, 32)), expected)
+	require.Error(t, err)
+
+	exits, err := CollectVoluntaryExits(dir, "hoodi", "0x"+hex.EncodeToString(make([]byte, 32)), expected)
+	require.NoError(t, err)
+	require.NoError(t, exits.ValidateCount(3))
+	require.NoError(t, exits.ValidateIndices())
+
+	_, err = exits.Verify()
+	require.NoError(t, err)
+
+	report := exits.Findings.Report()
+
+	assert.Equal(t, map[FindingKind]int{
+		FindingParse:     1,
+		FindingFileName:  1,
+		FindingPubkey:    2,
+		FindingCount:     2,
+		FindingIndices:   1,
+		FindingSignature: 1,
+	}, report.Summary)
+
+	assert.Contains(t, report.Files, filepath.Join(dir, "7-0x"+pubkey+".json"))
+	assert.Contains(t, report.Files, filepath.Join(dir, "broken.json"))
+	assert.Len(t, report.Pubkeys[missingPubkey].Findings, 1)
+	require.NotEmpty(t, report.Pubkeys[otherPubkey].Findings)
+	assert.Equal(t, "unexpected pubkey found: "+otherPubkey, report.Pubkeys[otherPubkey].Findings[0].Message)
+
+	signatureFindings := report.Pubkeys[pubkey].Files[filepath.Join(dir, "1-0x"+pubkey+".json")]
+	require.Len(t, signatureFindings, 1)
+	assert.Equal(t, FindingSignature, signatureFindings[0].Kind)
+	assert.Equal(t, uint64(1), *signatureFindings[0].ValidatorIndex)
+
+	require.Error(t, report.Err())
+}
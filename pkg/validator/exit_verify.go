@@ -0,0 +1,118 @@
+package validator
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/prysm/v5/beacon-chain/core/blocks"
+	"github.com/prysmaticlabs/prysm/v5/beacon-chain/core/signing"
+	"github.com/prysmaticlabs/prysm/v5/config/params"
+	"github.com/prysmaticlabs/prysm/v5/consensus-types/primitives"
+	ethpb "github.com/prysmaticlabs/prysm/v5/proto/prysm/v1alpha1"
+)
+
+// exitDomain returns the signing domain of voluntary exits on the active
+// network. Since Deneb (EIP-7044) exits are signed with the Capella fork
+// version whatever the current fork, so that they stay valid forever.
+func exitDomain() ([]byte, error) {
+	cfg := params.BeaconConfig()
+
+	domain, err := signing.ComputeDomain(cfg.DomainVoluntaryExit, cfg.CapellaForkVersion, cfg.GenesisValidatorsRoot[:])
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to compute voluntary exit domain")
+	}
+
+	return domain, nil
+}
+
+// verifyExitSignature verifies the signature of an exit against its pubkey
+func verifyExitSignature(vexit *VoluntaryExit, domain []byte) error {
+	pubkey, sig, err := parseSignature(vexit.Pubkey, vexit.PBExit.Signature)
+	if err != nil {
+		return err
+	}
+
+	root, err := signing.ComputeSigningRoot(vexit.PBExit.Exit, domain)
+	if err != nil {
+		return errors.Wrap(err, "failed to compute signing root")
+	}
+
+	if !sig.Verify(pubkey, root[:]) {
+		return signing.ErrSigFailedToVerify
+	}
+
+	return nil
+}
+
+// exitChecker checks exits against the rules the chain applies when they are
+// submitted, besides their signatures. Without a state file only the exit
+// epoch can be checked: the registry rules and the Electra/Fulu pending
+// partial withdrawal rule need the state. With one, exits for validators in
+// the state are also checked against their registry entry and the withdrawal
+// queue.
+type exitChecker struct {
+	epoch      primitives.Epoch
+	knownEpoch bool
+	stateFile  *StateFile
+}
+
+func newExitChecker(stateFile *StateFile) *exitChecker {
+	if stateFile != nil {
+		return &exitChecker{epoch: stateFile.epoch(), knownEpoch: true, stateFile: stateFile}
+	}
+
+	cfg := params.BeaconConfig()
+	_, known := networkGenesisTime(cfg.ConfigName, cfg)
+
+	return &exitChecker{epoch: networkCurrentEpoch(cfg.ConfigName, cfg, time.Now()), knownEpoch: known}
+}
+
+// check returns an error if the exit would be rejected by the chain. taken is
+// set for exits of indices the state file assigns to another validator, which
+// can never be used.
+func (c *exitChecker) check(vexit *VoluntaryExit) (taken bool, err error) {
+	exit := vexit.PBExit.Exit
+
+	if c.knownEpoch && exit.Epoch > c.epoch {
+		return false, fmt.Errorf("expected current epoch >= exit epoch, received %d < %d", c.epoch, exit.Epoch)
+	}
+
+	if c.stateFile == nil || int(exit.ValidatorIndex) >= c.stateFile.ValidatorCount() {
+		return false, nil
+	}
+
+	validator, ours := c.stateFile.registryValidator(exit.ValidatorIndex, vexit.Pubkey)
+	if !ours {
+		return true, nil
+	}
+
+	return false, c.checkValidator(validator, exit)
+}
+
+// checkValidator applies the registry rules of process_voluntary_exit
+func (c *exitChecker) checkValidator(validator *ethpb.Validator, exit *ethpb.VoluntaryExit) error {
+	cfg := params.BeaconConfig()
+
+	if validator.GetActivationEpoch() > c.epoch || c.epoch >= validator.GetExitEpoch() {
+		return errors.New("non-active validator cannot exit")
+	}
+
+	if validator.GetExitEpoch() != cfg.FarFutureEpoch {
+		return fmt.Errorf("validator with index %d %s: %v", exit.ValidatorIndex, blocks.ValidatorAlreadyExitedMsg, validator.GetExitEpoch())
+	}
+
+	if c.epoch < validator.GetActivationEpoch()+cfg.ShardCommitteePeriod {
+		return fmt.Errorf("%s: %d of %d epochs. Validator will be eligible for exit at epoch %d",
+			blocks.ValidatorCannotExitYetMsg,
+			c.epoch-validator.GetActivationEpoch(),
+			cfg.ShardCommitteePeriod,
+			validator.GetActivationEpoch()+cfg.ShardCommitteePeriod)
+	}
+
+	if c.stateFile.hasPendingWithdrawal(exit.ValidatorIndex) {
+		return fmt.Errorf("validator %d must have no pending balance to withdraw", exit.ValidatorIndex)
+	}
+
+	return nil
+}
@@ -0,0 +1,155 @@
+package validator
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/prysmaticlabs/prysm/v5/config/params"
+	"github.com/prysmaticlabs/prysm/v5/consensus-types/primitives"
+	"github.com/prysmaticlabs/prysm/v5/crypto/bls"
+	ethpb "github.com/prysmaticlabs/prysm/v5/proto/prysm/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyCapellaPinnedSignature(t *testing.T) {
+	defer params.OverrideBeaconConfig(params.MainnetConfig())
+
+	key, err := bls.RandKey()
+	require.NoError(t, err)
+
+	pubkey := hex.EncodeToString(key.PublicKey().Marshal())
+	hoodi := params.HoodiConfig()
+
+	tests := []struct {
+		name        string
+		forkVersion []byte
+		expectError bool
+	}{
+		{
+			name:        "signed with capella fork version",
+			forkVersion: hoodi.CapellaForkVersion,
+		},
+		{
+			name:        "signed with electra fork version",
+			forkVersion: hoodi.ElectraForkVersion,
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeSignedExit(t, dir, key, hoodi, tt.forkVersion, 3)
+
+			exits, err := NewVoluntaryExits(dir, "hoodi", "0x"+hex.EncodeToString(make([]byte, 32)), []string{"0x" + pubkey})
+			require.NoError(t, err)
+
+			rsp, err := exits.Verify()
+			if tt.expectError {
+				require.Error(t, err)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, uint64(3), rsp.FirstIndex)
+		})
+	}
+}
+
+func TestVerifyStructure(t *testing.T) {
+	defer params.OverrideBeaconConfig(params.MainnetConfig())
+
+	key, err := bls.RandKey()
+	require.NoError(t, err)
+
+	pubkey := key.PublicKey().Marshal()
+	hoodi := params.HoodiConfig()
+	farFuture := params.BeaconConfig().FarFutureEpoch
+
+	dir := t.TempDir()
+	writeSignedExit(t, dir, key, hoodi, hoodi.CapellaForkVersion, 0)
+
+	tests := []struct {
+		name        string
+		validator   *ethpb.Validator
+		pending     []*ethpb.PendingPartialWithdrawal
+		expectError string
+	}{
+		{
+			name:      "active validator",
+			validator: testValidator(pubkey, 0, farFuture),
+		},
+		{
+			name:        "pending activation",
+			validator:   testValidator(pubkey, testStateEpoch+1, farFuture),
+			expectError: "non-active validator",
+		},
+		{
+			name:        "already exiting",
+			validator:   testValidator(pubkey, 0, testStateEpoch+1),
+			expectError: "has already submitted an exit",
+		},
+		{
+			name:        "not active long enough",
+			validator:   testValidator(pubkey, testStateEpoch-1, farFuture),
+			expectError: "validator has not been active long enough to exit",
+		},
+		{
+			// Electra and later reject exits for validators with a pending partial withdrawal
+			name:        "pending partial withdrawal",
+			validator:   testValidator(pubkey, 0, farFuture),
+			pending:     []*ethpb.PendingPartialWithdrawal{{Index: 0, Amount: 1}},
+			expectError: "pending balance to withdraw",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exits, err := NewVoluntaryExits(dir, "hoodi", "0x"+hex.EncodeToString(make([]byte, 32)), []string{"0x" + hex.EncodeToString(pubkey)})
+			require.NoError(t, err)
+
+			// The signature is valid either way
+			_, err = exits.Verify()
+			require.NoError(t, err)
+
+			f, err := LoadStateFile(writeTestBeaconState(t, []*ethpb.Validator{tt.validator}, tt.pending...), "hoodi")
+			require.NoError(t, err)
+
+			exits.UseStateFile(f)
+
+			_, err = exits.Verify()
+			if tt.expectError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectError)
+
+				return
+			}
+
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestExitCheckerEpoch(t *testing.T) {
+	require.NoError(t, setNetwork("hoodi"))
+
+	defer params.OverrideBeaconConfig(params.MainnetConfig())
+
+	checker := newExitChecker(nil)
+	assert.True(t, checker.knownEpoch)
+
+	exit := func(epoch primitives.Epoch) *VoluntaryExit {
+		return &VoluntaryExit{PBExit: &ethpb.SignedVoluntaryExit{Exit: &ethpb.VoluntaryExit{Epoch: epoch, ValidatorIndex: 5}}}
+	}
+
+	// Without a state only the exit epoch is checked
+	taken, err := checker.check(exit(0))
+	require.NoError(t, err)
+	assert.False(t, taken)
+
+	_, err = checker.check(exit(checker.epoch + 1))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "expected current epoch >= exit epoch")
+}
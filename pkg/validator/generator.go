@@ -1,15 +1,17 @@
 package validator
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"math"
 	"os"
 	"path/filepath"
-	"strconv"
 	"sync/atomic"
 
 	"github.com/pkg/errors"
+
+	"github.com/ethpandaops/validator-tools/pkg/beacon"
 )
 
 type VoluntaryExitGenerator struct {
@@ -17,12 +19,17 @@ type VoluntaryExitGenerator struct {
 	WithdrawalCredentials string
 	Passphrase            string
 	BeaconURL             string
+	Beacon                beacon.Client
 	Iterations            int
 	IndexStart            int
 	IndexOffset           int
+	IndexStateID          string
 	NumWorkers            int
 	TotalKeystores        int32
 	CurrentKeystore       int32
+	Pubkeys               []string
+	Hooks                 *Hooks
+	Progress              *Progress
 }
 
 func NewVoluntaryExitGenerator(outputDir, withdrawalCreds, passphrase, beaconURL string, iterations, indexStart, indexOffset, numWorkers int) *VoluntaryExitGenerator {
@@ -72,50 +79,85 @@ func (g *VoluntaryExitGenerator) SetTotalKeystores(total int) {
 	log.Infof("Total keystores to process: %d", g.TotalKeystores)
 }
 
-func (g *VoluntaryExitGenerator) GetValidatorStartIndex() (int, error) {
+func (g *VoluntaryExitGenerator) GetValidatorStartIndex(ctx context.Context) (int, error) {
 	if g.IndexStart >= 0 {
 		return g.IndexStart + g.IndexOffset, nil
 	}
 
-	resp, err := g.FetchJSON(g.BeaconURL + "/eth/v1/beacon/states/head/validators")
-	if err != nil {
-		return 0, err
+	if g.Beacon == nil {
+		return 0, errors.New("a beacon node is required to find the validator start index")
 	}
 
-	var result struct {
-		Data []struct {
-			Index string `json:"index"`
-		} `json:"data"`
-	}
+	// A state file holds the whole registry, no need to search
+	if stateFile, ok := g.Beacon.(*StateFile); ok {
+		if stateFile.ValidatorCount() == 0 {
+			return 0, errors.New("no validators found in beacon state file")
+		}
 
-	if err := json.Unmarshal(resp, &result); err != nil {
-		return 0, errors.Wrap(err, "failed to parse validator response")
-	}
+		maxIndex := stateFile.ValidatorCount() - 1
 
-	maxIndex := -1
+		log.WithField("slot", stateFile.Slot()).Infof("Highest validator index: %d", maxIndex)
 
-	for _, v := range result.Data {
-		index, err := strconv.Atoi(v.Index)
-		if err != nil {
-			continue
-		}
+		return maxIndex + g.IndexOffset, nil
+	}
 
-		if index > maxIndex {
-			maxIndex = index
-		}
+	stateID := g.IndexStateID
+	if stateID == "" {
+		stateID = "head"
 	}
 
-	if maxIndex == -1 {
-		return 0, errors.New("no valid validator indices found")
+	maxIndex, err := beacon.HighestValidatorIndex(ctx, g.Beacon, stateID)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to find the highest validator index")
 	}
 
-	return maxIndex + g.IndexOffset, nil
+	log.WithField("state", stateID).Infof("Highest validator index: %d", maxIndex)
+
+	return int(maxIndex) + g.IndexOffset, nil
 }
 
-func (g *VoluntaryExitGenerator) GenerateExits(keystorePath string, config *BeaconConfig, startIndex int) error {
+// exitFileName returns the name of the exit file generated for a validator index
+func exitFileName(validatorIndex int, pubkey string) string {
+	return fmt.Sprintf("%d-%s.json", validatorIndex, pubkey)
+}
+
+// KeystoreError is returned by GenerateExits when generating the exits of a
+// keystore fails, so that the failure can be reported with the keystore
+type KeystoreError struct {
+	Keystore       string
+	Pubkey         string
+	KeystoreNumber int
+	TotalKeystores int
+	StartIndex     int
+	Err            error
+}
+
+func (e *KeystoreError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *KeystoreError) Unwrap() error {
+	return e.Err
+}
+
+func (g *VoluntaryExitGenerator) GenerateExits(keystorePath string, config *BeaconConfig, startIndex int) (err error) {
 	atomic.AddInt32(&g.CurrentKeystore, 1)
 	keystoreNum := atomic.LoadInt32(&g.CurrentKeystore)
 
+	keystoreErr := &KeystoreError{
+		Keystore:       keystorePath,
+		KeystoreNumber: int(keystoreNum),
+		TotalKeystores: int(g.TotalKeystores),
+		StartIndex:     startIndex,
+	}
+
+	defer func() {
+		if err != nil {
+			keystoreErr.Err = err
+			err = keystoreErr
+		}
+	}()
+
 	if err := config.Validate(); err != nil {
 		return errors.Wrap(err, "invalid beacon configuration")
 	}
@@ -133,6 +175,8 @@ func (g *VoluntaryExitGenerator) GenerateExits(keystorePath string, config *Beac
 
 	log.Infof("Absolute keystore path: %s", absKeystorePath)
 
+	keystoreErr.Keystore = absKeystorePath
+
 	log.Info("Reading pubkey from keystore")
 
 	keystoreData, err := os.ReadFile(absKeystorePath)
@@ -160,6 +204,8 @@ func (g *VoluntaryExitGenerator) GenerateExits(keystorePath string, config *Beac
 
 	log.Infof("Pubkey: %s", keystoreJSON.Pubkey)
 
+	keystoreErr.Pubkey = keystoreJSON.Pubkey
+
 	tasks := make(chan exitTask, g.Iterations)
 
 	log.Info("Sending tasks to workers")
@@ -178,6 +224,31 @@ func (g *VoluntaryExitGenerator) GenerateExits(keystorePath string, config *Beac
 		return err
 	}
 
+	g.Pubkeys = append(g.Pubkeys, keystoreJSON.Pubkey)
+
+	// Only hash the files of this keystore, the others are already in the manifest
+	written := make([]string, 0, g.Iterations)
+	for i := 1; i <= g.Iterations; i++ {
+		written = append(written, exitFileName(startIndex+i, keystoreJSON.Pubkey))
+	}
+
+	if err := UpdateChecksums(g.OutputDir, written); err != nil {
+		return errors.Wrap(err, "failed to update checksum manifest")
+	}
+
+	if err := g.Hooks.Run(&HookContext{
+		Event:          HookAfterKeystore,
+		Keystore:       absKeystorePath,
+		Pubkey:         keystoreJSON.Pubkey,
+		KeystoreNumber: int(keystoreNum),
+		TotalKeystores: int(g.TotalKeystores),
+		Count:          g.Iterations,
+		StartIndex:     startIndex,
+		OutputDir:      g.OutputDir,
+	}); err != nil {
+		return err
+	}
+
 	log.Infof("Exit generation completed for keystore %d/%d", keystoreNum, g.TotalKeystores)
 
 	return nil
@@ -1,13 +1,17 @@
 package validator
 
 import (
+	"context"
 	"encoding/json"
+	"io"
 	"os"
 	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/ethpandaops/validator-tools/pkg/beacon"
 )
 
 func TestNewVoluntaryExitGenerator(t *testing.T) {
@@ -98,10 +102,17 @@ func TestSetTotalKeystores(t *testing.T) {
 }
 
 func TestGetValidatorStartIndex(t *testing.T) {
+	registry := make([]*beacon.Validator, 43)
+	for i := range registry {
+		registry[i] = &beacon.Validator{Index: uint64(i)}
+	}
+
 	tests := []struct {
 		name        string
 		indexStart  int
 		indexOffset int
+		stateID     string
+		beacon      *mockBeaconClient
 		expected    int
 		expectErr   bool
 	}{
@@ -117,18 +128,43 @@ func TestGetValidatorStartIndex(t *testing.T) {
 			indexStart:  -1,
 			indexOffset: 0,
 			expected:    0,
-			expectErr:   true, // Will fail due to missing beacon URL
+			expectErr:   true, // Will fail due to missing beacon node
+		},
+		{
+			name:        "highest index from beacon node",
+			indexStart:  -1,
+			indexOffset: 5,
+			beacon:      &mockBeaconClient{validators: registry},
+			expected:    47,
+		},
+		{
+			name:       "highest index in finalized state",
+			indexStart: -1,
+			stateID:    "finalized",
+			beacon:     &mockBeaconClient{validators: registry[:10]},
+			expected:   9,
+		},
+		{
+			name:       "no validators on beacon node",
+			indexStart: -1,
+			beacon:     &mockBeaconClient{},
+			expectErr:  true,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			g := &VoluntaryExitGenerator{
-				IndexStart:  tt.indexStart,
-				IndexOffset: tt.indexOffset,
+				IndexStart:   tt.indexStart,
+				IndexOffset:  tt.indexOffset,
+				IndexStateID: tt.stateID,
 			}
 
-			got, err := g.GetValidatorStartIndex()
+			if tt.beacon != nil {
+				g.Beacon = tt.beacon
+			}
+
+			got, err := g.GetValidatorStartIndex(context.Background())
 			if tt.expectErr {
 				assert.Error(t, err)
 
@@ -137,6 +173,17 @@ func TestGetValidatorStartIndex(t *testing.T) {
 
 			assert.NoError(t, err)
 			assert.Equal(t, tt.expected, got)
+
+			if tt.beacon == nil {
+				return
+			}
+
+			// Only single validators are probed, never the full registry
+			assert.Empty(t, tt.beacon.ids)
+
+			if tt.stateID != "" {
+				assert.Subset(t, []string{tt.stateID}, tt.beacon.stateIDs)
+			}
 		})
 	}
 }
@@ -216,11 +263,22 @@ func TestGenerateExits(t *testing.T) {
 		},
 	}
 
+	origExecCommand := execCommand
+
+	defer func() { execCommand = origExecCommand }()
+
+	execCommand = func(name string, args ...string) commander {
+		return &mockCmd{t: t, output: []byte(`{"exit": true}`)}
+	}
+
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
+			outputDir := t.TempDir()
+
 			g := &VoluntaryExitGenerator{
-				OutputDir:  tempDir,
-				Iterations: 1,
+				OutputDir:  outputDir,
+				Iterations: 2,
+				NumWorkers: 1,
 			}
 
 			err := g.GenerateExits(tt.keystorePath, tt.config, tt.startIndex)
@@ -230,7 +288,79 @@ func TestGenerateExits(t *testing.T) {
 				return
 			}
 
-			assert.NoError(t, err)
+			require.NoError(t, err)
+
+			// The checksum manifest covers the files of the keystore
+			report, err := VerifyChecksums(outputDir)
+			require.NoError(t, err)
+			assert.True(t, report.OK())
+
+			sums, err := readChecksums(filepath.Join(outputDir, ChecksumsFileName))
+			require.NoError(t, err)
+			assert.Len(t, sums, 2)
 		})
 	}
 }
+
+func TestGenerateExitsHooks(t *testing.T) {
+	tempDir := t.TempDir()
+	keystorePath := filepath.Join(tempDir, "keystore.json")
+	require.NoError(t, os.WriteFile(keystorePath, []byte(`{"pubkey": "0x1234567890abcdef"}`), 0o600))
+
+	config := &BeaconConfig{
+		GenesisValidatorsRoot:      "0x123",
+		GenesisVersion:             "0x456",
+		ExitForkVersion:            "0x789",
+		CurrentForkVersion:         "0xabc",
+		Epoch:                      "123",
+		BlsToExecutionChangeDomain: "0xdef",
+		VoluntaryExitDomain:        "0xghi",
+	}
+
+	origExecCommand, origHookCommand := execCommand, hookCommand
+
+	defer func() { execCommand, hookCommand = origExecCommand, origHookCommand }()
+
+	hooks, err := NewHooks(map[HookEvent]string{HookAfterKeystore: "true"}, string(HookFailureAbort))
+	require.NoError(t, err)
+
+	t.Run("after_keystore sees the updated checksum manifest", func(t *testing.T) {
+		outputDir := t.TempDir()
+
+		execCommand = func(name string, args ...string) commander {
+			return &mockCmd{t: t, output: []byte(`{"exit": true}`)}
+		}
+
+		var sums map[string]string
+
+		hookCommand = func(command string, stdin io.Reader) commander {
+			sums, err = readChecksums(filepath.Join(outputDir, ChecksumsFileName))
+			require.NoError(t, err)
+
+			return &mockCmd{t: t}
+		}
+
+		g := &VoluntaryExitGenerator{OutputDir: outputDir, Iterations: 2, NumWorkers: 1, Hooks: hooks}
+		require.NoError(t, g.GenerateExits(keystorePath, config, 10))
+
+		assert.Contains(t, sums, "11-0x1234567890abcdef.json")
+		assert.Contains(t, sums, "12-0x1234567890abcdef.json")
+	})
+
+	t.Run("failures report the keystore", func(t *testing.T) {
+		execCommand = func(name string, args ...string) commander {
+			return &mockCmd{t: t, shouldFail: true}
+		}
+
+		g := &VoluntaryExitGenerator{OutputDir: t.TempDir(), Iterations: 2, NumWorkers: 1, Hooks: hooks, TotalKeystores: 3}
+		err := g.GenerateExits(keystorePath, config, 10)
+		require.Error(t, err)
+
+		var keystoreErr *KeystoreError
+		require.ErrorAs(t, err, &keystoreErr)
+		assert.Equal(t, keystorePath, keystoreErr.Keystore)
+		assert.Equal(t, "0x1234567890abcdef", keystoreErr.Pubkey)
+		assert.Equal(t, 3, keystoreErr.TotalKeystores)
+		assert.Equal(t, 10, keystoreErr.StartIndex)
+	})
+}
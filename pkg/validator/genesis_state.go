@@ -0,0 +1,121 @@
+package validator
+
+import (
+	"bytes"
+	"encoding/hex"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/prysm/v5/beacon-chain/state/stateutil"
+	"github.com/prysmaticlabs/prysm/v5/config/params"
+	"github.com/prysmaticlabs/prysm/v5/consensus-types/primitives"
+	ethpb "github.com/prysmaticlabs/prysm/v5/proto/prysm/v1alpha1"
+	"github.com/prysmaticlabs/prysm/v5/runtime/version"
+	"github.com/sirupsen/logrus"
+)
+
+// stateForkVersionOffset is the offset of fork.current_version in an SSZ
+// BeaconState: 8 (genesis_time) + 32 (genesis_validators_root) + 8 (slot) + 4 (previous_version)
+const stateForkVersionOffset = 52
+
+// sszBeaconState is implemented by every fork's BeaconState protobuf
+type sszBeaconState interface {
+	UnmarshalSSZ(buf []byte) error
+	GetGenesisTime() uint64
+	GetGenesisValidatorsRoot() []byte
+	GetSlot() primitives.Slot
+	GetFork() *ethpb.Fork
+	GetValidators() []*ethpb.Validator
+	GetBalances() []uint64
+}
+
+// GenesisInfo holds the network identity derived from a genesis state
+type GenesisInfo struct {
+	GenesisTime           uint64
+	GenesisValidatorsRoot [32]byte
+	ForkVersion           []byte
+	Validators            int
+}
+
+// LoadGenesisState reads an SSZ genesis state and derives the genesis validators
+// root from the hash tree root of its validator registry
+func LoadGenesisState(path string, cfg *params.BeaconChainConfig) (*GenesisInfo, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read genesis state")
+	}
+
+	st, fork, err := decodeBeaconState(data, cfg)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode genesis state")
+	}
+
+	root, err := stateutil.ValidatorRegistryRoot(st.GetValidators())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to compute genesis validators root")
+	}
+
+	if !bytes.Equal(root[:], st.GetGenesisValidatorsRoot()) {
+		log.WithFields(logrus.Fields{
+			"computed": "0x" + hex.EncodeToString(root[:]),
+			"state":    "0x" + hex.EncodeToString(st.GetGenesisValidatorsRoot()),
+		}).Warn("Genesis validators root in state does not match its validator registry")
+	}
+
+	info := &GenesisInfo{
+		GenesisTime:           st.GetGenesisTime(),
+		GenesisValidatorsRoot: root,
+		ForkVersion:           st.GetFork().GetCurrentVersion(),
+		Validators:            len(st.GetValidators()),
+	}
+
+	log.WithFields(logrus.Fields{
+		"fork":                    version.String(fork),
+		"fork_version":            "0x" + hex.EncodeToString(info.ForkVersion),
+		"genesis_time":            info.GenesisTime,
+		"genesis_validators_root": "0x" + hex.EncodeToString(root[:]),
+		"validators":              info.Validators,
+	}).Info("Loaded genesis state")
+
+	return info, nil
+}
+
+// decodeBeaconState unmarshals an SSZ BeaconState of any fork. The fork is
+// detected from the state's fork.current_version using the given config.
+func decodeBeaconState(data []byte, cfg *params.BeaconChainConfig) (sszBeaconState, int, error) {
+	if len(data) < stateForkVersionOffset+4 {
+		return nil, 0, errors.New("state is too short")
+	}
+
+	currentVersion := data[stateForkVersionOffset : stateForkVersionOffset+4]
+
+	fork, ok := params.ConfigForkVersions(cfg)[[4]byte(currentVersion)]
+	if !ok {
+		return nil, 0, errors.Errorf("fork version 0x%x is not part of network %s", currentVersion, cfg.ConfigName)
+	}
+
+	var st sszBeaconState
+
+	switch fork {
+	case version.Phase0:
+		st = &ethpb.BeaconState{}
+	case version.Altair:
+		st = &ethpb.BeaconStateAltair{}
+	case version.Bellatrix:
+		st = &ethpb.BeaconStateBellatrix{}
+	case version.Capella:
+		st = &ethpb.BeaconStateCapella{}
+	case version.Deneb:
+		st = &ethpb.BeaconStateDeneb{}
+	case version.Electra, version.Fulu:
+		st = &ethpb.BeaconStateElectra{}
+	default:
+		return nil, 0, errors.Errorf("unsupported fork %s", version.String(fork))
+	}
+
+	if err := st.UnmarshalSSZ(data); err != nil {
+		return nil, 0, errors.Wrapf(err, "failed to unmarshal %s state", version.String(fork))
+	}
+
+	return st, fork, nil
+}
@@ -0,0 +1,147 @@
+package validator
+
+import (
+	"bytes"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/prysmaticlabs/prysm/v5/beacon-chain/state/stateutil"
+	"github.com/prysmaticlabs/prysm/v5/config/params"
+	ethpb "github.com/prysmaticlabs/prysm/v5/proto/prysm/v1alpha1"
+	"github.com/prysmaticlabs/prysm/v5/testing/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeTestGenesisState writes a Deneb genesis state with the given fork version
+// and returns its path and the expected genesis validators root
+func writeTestGenesisState(t *testing.T, forkVersion []byte, genesisTime uint64) (string, [32]byte) {
+	t.Helper()
+
+	var root [32]byte
+
+	st, err := util.NewBeaconStateDeneb(func(s *ethpb.BeaconStateDeneb) error {
+		s.GenesisTime = genesisTime
+		s.Fork = &ethpb.Fork{
+			PreviousVersion: forkVersion,
+			CurrentVersion:  forkVersion,
+		}
+
+		for i := 0; i < 4; i++ {
+			pubkey := make([]byte, 48)
+			pubkey[0] = byte(i + 1)
+
+			s.Validators = append(s.Validators, &ethpb.Validator{
+				PublicKey:             pubkey,
+				WithdrawalCredentials: make([]byte, 32),
+				EffectiveBalance:      params.BeaconConfig().MaxEffectiveBalance,
+			})
+			s.Balances = append(s.Balances, params.BeaconConfig().MaxEffectiveBalance)
+			s.PreviousEpochParticipation = append(s.PreviousEpochParticipation, 0)
+			s.CurrentEpochParticipation = append(s.CurrentEpochParticipation, 0)
+			s.InactivityScores = append(s.InactivityScores, 0)
+		}
+
+		r, err := stateutil.ValidatorRegistryRoot(s.Validators)
+		if err != nil {
+			return err
+		}
+
+		root = r
+		s.GenesisValidatorsRoot = root[:]
+
+		return nil
+	})
+	require.NoError(t, err)
+
+	data, err := st.MarshalSSZ()
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "genesis.ssz")
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+
+	return path, root
+}
+
+func TestLoadGenesisState(t *testing.T) {
+	cfg := params.MainnetConfig().Copy()
+	path, root := writeTestGenesisState(t, cfg.DenebForkVersion, 1606824023)
+
+	info, err := LoadGenesisState(path, cfg)
+	require.NoError(t, err)
+	assert.Equal(t, root, info.GenesisValidatorsRoot)
+	assert.Equal(t, uint64(1606824023), info.GenesisTime)
+	assert.Equal(t, cfg.DenebForkVersion, info.ForkVersion)
+	assert.Equal(t, 4, info.Validators)
+
+	_, err = LoadGenesisState(filepath.Join(t.TempDir(), "missing.ssz"), cfg)
+	assert.Error(t, err)
+}
+
+func TestDecodeBeaconState(t *testing.T) {
+	cfg := params.MainnetConfig().Copy()
+
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr string
+	}{
+		{
+			name:    "too short",
+			data:    []byte{0x01, 0x02},
+			wantErr: "state is too short",
+		},
+		{
+			name:    "unknown fork version",
+			data:    bytes.Repeat([]byte{0xff}, 128),
+			wantErr: "is not part of network",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := decodeBeaconState(tt.data, cfg)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestLoadNetworkConfigWithGenesisState(t *testing.T) {
+	defer func() {
+		delete(customNetworks, "test-devnet")
+		delete(customGenesisTimes, "test-devnet")
+	}()
+
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte(testDevnetConfig), 0o600))
+
+	// All forks up to Deneb are active at genesis in the test devnet
+	statePath, root := writeTestGenesisState(t, []byte{0x50, 0x00, 0x00, 0x38}, 1700000123)
+
+	name, err := LoadNetworkConfig(configPath, "", statePath)
+	require.NoError(t, err)
+
+	config, err := NewBeaconConfigFromNetwork(name)
+	require.NoError(t, err)
+	assert.Equal(t, "0x"+hex.EncodeToString(root[:]), config.GenesisValidatorsRoot)
+
+	cfg, err := networkConfig(name)
+	require.NoError(t, err)
+
+	genesis, ok := networkGenesisTime(name, cfg)
+	require.True(t, ok)
+	assert.Equal(t, uint64(1700000123), genesis)
+
+	// A conflicting root is rejected
+	_, err = LoadNetworkConfig(configPath, "0x"+strings.Repeat("ab", 32), statePath)
+	assert.Error(t, err)
+
+	// A genesis state from a later fork does not match the network's genesis
+	statePath, _ = writeTestGenesisState(t, []byte{0x60, 0x00, 0x00, 0x38}, 1700000123)
+	_, err = LoadNetworkConfig(configPath, "", statePath)
+	assert.Error(t, err)
+}
@@ -0,0 +1,121 @@
+package validator
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// HookEvent identifies the point in a generation run at which a hook is executed
+type HookEvent string
+
+const (
+	HookBeforeRun     HookEvent = "before_run"
+	HookAfterKeystore HookEvent = "after_keystore"
+	HookAfterRun      HookEvent = "after_run"
+	HookOnFailure     HookEvent = "on_failure"
+)
+
+// HookFailurePolicy controls what happens when a hook command fails
+type HookFailurePolicy string
+
+const (
+	HookFailureAbort HookFailurePolicy = "abort"
+	HookFailureWarn  HookFailurePolicy = "warn"
+)
+
+// Hooks holds the shell commands to run around exit generation
+type Hooks struct {
+	Commands      map[HookEvent]string
+	FailurePolicy HookFailurePolicy
+}
+
+// HookContext is passed to hook commands as JSON on stdin. TotalKeystores is
+// omitted until the keystores have been listed, so before_run never has it.
+type HookContext struct {
+	Event          HookEvent `json:"event"`
+	Keystore       string    `json:"keystore,omitempty"`
+	Pubkey         string    `json:"pubkey,omitempty"`
+	KeystoreNumber int       `json:"keystore_number,omitempty"`
+	TotalKeystores int       `json:"total_keystores,omitempty"`
+	Count          int       `json:"count"`
+	StartIndex     int       `json:"start_index"`
+	OutputDir      string    `json:"output_dir"`
+	Error          string    `json:"error,omitempty"`
+}
+
+var hookCommand = func(command string, stdin io.Reader) commander {
+	cmd := exec.Command("sh", "-c", command)
+	cmd.Stdin = stdin
+
+	return cmd
+}
+
+// NewHooks creates a Hooks instance, validating the failure policy
+func NewHooks(commands map[HookEvent]string, policy string) (*Hooks, error) {
+	switch HookFailurePolicy(policy) {
+	case HookFailureAbort, HookFailureWarn:
+	default:
+		return nil, fmt.Errorf("unknown hook failure policy: %s", policy)
+	}
+
+	cmds := make(map[HookEvent]string, len(commands))
+
+	for event, command := range commands {
+		if strings.TrimSpace(command) != "" {
+			cmds[event] = command
+		}
+	}
+
+	return &Hooks{
+		Commands:      cmds,
+		FailurePolicy: HookFailurePolicy(policy),
+	}, nil
+}
+
+// Run executes the hook registered for hookCtx.Event, if any. Failures are
+// returned only when the failure policy is abort; on_failure hook errors are
+// always just logged since the run is already failing.
+func (h *Hooks) Run(hookCtx *HookContext) error {
+	if h == nil {
+		return nil
+	}
+
+	command, ok := h.Commands[hookCtx.Event]
+	if !ok {
+		return nil
+	}
+
+	hookLog := log.WithField("hook", hookCtx.Event)
+
+	input, err := json.Marshal(hookCtx)
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal context for %s hook", hookCtx.Event)
+	}
+
+	hookLog.Debug("Running hook")
+
+	output, err := hookCommand(command, bytes.NewReader(input)).CombinedOutput()
+	if len(output) > 0 {
+		hookLog.Infof("Hook output: %s", strings.TrimSpace(string(output)))
+	}
+
+	if err == nil {
+		return nil
+	}
+
+	if hookCtx.Event == HookOnFailure || h.FailurePolicy == HookFailureWarn {
+		hookLog.WithError(err).Warn("Hook failed")
+
+		return nil
+	}
+
+	hookLog.WithError(err).Error("Hook failed")
+
+	return errors.Wrapf(err, "%s hook failed", hookCtx.Event)
+}
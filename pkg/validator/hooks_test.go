@@ -0,0 +1,157 @@
+package validator
+
+import (
+	"encoding/json"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewHooks(t *testing.T) {
+	hooks, err := NewHooks(map[HookEvent]string{
+		HookBeforeRun: "echo before",
+		HookAfterRun:  "  ",
+	}, "warn")
+	require.NoError(t, err)
+	assert.Equal(t, HookFailureWarn, hooks.FailurePolicy)
+	assert.Equal(t, map[HookEvent]string{HookBeforeRun: "echo before"}, hooks.Commands)
+
+	_, err = NewHooks(nil, "ignore")
+	assert.Error(t, err)
+}
+
+func TestHooksRun(t *testing.T) {
+	tests := []struct {
+		name        string
+		policy      HookFailurePolicy
+		event       HookEvent
+		shouldFail  bool
+		expectError bool
+	}{
+		{
+			name:   "successful hook",
+			policy: HookFailureAbort,
+			event:  HookAfterKeystore,
+		},
+		{
+			name:        "failing hook aborts",
+			policy:      HookFailureAbort,
+			event:       HookAfterKeystore,
+			shouldFail:  true,
+			expectError: true,
+		},
+		{
+			name:       "failing hook warns",
+			policy:     HookFailureWarn,
+			event:      HookAfterKeystore,
+			shouldFail: true,
+		},
+		{
+			name:       "failing on_failure hook never aborts",
+			policy:     HookFailureAbort,
+			event:      HookOnFailure,
+			shouldFail: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				gotCommand string
+				gotInput   []byte
+			)
+
+			origHookCommand := hookCommand
+			hookCommand = func(command string, stdin io.Reader) commander {
+				gotCommand = command
+
+				var err error
+				gotInput, err = io.ReadAll(stdin)
+				require.NoError(t, err)
+
+				return &mockCmd{
+					t:          t,
+					shouldFail: tt.shouldFail,
+					output:     []byte("done"),
+				}
+			}
+
+			defer func() { hookCommand = origHookCommand }()
+
+			hooks := &Hooks{
+				Commands:      map[HookEvent]string{tt.event: "./hook.sh"},
+				FailurePolicy: tt.policy,
+			}
+
+			err := hooks.Run(&HookContext{
+				Event:          tt.event,
+				Keystore:       "/keys/keystore-0.json",
+				Pubkey:         "0xabc",
+				KeystoreNumber: 1,
+				TotalKeystores: 2,
+				Count:          10,
+				StartIndex:     100,
+				OutputDir:      "/out",
+			})
+
+			if tt.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.Equal(t, "./hook.sh", gotCommand)
+
+			var hookCtx HookContext
+			require.NoError(t, json.Unmarshal(gotInput, &hookCtx))
+			assert.Equal(t, tt.event, hookCtx.Event)
+			assert.Equal(t, "0xabc", hookCtx.Pubkey)
+			assert.Equal(t, 100, hookCtx.StartIndex)
+			assert.Equal(t, "/out", hookCtx.OutputDir)
+		})
+	}
+}
+
+func TestHookContextTotalKeystores(t *testing.T) {
+	// before_run runs before the keystores are listed
+	data, err := json.Marshal(&HookContext{Event: HookBeforeRun, Count: 10, OutputDir: "/out"})
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "total_keystores")
+
+	data, err = json.Marshal(&HookContext{Event: HookAfterRun, TotalKeystores: 2, Count: 10, OutputDir: "/out"})
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"total_keystores":2`)
+}
+
+func TestHooksRunWithoutCommand(t *testing.T) {
+	origHookCommand := hookCommand
+	hookCommand = func(command string, stdin io.Reader) commander {
+		t.Fatal("hook command should not be executed")
+
+		return nil
+	}
+
+	defer func() { hookCommand = origHookCommand }()
+
+	var nilHooks *Hooks
+	assert.NoError(t, nilHooks.Run(&HookContext{Event: HookBeforeRun}))
+
+	hooks := &Hooks{Commands: map[HookEvent]string{}, FailurePolicy: HookFailureAbort}
+	assert.NoError(t, hooks.Run(&HookContext{Event: HookBeforeRun}))
+}
+
+func TestHooksRunShell(t *testing.T) {
+	hooks := &Hooks{
+		Commands: map[HookEvent]string{
+			HookAfterRun: `grep -q '"event":"after_run"'`,
+		},
+		FailurePolicy: HookFailureAbort,
+	}
+
+	assert.NoError(t, hooks.Run(&HookContext{Event: HookAfterRun}))
+
+	hooks.Commands[HookAfterRun] = "exit 3"
+	assert.Error(t, hooks.Run(&HookContext{Event: HookAfterRun}))
+}
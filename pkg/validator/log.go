@@ -2,6 +2,8 @@ package validator
 
 import (
 	"github.com/sirupsen/logrus"
+
+	"github.com/ethpandaops/validator-tools/pkg/beacon"
 )
 
 var log *logrus.Logger
@@ -10,6 +12,8 @@ func init() {
 	log = logrus.New()
 
 	log.SetLevel(logrus.InfoLevel)
+
+	beacon.SetLogger(log)
 }
 
 // GetLogger returns the configured logger instance
@@ -0,0 +1,103 @@
+package validator
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/ethpandaops/validator-tools/pkg/atomicfile"
+)
+
+// ManifestFileName is the name of the provenance manifest written next to generated exits
+const ManifestFileName = "manifest.json"
+
+// VersionInfo identifies the build of validator-tools that produced a manifest
+type VersionInfo struct {
+	Release   string `json:"release"`
+	GitCommit string `json:"git_commit"`
+	OS        string `json:"os"`
+	Arch      string `json:"arch"`
+}
+
+// Manifest records the inputs that produced a set of generated voluntary exits.
+// StartIndex is the validator index generation started from: exits are
+// generated for the Count indices after it.
+type Manifest struct {
+	CreatedAt             time.Time     `json:"created_at"`
+	ToolVersion           VersionInfo   `json:"tool_version"`
+	EthdoVersion          string        `json:"ethdo_version"`
+	BeaconConfig          *BeaconConfig `json:"beacon_config"`
+	WithdrawalCredentials string        `json:"withdrawal_credentials"`
+	StartIndex            int           `json:"start_index"`
+	Count                 int           `json:"count"`
+	Pubkeys               []string      `json:"pubkeys"`
+}
+
+// WriteManifest atomically writes the manifest to dir/manifest.json
+func WriteManifest(dir string, m *Manifest) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal manifest")
+	}
+
+	if err := atomicfile.WriteFile(filepath.Join(dir, ManifestFileName), data, 0o644); err != nil {
+		return errors.Wrap(err, "failed to write manifest")
+	}
+
+	return nil
+}
+
+// LoadManifest reads a manifest written by WriteManifest
+func LoadManifest(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read manifest")
+	}
+
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, errors.Wrap(err, "failed to parse manifest")
+	}
+
+	if m.BeaconConfig == nil {
+		return nil, errors.New("manifest has no beacon config")
+	}
+
+	if len(m.Pubkeys) == 0 {
+		return nil, errors.New("manifest has no pubkeys")
+	}
+
+	return &m, nil
+}
+
+// EarlierEphemeryIteration reports, with a warning, whether the manifest was
+// written for an Ephemery iteration other than the loaded one
+func (m *Manifest) EarlierEphemeryIteration() bool {
+	return isEarlierEphemeryIteration(m.BeaconConfig)
+}
+
+// Ephemery reports whether the manifest was written for an Ephemery
+// iteration, by its genesis fork version. Its network is only known once the
+// current iteration has been loaded with LoadEphemeryConfig.
+func (m *Manifest) Ephemery() bool {
+	return isEphemeryConfig(m.BeaconConfig)
+}
+
+// Network returns the name of the known network matching the manifest's genesis
+// validators root. Manifests from an earlier Ephemery iteration resolve to
+// ephemery with a warning.
+func (m *Manifest) Network() (string, error) {
+	if m.Ephemery() && !EphemeryLoaded() {
+		return "", errors.New("manifest was written for Ephemery, but the current Ephemery iteration has not been loaded, use --network ephemery")
+	}
+
+	network, err := networkByGenesisValidatorsRoot(m.BeaconConfig.GenesisValidatorsRoot)
+	if err != nil && isEarlierEphemeryIteration(m.BeaconConfig) {
+		return EphemeryNetwork, nil
+	}
+
+	return network, err
+}
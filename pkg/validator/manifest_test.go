@@ -0,0 +1,101 @@
+package validator
+
+import (
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/prysmaticlabs/prysm/v5/config/params"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManifestRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	root := params.HoodiConfig().GenesisValidatorsRoot
+
+	manifest := &Manifest{
+		CreatedAt: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+		ToolVersion: VersionInfo{
+			Release:   "v1.2.3",
+			GitCommit: "abcdef",
+			OS:        "linux",
+			Arch:      "amd64",
+		},
+		EthdoVersion: "1.37.0",
+		BeaconConfig: &BeaconConfig{
+			GenesisValidatorsRoot: "0x" + hex.EncodeToString(root[:]),
+			Epoch:                 "0",
+		},
+		WithdrawalCredentials: "0x0100000000000000000000000123456789abcdef0123456789abcdef01234567",
+		StartIndex:            1000,
+		Count:                 50,
+		Pubkeys:               []string{"0x" + testPubkeyHex},
+	}
+
+	require.NoError(t, WriteManifest(dir, manifest))
+
+	loaded, err := LoadManifest(filepath.Join(dir, ManifestFileName))
+	require.NoError(t, err)
+	assert.Equal(t, manifest, loaded)
+
+	network, err := loaded.Network()
+	require.NoError(t, err)
+	assert.Equal(t, "hoodi", network)
+}
+
+func TestLoadManifestErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{
+			name:    "invalid json",
+			content: `{ invalid`,
+		},
+		{
+			name:    "missing beacon config",
+			content: `{"pubkeys": ["0xabc"]}`,
+		},
+		{
+			name:    "missing pubkeys",
+			content: `{"beacon_config": {"genesis_validators_root": "0x00"}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), ManifestFileName)
+			require.NoError(t, os.WriteFile(path, []byte(tt.content), 0o600))
+
+			_, err := LoadManifest(path)
+			assert.Error(t, err)
+		})
+	}
+
+	_, err := LoadManifest(filepath.Join(t.TempDir(), "missing.json"))
+	assert.Error(t, err)
+}
+
+func TestManifestUnknownNetwork(t *testing.T) {
+	manifest := &Manifest{
+		BeaconConfig: &BeaconConfig{
+			GenesisValidatorsRoot: "0x" + testPubkeyHex,
+		},
+	}
+
+	_, err := manifest.Network()
+	assert.Error(t, err)
+}
+
+func TestManifestIncludedInChecksums(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "1-abc.json"), []byte(`{}`), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ManifestFileName), []byte(`{}`), 0o600))
+
+	files, err := checksumTargets(dir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1-abc.json", ManifestFileName}, files)
+}
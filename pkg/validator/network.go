@@ -0,0 +1,346 @@
+package validator
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/prysm/v5/config/params"
+	"github.com/prysmaticlabs/prysm/v5/consensus-types/primitives"
+	"github.com/prysmaticlabs/prysm/v5/runtime/version"
+	"github.com/sirupsen/logrus"
+)
+
+// knownNetworks lists the networks accepted by setNetwork
+var knownNetworks = []string{"mainnet", "holesky", "hoodi", "sepolia", "gnosis", "chiado"}
+
+// networkGenesisTimes holds the genesis time of each known network, used to
+// derive the current fork without a beacon node
+var networkGenesisTimes = map[string]uint64{
+	"mainnet": 1606824023,
+	"holesky": 1695902400,
+	"hoodi":   1742213400,
+	"sepolia": 1655733600,
+	"gnosis":  1638993340,
+	"chiado":  1665396300,
+}
+
+// forkEpochUpdates holds the Electra and Fulu fork epochs that were scheduled
+// after the bundled prysm release froze its network presets
+var forkEpochUpdates = map[string]struct {
+	electra primitives.Epoch
+	fulu    primitives.Epoch
+}{
+	"mainnet": {electra: 364032, fulu: 411392},
+	"holesky": {electra: 115968, fulu: 165120},
+	"sepolia": {electra: 222464, fulu: 272640},
+	"hoodi":   {electra: 2048, fulu: 50688},
+}
+
+// customNetworks holds networks loaded from consensus config files, keyed by CONFIG_NAME
+var customNetworks = map[string]*params.BeaconChainConfig{}
+
+// customGenesisTimes holds the genesis time of custom networks loaded together with a genesis state
+var customGenesisTimes = map[string]uint64{}
+
+// LoadNetworkConfig loads a consensus-specs config.yaml so that its CONFIG_NAME
+// can be used wherever a network name is accepted. genesisValidatorsRoot is
+// optional and overrides the root used for signing domains. genesisStatePath is
+// also optional; when set, the genesis validators root and genesis time are
+// derived from the SSZ genesis state instead. It returns the name the network
+// was registered under. Names of built-in networks are rejected, so that a
+// config can't silently replace their presets.
+func LoadNetworkConfig(path, genesisValidatorsRoot, genesisStatePath string) (string, error) {
+	cfg, err := params.UnmarshalConfigFile(path, nil)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to load network config")
+	}
+
+	if slices.Contains(knownNetworks, cfg.ConfigName) || cfg.ConfigName == EphemeryNetwork {
+		return "", fmt.Errorf("network config %s has the CONFIG_NAME of built-in network %s, use a different name for custom networks",
+			path, cfg.ConfigName)
+	}
+
+	if err := registerNetwork(cfg, genesisValidatorsRoot, genesisStatePath); err != nil {
+		return "", err
+	}
+
+	return cfg.ConfigName, nil
+}
+
+// registerNetwork applies the genesis validators root to a config loaded from
+// config.yaml and registers it under its CONFIG_NAME
+func registerNetwork(cfg *params.BeaconChainConfig, genesisValidatorsRoot, genesisStatePath string) error {
+	// config.yaml does not carry the genesis validators root, don't inherit mainnet's
+	cfg.GenesisValidatorsRoot = [32]byte{}
+
+	if genesisValidatorsRoot != "" {
+		root, err := hex.DecodeString(strings.TrimPrefix(genesisValidatorsRoot, "0x"))
+		if err != nil || len(root) != 32 {
+			return fmt.Errorf("invalid genesis validators root: %s", genesisValidatorsRoot)
+		}
+
+		copy(cfg.GenesisValidatorsRoot[:], root)
+	}
+
+	delete(customGenesisTimes, cfg.ConfigName)
+
+	if genesisStatePath != "" {
+		if err := applyGenesisState(cfg, genesisStatePath); err != nil {
+			return err
+		}
+	}
+
+	if cfg.GenesisValidatorsRoot == [32]byte{} {
+		log.WithField("network", cfg.ConfigName).Warn("No genesis validators root set for network, exit signatures will not verify")
+	}
+
+	customNetworks[cfg.ConfigName] = cfg
+
+	log.WithFields(logrus.Fields{
+		"network":                 cfg.ConfigName,
+		"genesis_fork_version":    "0x" + hex.EncodeToString(cfg.GenesisForkVersion),
+		"genesis_validators_root": "0x" + hex.EncodeToString(cfg.GenesisValidatorsRoot[:]),
+	}).Info("Loaded custom network config")
+
+	return nil
+}
+
+// applyGenesisState sets the genesis validators root and genesis time of cfg
+// from a genesis state, checking them against any root already configured
+func applyGenesisState(cfg *params.BeaconChainConfig, path string) error {
+	info, err := LoadGenesisState(path, cfg)
+	if err != nil {
+		return err
+	}
+
+	if !bytes.Equal(info.ForkVersion, forkVersionAtEpoch(cfg, 0)) {
+		return fmt.Errorf("genesis state fork version 0x%x does not match the genesis fork of network %s (0x%x)",
+			info.ForkVersion, cfg.ConfigName, forkVersionAtEpoch(cfg, 0))
+	}
+
+	if cfg.GenesisValidatorsRoot != [32]byte{} && cfg.GenesisValidatorsRoot != info.GenesisValidatorsRoot {
+		return fmt.Errorf("genesis validators root 0x%x does not match genesis state (0x%x)",
+			cfg.GenesisValidatorsRoot, info.GenesisValidatorsRoot)
+	}
+
+	cfg.GenesisValidatorsRoot = info.GenesisValidatorsRoot
+	customGenesisTimes[cfg.ConfigName] = info.GenesisTime
+
+	return nil
+}
+
+// networkNames returns the names of all known and loaded custom networks
+func networkNames() []string {
+	names := append([]string{}, knownNetworks...)
+
+	custom := make([]string, 0, len(customNetworks))
+	for name := range customNetworks {
+		custom = append(custom, name)
+	}
+
+	sort.Strings(custom)
+
+	return append(names, custom...)
+}
+
+// networkConfig returns the beacon chain config for a known or loaded custom network
+func networkConfig(network string) (*params.BeaconChainConfig, error) {
+	if cfg, ok := customNetworks[network]; ok {
+		return cfg.Copy(), nil
+	}
+
+	switch network {
+	case "mainnet":
+		return withForkEpochUpdates(network, params.MainnetConfig().Copy()), nil
+	case "holesky":
+		return withForkEpochUpdates(network, params.HoleskyConfig()), nil
+	case "hoodi":
+		return withForkEpochUpdates(network, params.HoodiConfig()), nil
+	case "sepolia":
+		return withForkEpochUpdates(network, params.SepoliaConfig()), nil
+	case "gnosis":
+		return gnosisConfig(), nil
+	case "chiado":
+		return chiadoConfig(), nil
+	case EphemeryNetwork:
+		// Registered in customNetworks once loaded
+		return nil, errors.New("the current Ephemery iteration has not been loaded, use --network ephemery")
+	default:
+		return nil, fmt.Errorf("unknown network: %s", network)
+	}
+}
+
+// withForkEpochUpdates applies forkEpochUpdates to a prysm network preset
+func withForkEpochUpdates(network string, cfg *params.BeaconChainConfig) *params.BeaconChainConfig {
+	update, ok := forkEpochUpdates[network]
+	if !ok {
+		return cfg
+	}
+
+	cfg.ElectraForkEpoch = update.electra
+	cfg.FuluForkEpoch = update.fulu
+	cfg.InitializeForkSchedule()
+
+	return cfg
+}
+
+// setNetwork configures the network parameters
+func setNetwork(network string) error {
+	cfg, err := networkConfig(network)
+	if err != nil {
+		return err
+	}
+
+	params.OverrideBeaconConfig(cfg)
+
+	return nil
+}
+
+// networkByGenesisValidatorsRoot returns the known network with the given genesis validators root
+func networkByGenesisValidatorsRoot(root string) (string, error) {
+	for _, network := range networkNames() {
+		cfg, err := networkConfig(network)
+		if err != nil {
+			return "", err
+		}
+
+		if strings.EqualFold(strings.TrimPrefix(root, "0x"), hex.EncodeToString(cfg.GenesisValidatorsRoot[:])) {
+			return network, nil
+		}
+	}
+
+	return "", fmt.Errorf("no known network with genesis validators root %s", root)
+}
+
+// NewBeaconConfigFromNetwork builds the BeaconConfig for a known network from
+// its built-in presets, without contacting a beacon node
+func NewBeaconConfigFromNetwork(network string) (*BeaconConfig, error) {
+	config, err := beaconConfigFromNetwork(network)
+	if err != nil {
+		return nil, err
+	}
+
+	log.WithField("network", network).Info("Built beacon configuration from network presets")
+	log.Infof("Genesis validators root: %s", config.GenesisValidatorsRoot)
+	log.Infof("Exit fork version: %s", config.ExitForkVersion)
+	log.Infof("Current fork version: %s", config.CurrentForkVersion)
+
+	return config, nil
+}
+
+// beaconConfigFromNetwork builds the BeaconConfig of a network from its presets
+func beaconConfigFromNetwork(network string) (*BeaconConfig, error) {
+	cfg, err := networkConfig(network)
+	if err != nil {
+		return nil, err
+	}
+
+	currentEpoch := networkCurrentEpoch(network, cfg, time.Now())
+
+	return &BeaconConfig{
+		GenesisValidatorsRoot:      "0x" + hex.EncodeToString(cfg.GenesisValidatorsRoot[:]),
+		GenesisVersion:             "0x" + hex.EncodeToString(cfg.GenesisForkVersion),
+		ExitForkVersion:            "0x" + hex.EncodeToString(cfg.CapellaForkVersion),
+		CurrentForkVersion:         "0x" + hex.EncodeToString(forkVersionAtEpoch(cfg, currentEpoch)),
+		Epoch:                      strconv.FormatUint(exitEpoch(uint64(cfg.CapellaForkEpoch), uint64(cfg.MinValidatorWithdrawabilityDelay)), 10),
+		BlsToExecutionChangeDomain: "0x" + hex.EncodeToString(cfg.DomainBLSToExecutionChange[:]),
+		VoluntaryExitDomain:        "0x" + hex.EncodeToString(cfg.DomainVoluntaryExit[:]),
+	}, nil
+}
+
+// exitEpoch returns the epoch used for generated exits: the Capella fork
+// epoch, unless MIN_VALIDATOR_WITHDRAWABILITY_DELAY is later. The two are
+// compared as decimal strings, as exits have always been generated, so that
+// regenerated sets match earlier ones. This gives mainnet and chiado epoch
+// 256 rather than their Capella fork epoch; both are valid exit epochs.
+func exitEpoch(capellaForkEpoch, minValidatorWithdrawabilityDelay uint64) uint64 {
+	if strconv.FormatUint(capellaForkEpoch, 10) < strconv.FormatUint(minValidatorWithdrawabilityDelay, 10) {
+		return minValidatorWithdrawabilityDelay
+	}
+
+	return capellaForkEpoch
+}
+
+// networkGenesisTime returns the genesis time of a network. Custom networks use
+// the genesis state's time when one was loaded, otherwise they are assumed to
+// have launched at MIN_GENESIS_TIME + GENESIS_DELAY, as devnets do.
+func networkGenesisTime(network string, cfg *params.BeaconChainConfig) (uint64, bool) {
+	if genesis, ok := networkGenesisTimes[network]; ok {
+		return genesis, true
+	}
+
+	if genesis, ok := customGenesisTimes[network]; ok {
+		return genesis, true
+	}
+
+	if _, ok := customNetworks[network]; ok {
+		return cfg.MinGenesisTime + cfg.GenesisDelay, true
+	}
+
+	return 0, false
+}
+
+// networkCurrentEpoch derives the current epoch of a network from its genesis time
+func networkCurrentEpoch(network string, cfg *params.BeaconChainConfig, now time.Time) primitives.Epoch {
+	genesis, ok := networkGenesisTime(network, cfg)
+	if !ok || now.Unix() < int64(genesis) {
+		return 0
+	}
+
+	epochSeconds := cfg.SecondsPerSlot * uint64(cfg.SlotsPerEpoch)
+	if epochSeconds == 0 {
+		return 0
+	}
+
+	return primitives.Epoch((uint64(now.Unix()) - genesis) / epochSeconds)
+}
+
+// scheduledFork is a fork in a network's fork schedule
+type scheduledFork struct {
+	version     int
+	epoch       primitives.Epoch
+	forkVersion []byte
+}
+
+// forkSchedule returns the forks of a network in activation order
+func forkSchedule(cfg *params.BeaconChainConfig) []scheduledFork {
+	return []scheduledFork{
+		{version.Phase0, 0, cfg.GenesisForkVersion},
+		{version.Altair, cfg.AltairForkEpoch, cfg.AltairForkVersion},
+		{version.Bellatrix, cfg.BellatrixForkEpoch, cfg.BellatrixForkVersion},
+		{version.Capella, cfg.CapellaForkEpoch, cfg.CapellaForkVersion},
+		{version.Deneb, cfg.DenebForkEpoch, cfg.DenebForkVersion},
+		{version.Electra, cfg.ElectraForkEpoch, cfg.ElectraForkVersion},
+		{version.Fulu, cfg.FuluForkEpoch, cfg.FuluForkVersion},
+	}
+}
+
+// forkAtEpoch returns the fork active at the given epoch and the fork before it
+func forkAtEpoch(cfg *params.BeaconChainConfig, epoch primitives.Epoch) (current, previous scheduledFork) {
+	schedule := forkSchedule(cfg)
+	current, previous = schedule[0], schedule[0]
+
+	for _, fork := range schedule[1:] {
+		if fork.epoch > epoch || fork.epoch == cfg.FarFutureEpoch {
+			break
+		}
+
+		previous, current = current, fork
+	}
+
+	return current, previous
+}
+
+// forkVersionAtEpoch returns the fork version active at the given epoch
+func forkVersionAtEpoch(cfg *params.BeaconChainConfig, epoch primitives.Epoch) []byte {
+	current, _ := forkAtEpoch(cfg, epoch)
+
+	return current.forkVersion
+}
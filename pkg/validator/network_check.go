@@ -0,0 +1,227 @@
+package validator
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/prysm/v5/config/params"
+	"github.com/sirupsen/logrus"
+
+	"github.com/ethpandaops/validator-tools/pkg/beacon"
+)
+
+// CheckNetwork compares a beacon config, typically fetched from a beacon node,
+// against the constants of the expected network. All mismatches are returned
+// in a single error.
+func (c *BeaconConfig) CheckNetwork(network string) error {
+	expected, err := beaconConfigFromNetwork(network)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := networkConfig(network)
+	if err != nil {
+		return err
+	}
+
+	checks := []struct {
+		name     string
+		expected string
+		actual   string
+	}{
+		{"genesis validators root", expected.GenesisValidatorsRoot, c.GenesisValidatorsRoot},
+		{"genesis fork version", expected.GenesisVersion, c.GenesisVersion},
+		{"exit fork version", expected.ExitForkVersion, c.ExitForkVersion},
+		{"exit epoch", expected.Epoch, c.Epoch},
+		{"voluntary exit domain", expected.VoluntaryExitDomain, c.VoluntaryExitDomain},
+		{"BLS to execution change domain", expected.BlsToExecutionChangeDomain, c.BlsToExecutionChangeDomain},
+	}
+
+	var mismatches []string
+
+	for _, check := range checks {
+		if normalizeSpecValue(check.expected) != normalizeSpecValue(check.actual) {
+			mismatches = append(mismatches, fmt.Sprintf("%s: expected %s, got %s", check.name, check.expected, check.actual))
+		}
+	}
+
+	// The node may be behind or ahead of our fork schedule, but its current
+	// fork must be one of the network's
+	currentKnown := false
+
+	for _, fork := range forkSchedule(cfg) {
+		if normalizeSpecValue(c.CurrentForkVersion) == "0x"+hex.EncodeToString(fork.forkVersion) {
+			currentKnown = true
+		}
+	}
+
+	if !currentKnown {
+		mismatches = append(mismatches, fmt.Sprintf("current fork version %s is not a fork version of %s", c.CurrentForkVersion, network))
+	}
+
+	if len(mismatches) > 0 {
+		for _, mismatch := range mismatches {
+			log.WithField("network", network).Error(mismatch)
+		}
+
+		return errors.Errorf("beacon node does not match network %s: %s", network, strings.Join(mismatches, "; "))
+	}
+
+	log.WithField("network", network).Info("Beacon node configuration matches expected network")
+
+	return nil
+}
+
+// CheckBeaconNetwork fetches the beacon config from a beacon node and checks it
+// against the constants of the expected network. On a mismatch the full spec
+// diff is logged as well.
+func CheckBeaconNetwork(ctx context.Context, client beacon.Client, network string) error {
+	config, err := FetchBeaconConfig(ctx, client)
+	if err != nil {
+		return errors.Wrap(err, "failed to fetch beacon configuration")
+	}
+
+	if err := config.CheckNetwork(network); err != nil {
+		// Log the full spec diff to help work out which network the node is on
+		if diff, diffErr := FetchSpecDiff(ctx, client, network); diffErr == nil {
+			diff.Log()
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// SpecDifference is a config value that differs between a beacon node and the expected network
+type SpecDifference struct {
+	Key      string `json:"key"`
+	Expected string `json:"expected"`
+	Actual   string `json:"actual"`
+}
+
+// SpecDiff compares a beacon node's /eth/v1/config/spec with the prysm params of a network
+type SpecDiff struct {
+	Network        string           `json:"network"`
+	Mismatched     []SpecDifference `json:"mismatched"`
+	MissingOnNode  []string         `json:"missing_on_node"`
+	UnknownToPrysm []string         `json:"unknown_to_prysm"`
+}
+
+// FetchSpecDiff fetches /eth/v1/config/spec from a beacon node and diffs it
+// against the prysm params of the expected network
+func FetchSpecDiff(ctx context.Context, client beacon.Client, network string) (*SpecDiff, error) {
+	spec, err := client.Spec(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return DiffSpec(spec, network)
+}
+
+// DiffSpec diffs spec values, as returned by /eth/v1/config/spec, against the
+// prysm params of the expected network
+func DiffSpec(spec beacon.Spec, network string) (*SpecDiff, error) {
+	cfg, err := networkConfig(network)
+	if err != nil {
+		return nil, err
+	}
+
+	expected := expectedSpec(cfg)
+	diff := &SpecDiff{
+		Network:        network,
+		Mismatched:     []SpecDifference{},
+		MissingOnNode:  []string{},
+		UnknownToPrysm: []string{},
+	}
+
+	for key, raw := range spec {
+		actual := specValue(raw)
+
+		want, ok := expected[key]
+		if !ok {
+			diff.UnknownToPrysm = append(diff.UnknownToPrysm, key)
+
+			continue
+		}
+
+		if normalizeSpecValue(want) != normalizeSpecValue(actual) {
+			diff.Mismatched = append(diff.Mismatched, SpecDifference{Key: key, Expected: want, Actual: actual})
+		}
+	}
+
+	for key := range expected {
+		if _, ok := spec[key]; !ok {
+			diff.MissingOnNode = append(diff.MissingOnNode, key)
+		}
+	}
+
+	sort.Slice(diff.Mismatched, func(i, j int) bool { return diff.Mismatched[i].Key < diff.Mismatched[j].Key })
+	sort.Strings(diff.MissingOnNode)
+	sort.Strings(diff.UnknownToPrysm)
+
+	return diff, nil
+}
+
+// Log logs every difference in the spec diff
+func (d *SpecDiff) Log() {
+	for _, m := range d.Mismatched {
+		log.WithFields(logrus.Fields{
+			"key":      m.Key,
+			"expected": m.Expected,
+			"actual":   m.Actual,
+		}).Warn("Spec value differs from network params")
+	}
+
+	log.WithFields(logrus.Fields{
+		"network":          d.Network,
+		"mismatched":       len(d.Mismatched),
+		"missing_on_node":  len(d.MissingOnNode),
+		"unknown_to_prysm": len(d.UnknownToPrysm),
+	}).Info("Spec diff complete")
+}
+
+// expectedSpec returns the spec values of a network's prysm params, keyed like /eth/v1/config/spec
+func expectedSpec(cfg *params.BeaconChainConfig) map[string]string {
+	spec := map[string]string{}
+
+	for _, line := range strings.Split(string(params.ConfigToYaml(cfg)), "\n") {
+		// Skip nested values such as the entries of BLOB_SCHEDULE
+		if strings.HasPrefix(line, " ") || strings.HasPrefix(line, "-") {
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+
+		spec[strings.TrimSpace(key)] = strings.Trim(strings.TrimSpace(value), "'\"")
+	}
+
+	// Signing domains are constants and not part of config.yaml
+	spec["DOMAIN_DEPOSIT"] = fmt.Sprintf("%#x", cfg.DomainDeposit)
+	spec["DOMAIN_VOLUNTARY_EXIT"] = fmt.Sprintf("%#x", cfg.DomainVoluntaryExit)
+	spec["DOMAIN_BLS_TO_EXECUTION_CHANGE"] = fmt.Sprintf("%#x", cfg.DomainBLSToExecutionChange)
+
+	return spec
+}
+
+// specValue returns a spec value as a string; non-string values are kept as compact JSON
+func specValue(raw json.RawMessage) string {
+	var value string
+	if err := json.Unmarshal(raw, &value); err == nil {
+		return value
+	}
+
+	return string(raw)
+}
+
+func normalizeSpecValue(value string) string {
+	return strings.ToLower(strings.TrimSpace(value))
+}
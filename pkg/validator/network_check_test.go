@@ -0,0 +1,156 @@
+package validator
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prysmaticlabs/prysm/v5/config/params"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethpandaops/validator-tools/pkg/beacon"
+)
+
+// newNetworkBeaconServer serves the genesis, fork and spec endpoints of a beacon
+// node on the given network, with spec and fork overrides applied
+func newNetworkBeaconServer(t *testing.T, network string, specOverrides map[string]string, currentVersion string) *httptest.Server {
+	t.Helper()
+
+	cfg, err := networkConfig(network)
+	require.NoError(t, err)
+
+	spec := map[string]string{}
+	for key, value := range expectedSpec(cfg) {
+		spec[key] = value
+	}
+
+	for key, value := range specOverrides {
+		spec[key] = value
+	}
+
+	if currentVersion == "" {
+		currentVersion = "0x" + hex.EncodeToString(cfg.FuluForkVersion)
+	}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var data interface{}
+
+		switch r.URL.Path {
+		case "/eth/v1/beacon/genesis":
+			data = map[string]string{
+				"genesis_validators_root": "0x" + hex.EncodeToString(cfg.GenesisValidatorsRoot[:]),
+				"genesis_fork_version":    spec["GENESIS_FORK_VERSION"],
+			}
+		case "/eth/v1/beacon/states/head/fork":
+			data = map[string]string{
+				"previous_version": "0x" + hex.EncodeToString(cfg.ElectraForkVersion),
+				"current_version":  currentVersion,
+			}
+		case "/eth/v1/config/spec":
+			data = spec
+		default:
+			w.WriteHeader(http.StatusNotFound)
+
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		require.NoError(t, json.NewEncoder(w).Encode(map[string]interface{}{"data": data}))
+	}))
+}
+
+func TestCheckBeaconNetwork(t *testing.T) {
+	defer params.OverrideBeaconConfig(params.MainnetConfig())
+
+	tests := []struct {
+		name           string
+		network        string
+		specOverrides  map[string]string
+		currentVersion string
+		expectedError  string
+	}{
+		{
+			name:    "matching node",
+			network: "hoodi",
+		},
+		{
+			name:          "node on another network",
+			network:       "mainnet",
+			expectedError: "genesis validators root",
+		},
+		{
+			name:          "different capella fork version",
+			network:       "hoodi",
+			specOverrides: map[string]string{"CAPELLA_FORK_VERSION": "0x40000911"},
+			expectedError: "exit fork version",
+		},
+		{
+			name:           "unknown current fork version",
+			network:        "hoodi",
+			currentVersion: "0x70000911",
+			expectedError:  "current fork version 0x70000911 is not a fork version of hoodi",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newNetworkBeaconServer(t, "hoodi", tt.specOverrides, tt.currentVersion)
+			defer server.Close()
+
+			client, err := beacon.NewClient(server.URL, nil)
+			require.NoError(t, err)
+
+			err = CheckBeaconNetwork(context.Background(), client, tt.network)
+			if tt.expectedError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError)
+
+				return
+			}
+
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestFetchSpecDiff(t *testing.T) {
+	server := newNetworkBeaconServer(t, "hoodi", map[string]string{"SLOTS_PER_EPOCH": "16", "NEW_FORK_EPOCH": "1"}, "")
+	defer server.Close()
+
+	client, err := beacon.NewClient(server.URL, nil)
+	require.NoError(t, err)
+
+	diff, err := FetchSpecDiff(context.Background(), client, "hoodi")
+	require.NoError(t, err)
+
+	assert.Equal(t, []SpecDifference{{Key: "SLOTS_PER_EPOCH", Expected: "32", Actual: "16"}}, diff.Mismatched)
+	assert.Empty(t, diff.MissingOnNode)
+	assert.Equal(t, []string{"NEW_FORK_EPOCH"}, diff.UnknownToPrysm)
+}
+
+func TestDiffSpec(t *testing.T) {
+	hoodi := params.HoodiConfig()
+
+	spec := beacon.Spec{
+		"CONFIG_NAME":           json.RawMessage(`"hoodi"`),
+		"CAPELLA_FORK_VERSION":  json.RawMessage(`"0x` + hex.EncodeToString(hoodi.CapellaForkVersion) + `"`),
+		"DOMAIN_VOLUNTARY_EXIT": json.RawMessage(`"0x04000000"`),
+		"SECONDS_PER_SLOT":      json.RawMessage(`"6"`),
+		"BLOB_SCHEDULE":         json.RawMessage(`[{"EPOCH":"1","MAX_BLOBS_PER_BLOCK":"9"}]`),
+	}
+
+	diff, err := DiffSpec(spec, "hoodi")
+	require.NoError(t, err)
+
+	assert.Equal(t, []SpecDifference{{Key: "SECONDS_PER_SLOT", Expected: "12", Actual: "6"}}, diff.Mismatched)
+	assert.Contains(t, diff.MissingOnNode, "DOMAIN_DEPOSIT")
+	assert.NotContains(t, diff.MissingOnNode, "CAPELLA_FORK_VERSION")
+	assert.Equal(t, []string{"BLOB_SCHEDULE"}, diff.UnknownToPrysm)
+
+	_, err = DiffSpec(spec, "unknown")
+	require.Error(t, err)
+}
@@ -0,0 +1,79 @@
+package validator
+
+import (
+	"github.com/prysmaticlabs/prysm/v5/config/params"
+)
+
+// gnosisPreset applies the gnosis preset (5 second slots, 16 slots per epoch)
+// on top of the mainnet config. Balances are denominated in mGNO, with 32 mGNO
+// (1 GNO) per validator, so the mainnet Gwei amounts carry over unchanged.
+func gnosisPreset() *params.BeaconChainConfig {
+	cfg := params.MainnetConfig().Copy()
+	cfg.PresetBase = "gnosis"
+	cfg.SecondsPerSlot = 5
+	cfg.SlotsPerEpoch = 16
+	cfg.EpochsPerSyncCommitteePeriod = 512
+	cfg.MaxWithdrawalsPerPayload = 8
+	cfg.BaseRewardFactor = 25
+	cfg.ChurnLimitQuotient = 4096
+
+	return cfg
+}
+
+// gnosisConfig returns the beacon chain config for Gnosis Chain
+func gnosisConfig() *params.BeaconChainConfig {
+	cfg := gnosisPreset()
+	cfg.ConfigName = "gnosis"
+	cfg.DepositChainID = 100
+	cfg.DepositNetworkID = 100
+	cfg.DepositContractAddress = "0x0B98057eA310F4d31F2a452B414647007d1645d9"
+	cfg.GenesisValidatorsRoot = [32]byte{
+		0xf5, 0xdc, 0xb5, 0x56, 0x4e, 0x82, 0x9a, 0xab, 0x27, 0x26, 0x4b, 0x9b, 0xec, 0xd5, 0xdf, 0xaa,
+		0x01, 0x70, 0x85, 0x61, 0x12, 0x24, 0xcb, 0x30, 0x36, 0xf5, 0x73, 0x36, 0x8d, 0xbb, 0x9d, 0x47,
+	}
+	cfg.GenesisForkVersion = []byte{0x00, 0x00, 0x00, 0x64}
+	cfg.AltairForkVersion = []byte{0x01, 0x00, 0x00, 0x64}
+	cfg.AltairForkEpoch = 512
+	cfg.BellatrixForkVersion = []byte{0x02, 0x00, 0x00, 0x64}
+	cfg.BellatrixForkEpoch = 385536
+	cfg.CapellaForkVersion = []byte{0x03, 0x00, 0x00, 0x64}
+	cfg.CapellaForkEpoch = 648704
+	cfg.DenebForkVersion = []byte{0x04, 0x00, 0x00, 0x64}
+	cfg.DenebForkEpoch = 889856
+	cfg.ElectraForkVersion = []byte{0x05, 0x00, 0x00, 0x64}
+	cfg.ElectraForkEpoch = 1337856
+	cfg.FuluForkVersion = []byte{0x06, 0x00, 0x00, 0x64}
+	cfg.FuluForkEpoch = cfg.FarFutureEpoch
+	cfg.InitializeForkSchedule()
+
+	return cfg
+}
+
+// chiadoConfig returns the beacon chain config for the Chiado testnet
+func chiadoConfig() *params.BeaconChainConfig {
+	cfg := gnosisPreset()
+	cfg.ConfigName = "chiado"
+	cfg.DepositChainID = 10200
+	cfg.DepositNetworkID = 10200
+	cfg.DepositContractAddress = "0xb97036A26259B7147018913bD58a774cf91acf25"
+	cfg.GenesisValidatorsRoot = [32]byte{
+		0x9d, 0x64, 0x2d, 0xac, 0x73, 0x05, 0x8f, 0xbf, 0x39, 0xc0, 0xae, 0x41, 0xab, 0x1e, 0x34, 0xe4,
+		0xd8, 0x89, 0x04, 0x3c, 0xb1, 0x99, 0x85, 0x1d, 0xed, 0x70, 0x95, 0xbc, 0x99, 0xeb, 0x4c, 0x1e,
+	}
+	cfg.GenesisForkVersion = []byte{0x00, 0x00, 0x00, 0x6f}
+	cfg.AltairForkVersion = []byte{0x01, 0x00, 0x00, 0x6f}
+	cfg.AltairForkEpoch = 90
+	cfg.BellatrixForkVersion = []byte{0x02, 0x00, 0x00, 0x6f}
+	cfg.BellatrixForkEpoch = 180
+	cfg.CapellaForkVersion = []byte{0x03, 0x00, 0x00, 0x6f}
+	cfg.CapellaForkEpoch = 244224
+	cfg.DenebForkVersion = []byte{0x04, 0x00, 0x00, 0x6f}
+	cfg.DenebForkEpoch = 516608
+	cfg.ElectraForkVersion = []byte{0x05, 0x00, 0x00, 0x6f}
+	cfg.ElectraForkEpoch = 948224
+	cfg.FuluForkVersion = []byte{0x06, 0x00, 0x00, 0x6f}
+	cfg.FuluForkEpoch = cfg.FarFutureEpoch
+	cfg.InitializeForkSchedule()
+
+	return cfg
+}
@@ -0,0 +1,233 @@
+package validator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prysmaticlabs/prysm/v5/config/params"
+	"github.com/prysmaticlabs/prysm/v5/consensus-types/primitives"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewBeaconConfigFromNetwork(t *testing.T) {
+	tests := []struct {
+		name                  string
+		network               string
+		genesisValidatorsRoot string
+		genesisVersion        string
+		exitForkVersion       string
+		epoch                 string
+		expectError           bool
+	}{
+		{
+			name:                  "mainnet",
+			network:               "mainnet",
+			genesisValidatorsRoot: "0x4b363db94e286120d76eb905340fdd4e54bfe9f06bf33ff6cf5ad27f511bfe95",
+			genesisVersion:        "0x00000000",
+			exitForkVersion:       "0x03000000",
+			epoch:                 "256",
+		},
+		{
+			name:                  "holesky",
+			network:               "holesky",
+			genesisValidatorsRoot: "0x9143aa7c615a7f7115e2b6aac319c03529df8242ae705fba9df39b79c59fa8b1",
+			genesisVersion:        "0x01017000",
+			exitForkVersion:       "0x04017000",
+			epoch:                 "256",
+		},
+		{
+			name:                  "hoodi",
+			network:               "hoodi",
+			genesisValidatorsRoot: "0x212f13fc4df078b6cb7db228f1c8307566dcecf900867401a92023d7ba99cb5f",
+			genesisVersion:        "0x10000910",
+			exitForkVersion:       "0x40000910",
+			epoch:                 "256",
+		},
+		{
+			name:                  "sepolia",
+			network:               "sepolia",
+			genesisValidatorsRoot: "0xd8ea171f3c94aea21ebc42a1ed61052acf3f9209c00e4efbaaddac09ed9b8078",
+			genesisVersion:        "0x90000069",
+			exitForkVersion:       "0x90000072",
+			epoch:                 "56832",
+		},
+		{
+			name:                  "gnosis",
+			network:               "gnosis",
+			genesisValidatorsRoot: "0xf5dcb5564e829aab27264b9becd5dfaa017085611224cb3036f573368dbb9d47",
+			genesisVersion:        "0x00000064",
+			exitForkVersion:       "0x03000064",
+			epoch:                 "648704",
+		},
+		{
+			name:                  "chiado",
+			network:               "chiado",
+			genesisValidatorsRoot: "0x9d642dac73058fbf39c0ae41ab1e34e4d889043cb199851ded7095bc99eb4c1e",
+			genesisVersion:        "0x0000006f",
+			exitForkVersion:       "0x0300006f",
+			epoch:                 "256",
+		},
+		{
+			name:        "unknown network",
+			network:     "unknown",
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, err := NewBeaconConfigFromNetwork(tt.network)
+			if tt.expectError {
+				require.Error(t, err)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.NoError(t, config.Validate())
+			assert.Equal(t, tt.genesisValidatorsRoot, config.GenesisValidatorsRoot)
+			assert.Equal(t, tt.genesisVersion, config.GenesisVersion)
+			assert.Equal(t, tt.exitForkVersion, config.ExitForkVersion)
+			assert.Equal(t, tt.epoch, config.Epoch)
+			assert.Equal(t, "0x04000000", config.VoluntaryExitDomain)
+			assert.Equal(t, "0x0a000000", config.BlsToExecutionChangeDomain)
+			assert.NotEqual(t, config.GenesisVersion, config.CurrentForkVersion)
+		})
+	}
+}
+
+func TestExitEpoch(t *testing.T) {
+	assert.Equal(t, uint64(256), exitEpoch(194048, 256))
+	assert.Equal(t, uint64(56832), exitEpoch(56832, 256))
+	assert.Equal(t, uint64(256), exitEpoch(0, 256))
+	assert.Equal(t, uint64(0), exitEpoch(0, 0))
+}
+
+func TestForkVersionAtEpoch(t *testing.T) {
+	cfg := params.MainnetConfig()
+
+	assert.Equal(t, cfg.GenesisForkVersion, forkVersionAtEpoch(cfg, 0))
+	assert.Equal(t, cfg.AltairForkVersion, forkVersionAtEpoch(cfg, cfg.AltairForkEpoch))
+	assert.Equal(t, cfg.BellatrixForkVersion, forkVersionAtEpoch(cfg, cfg.CapellaForkEpoch-1))
+	assert.Equal(t, cfg.CapellaForkVersion, forkVersionAtEpoch(cfg, cfg.CapellaForkEpoch))
+	assert.Equal(t, cfg.DenebForkVersion, forkVersionAtEpoch(cfg, cfg.DenebForkEpoch+1))
+
+	// Unscheduled forks are never active
+	assert.Equal(t, cfg.DenebForkVersion, forkVersionAtEpoch(cfg, cfg.FarFutureEpoch))
+}
+
+func TestForkEpochUpdates(t *testing.T) {
+	cfg, err := networkConfig("mainnet")
+	require.NoError(t, err)
+	assert.Equal(t, primitives.Epoch(364032), cfg.ElectraForkEpoch)
+	assert.Equal(t, cfg.ElectraForkVersion, forkVersionAtEpoch(cfg, 364032))
+
+	// The shared prysm preset is left untouched
+	assert.Equal(t, params.MainnetConfig().FarFutureEpoch, params.MainnetConfig().ElectraForkEpoch)
+}
+
+func TestNetworkCurrentEpoch(t *testing.T) {
+	cfg := params.HoodiConfig()
+	genesis := time.Unix(int64(networkGenesisTimes["hoodi"]), 0)
+
+	assert.Equal(t, primitives.Epoch(0), networkCurrentEpoch("hoodi", cfg, genesis.Add(-time.Hour)))
+	assert.Equal(t, primitives.Epoch(0), networkCurrentEpoch("hoodi", cfg, genesis))
+	assert.Equal(t, primitives.Epoch(10), networkCurrentEpoch("hoodi", cfg, genesis.Add(10*384*time.Second)))
+	assert.Equal(t, primitives.Epoch(0), networkCurrentEpoch("unknown", cfg, genesis.Add(time.Hour)))
+}
+
+func TestGnosisSlotMath(t *testing.T) {
+	for _, network := range []string{"gnosis", "chiado"} {
+		cfg, err := networkConfig(network)
+		require.NoError(t, err)
+
+		assert.Equal(t, primitives.Slot(16), cfg.SlotsPerEpoch)
+		assert.Equal(t, uint64(5), cfg.SecondsPerSlot)
+
+		// 16 slots of 5 seconds make an 80 second epoch
+		genesis := time.Unix(int64(networkGenesisTimes[network]), 0)
+		assert.Equal(t, primitives.Epoch(10), networkCurrentEpoch(network, cfg, genesis.Add(800*time.Second)))
+
+		fork, ok := params.ConfigForkVersions(cfg)[[4]byte(cfg.CapellaForkVersion)]
+		require.True(t, ok)
+		assert.Equal(t, 3, fork)
+	}
+}
+
+func TestNetworkByGenesisValidatorsRoot(t *testing.T) {
+	network, err := networkByGenesisValidatorsRoot("0x4B363DB94E286120D76EB905340FDD4E54BFE9F06BF33FF6CF5AD27F511BFE95")
+	require.NoError(t, err)
+	assert.Equal(t, "mainnet", network)
+
+	_, err = networkByGenesisValidatorsRoot("0x00")
+	assert.Error(t, err)
+}
+
+const testDevnetConfig = `PRESET_BASE: 'mainnet'
+CONFIG_NAME: 'test-devnet'
+MIN_GENESIS_TIME: 1700000000
+GENESIS_DELAY: 60
+GENESIS_FORK_VERSION: 0x10000038
+ALTAIR_FORK_VERSION: 0x20000038
+ALTAIR_FORK_EPOCH: 0
+BELLATRIX_FORK_VERSION: 0x30000038
+BELLATRIX_FORK_EPOCH: 0
+CAPELLA_FORK_VERSION: 0x40000038
+CAPELLA_FORK_EPOCH: 0
+DENEB_FORK_VERSION: 0x50000038
+DENEB_FORK_EPOCH: 0
+ELECTRA_FORK_VERSION: 0x60000038
+ELECTRA_FORK_EPOCH: 10
+FULU_FORK_VERSION: 0x70000038
+FULU_FORK_EPOCH: 18446744073709551615
+`
+
+func TestLoadNetworkConfig(t *testing.T) {
+	defer func() {
+		delete(customNetworks, "test-devnet")
+		params.OverrideBeaconConfig(params.MainnetConfig())
+	}()
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(testDevnetConfig), 0o600))
+
+	root := "0x" + strings.Repeat("ab", 32)
+
+	name, err := LoadNetworkConfig(path, root, "")
+	require.NoError(t, err)
+	assert.Equal(t, "test-devnet", name)
+	assert.Contains(t, networkNames(), "test-devnet")
+
+	require.NoError(t, setNetwork(name))
+	assert.Equal(t, []byte{0x10, 0x00, 0x00, 0x38}, params.BeaconConfig().GenesisForkVersion)
+
+	config, err := NewBeaconConfigFromNetwork(name)
+	require.NoError(t, err)
+	assert.Equal(t, root, config.GenesisValidatorsRoot)
+	assert.Equal(t, "0x10000038", config.GenesisVersion)
+	assert.Equal(t, "0x40000038", config.ExitForkVersion)
+	assert.Equal(t, "0x60000038", config.CurrentForkVersion)
+
+	network, err := networkByGenesisValidatorsRoot(root)
+	require.NoError(t, err)
+	assert.Equal(t, "test-devnet", network)
+
+	_, err = LoadNetworkConfig(path, "0x1234", "")
+	assert.Error(t, err)
+
+	_, err = LoadNetworkConfig(filepath.Join(t.TempDir(), "missing.yaml"), "", "")
+	assert.Error(t, err)
+
+	// Built-in networks can't be replaced
+	for _, builtin := range []string{"mainnet", "hoodi", EphemeryNetwork} {
+		require.NoError(t, os.WriteFile(path, []byte(strings.Replace(testDevnetConfig, "test-devnet", builtin, 1)), 0o600))
+
+		_, err = LoadNetworkConfig(path, root, "")
+		require.ErrorContains(t, err, "built-in network "+builtin)
+		assert.NotContains(t, customNetworks, builtin)
+	}
+}
@@ -0,0 +1,221 @@
+package validator
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	ProgressEventUpdate       = "progress"
+	ProgressEventKeystoreDone = "keystore_complete"
+	ProgressEventComplete     = "complete"
+
+	progressBarWidth = 30
+)
+
+// ProgressEvent is a single progress record, written as newline-delimited JSON
+type ProgressEvent struct {
+	Time              time.Time `json:"time"`
+	Event             string    `json:"event"`
+	Keystore          int32     `json:"keystore"`
+	TotalKeystores    int32     `json:"total_keystores"`
+	KeystoreCompleted uint64    `json:"keystore_completed"`
+	KeystoreTotal     uint64    `json:"keystore_total"`
+	KeystorePercent   float64   `json:"keystore_percent"`
+	OverallCompleted  uint64    `json:"overall_completed"`
+	OverallTotal      uint64    `json:"overall_total"`
+	OverallPercent    float64   `json:"overall_percent"`
+	ExitsPerSecond    float64   `json:"exits_per_second"`
+	ETASeconds        float64   `json:"eta_seconds"`
+}
+
+// Progress tracks exit generation throughput across all keystores. Events are
+// optionally written as JSON lines to an events writer and rendered as a live
+// progress bar to a terminal writer. Rates are measured from Start.
+type Progress struct {
+	mu             sync.Mutex
+	events         *json.Encoder
+	bar            io.Writer
+	drawn          string
+	start          time.Time
+	now            func() time.Time
+	totalKeystores int32
+	iterations     uint64
+}
+
+// NewProgress creates a progress tracker. Either writer may be nil.
+func NewProgress(totalKeystores int32, iterations int, events, bar io.Writer) *Progress {
+	p := &Progress{
+		bar:            bar,
+		now:            time.Now,
+		totalKeystores: totalKeystores,
+		iterations:     uint64(max(iterations, 0)),
+	}
+
+	if events != nil {
+		p.events = json.NewEncoder(events)
+	}
+
+	return p
+}
+
+// Start starts the clock for the rate and ETA, once signing begins. Later
+// calls are ignored so that the rate covers all keystores.
+func (p *Progress) Start() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.start.IsZero() {
+		p.start = p.now()
+	}
+}
+
+// HasBar reports whether a live progress bar is rendered
+func (p *Progress) HasBar() bool {
+	return p.bar != nil
+}
+
+// LogWriter wraps the log output w so that log lines don't run into the
+// progress bar: the bar is cleared before each line and redrawn after it.
+func (p *Progress) LogWriter(w io.Writer) io.Writer {
+	return &progressLogWriter{progress: p, out: w}
+}
+
+type progressLogWriter struct {
+	progress *Progress
+	out      io.Writer
+}
+
+func (w *progressLogWriter) Write(b []byte) (int, error) {
+	p := w.progress
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.drawn == "" {
+		return w.out.Write(b)
+	}
+
+	fmt.Fprint(p.bar, "\r\033[K")
+
+	n, err := w.out.Write(b)
+
+	fmt.Fprint(p.bar, p.drawn)
+
+	return n, err
+}
+
+// Update records the number of exits completed for the current keystore
+func (p *Progress) Update(keystoreNum int32, completed uint64) ProgressEvent {
+	return p.emit(ProgressEventUpdate, keystoreNum, completed)
+}
+
+// KeystoreDone records that all exits for a keystore have been generated
+func (p *Progress) KeystoreDone(keystoreNum int32) ProgressEvent {
+	return p.emit(ProgressEventKeystoreDone, keystoreNum, p.iterations)
+}
+
+// Finish emits a final event and terminates the progress bar line
+func (p *Progress) Finish() ProgressEvent {
+	event := p.emit(ProgressEventComplete, p.totalKeystores, p.iterations)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.bar != nil {
+		fmt.Fprintln(p.bar)
+	}
+
+	p.drawn = ""
+
+	return event
+}
+
+func (p *Progress) emit(kind string, keystoreNum int32, completed uint64) ProgressEvent {
+	p.mu.Lock()
+
+	event := p.snapshot(kind, keystoreNum, completed)
+
+	var encodeErr error
+	if p.events != nil {
+		encodeErr = p.events.Encode(event)
+	}
+
+	if p.bar != nil {
+		p.drawn = renderProgressBar(&event)
+		fmt.Fprint(p.bar, p.drawn)
+	}
+
+	p.mu.Unlock()
+
+	// Logged without the lock, as log output may go through LogWriter
+	if encodeErr != nil {
+		log.WithError(encodeErr).Warn("Failed to write progress event")
+	}
+
+	return event
+}
+
+func (p *Progress) snapshot(kind string, keystoreNum int32, completed uint64) ProgressEvent {
+	now := p.now()
+
+	var finishedBefore uint64
+	if keystoreNum > 1 {
+		finishedBefore = uint64(keystoreNum-1) * p.iterations
+	}
+
+	overallTotal := uint64(max(p.totalKeystores, 0)) * p.iterations
+	overall := finishedBefore + completed
+
+	event := ProgressEvent{
+		Time:              now.UTC(),
+		Event:             kind,
+		Keystore:          keystoreNum,
+		TotalKeystores:    p.totalKeystores,
+		KeystoreCompleted: completed,
+		KeystoreTotal:     p.iterations,
+		KeystorePercent:   percent(completed, p.iterations),
+		OverallCompleted:  overall,
+		OverallTotal:      overallTotal,
+		OverallPercent:    percent(overall, overallTotal),
+	}
+
+	if elapsed := now.Sub(p.start).Seconds(); !p.start.IsZero() && elapsed > 0 {
+		event.ExitsPerSecond = float64(overall) / elapsed
+	}
+
+	if event.ExitsPerSecond > 0 && overallTotal > overall {
+		event.ETASeconds = float64(overallTotal-overall) / event.ExitsPerSecond
+	}
+
+	return event
+}
+
+// renderProgressBar renders a single carriage-return prefixed progress line
+func renderProgressBar(event *ProgressEvent) string {
+	filled := int(event.OverallPercent / 100 * progressBarWidth)
+	filled = min(max(filled, 0), progressBarWidth)
+
+	eta := "-"
+	if event.ETASeconds > 0 {
+		eta = (time.Duration(event.ETASeconds) * time.Second).String()
+	}
+
+	return fmt.Sprintf("\r\033[K[%s%s] %5.1f%% %d/%d exits | keystore %d/%d %5.1f%% | %.1f exits/s | ETA %s",
+		strings.Repeat("#", filled), strings.Repeat(".", progressBarWidth-filled),
+		event.OverallPercent, event.OverallCompleted, event.OverallTotal,
+		event.Keystore, event.TotalKeystores, event.KeystorePercent,
+		event.ExitsPerSecond, eta)
+}
+
+func percent(completed, total uint64) float64 {
+	if total == 0 {
+		return 0
+	}
+
+	return float64(completed) * 100 / float64(total)
+}
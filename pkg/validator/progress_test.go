@@ -0,0 +1,185 @@
+package validator
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProgressEvents(t *testing.T) {
+	var events, bar bytes.Buffer
+
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	current := start
+
+	p := NewProgress(2, 100, &events, &bar)
+	p.now = func() time.Time { return current }
+	p.start = start
+
+	// 50 exits of the first keystore after 10 seconds
+	current = start.Add(10 * time.Second)
+	event := p.Update(1, 50)
+
+	assert.Equal(t, ProgressEventUpdate, event.Event)
+	assert.Equal(t, uint64(50), event.KeystoreCompleted)
+	assert.InDelta(t, 50.0, event.KeystorePercent, 0.001)
+	assert.Equal(t, uint64(50), event.OverallCompleted)
+	assert.Equal(t, uint64(200), event.OverallTotal)
+	assert.InDelta(t, 25.0, event.OverallPercent, 0.001)
+	assert.InDelta(t, 5.0, event.ExitsPerSecond, 0.001)
+	assert.InDelta(t, 30.0, event.ETASeconds, 0.001)
+
+	current = start.Add(20 * time.Second)
+	event = p.KeystoreDone(1)
+	assert.Equal(t, ProgressEventKeystoreDone, event.Event)
+	assert.Equal(t, uint64(100), event.OverallCompleted)
+
+	// The second keystore counts the first as finished
+	current = start.Add(30 * time.Second)
+	event = p.Update(2, 50)
+	assert.Equal(t, uint64(150), event.OverallCompleted)
+	assert.InDelta(t, 75.0, event.OverallPercent, 0.001)
+
+	current = start.Add(40 * time.Second)
+	event = p.Finish()
+	assert.Equal(t, ProgressEventComplete, event.Event)
+	assert.InDelta(t, 100.0, event.OverallPercent, 0.001)
+	assert.Zero(t, event.ETASeconds)
+
+	var decoded []ProgressEvent
+
+	scanner := bufio.NewScanner(&events)
+	for scanner.Scan() {
+		var e ProgressEvent
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &e))
+		decoded = append(decoded, e)
+	}
+
+	require.Len(t, decoded, 4)
+	assert.Equal(t, ProgressEventUpdate, decoded[0].Event)
+	assert.Equal(t, ProgressEventComplete, decoded[3].Event)
+
+	assert.Contains(t, bar.String(), "\r")
+	assert.Contains(t, bar.String(), "100.0%")
+	assert.True(t, strings.HasSuffix(bar.String(), "\n"))
+}
+
+func TestProgressWithoutWriters(t *testing.T) {
+	p := NewProgress(0, 0, nil, nil)
+
+	event := p.Update(1, 0)
+	assert.Zero(t, event.OverallPercent)
+	assert.Zero(t, event.ETASeconds)
+
+	p.Finish()
+}
+
+func TestRenderProgressBar(t *testing.T) {
+	line := renderProgressBar(&ProgressEvent{
+		Keystore:         1,
+		TotalKeystores:   2,
+		KeystorePercent:  100,
+		OverallCompleted: 100,
+		OverallTotal:     200,
+		OverallPercent:   50,
+		ExitsPerSecond:   10,
+		ETASeconds:       10,
+	})
+
+	assert.Contains(t, line, "[###############...............]")
+	assert.Contains(t, line, "100/200 exits")
+	assert.Contains(t, line, "keystore 1/2")
+	assert.Contains(t, line, "ETA 10s")
+}
+
+func TestProgressStart(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	current := start
+
+	p := NewProgress(1, 100, nil, nil)
+	p.now = func() time.Time { return current }
+
+	// Time spent before signing begins doesn't count towards the rate
+	current = start.Add(time.Minute)
+	assert.Zero(t, p.Update(1, 0).ExitsPerSecond)
+
+	p.Start()
+
+	current = start.Add(time.Minute + 10*time.Second)
+	p.Start()
+	assert.InDelta(t, 5.0, p.Update(1, 50).ExitsPerSecond, 0.001)
+}
+
+func TestProgressLogWriter(t *testing.T) {
+	var terminal bytes.Buffer
+
+	p := NewProgress(1, 100, nil, &terminal)
+	logs := p.LogWriter(&terminal)
+
+	// Nothing to clear before the bar is drawn
+	_, err := logs.Write([]byte("before\n"))
+	require.NoError(t, err)
+	assert.Equal(t, "before\n", terminal.String())
+
+	terminal.Reset()
+
+	event := p.Update(1, 50)
+	bar := renderProgressBar(&event)
+
+	_, err = logs.Write([]byte("during\n"))
+	require.NoError(t, err)
+	assert.Equal(t, bar+"\r\033[Kduring\n"+bar, terminal.String())
+
+	p.Finish()
+	terminal.Reset()
+
+	_, err = logs.Write([]byte("after\n"))
+	require.NoError(t, err)
+	assert.Equal(t, "after\n", terminal.String())
+}
+
+// failingWriter fails every write, like a closed progress file descriptor
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("broken pipe")
+}
+
+func TestProgressEventWriteFailureWithLogWriter(t *testing.T) {
+	var terminal bytes.Buffer
+
+	p := NewProgress(1, 100, failingWriter{}, &terminal)
+
+	// The warning about the failed event goes through the log writer. A
+	// logger of its own keeps a deadlock from blocking the other tests.
+	origLog := log
+	defer func() { log = origLog }()
+
+	log = logrus.New()
+	log.SetOutput(p.LogWriter(&terminal))
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		p.Update(1, 50)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Update deadlocked logging a failed progress event")
+	}
+
+	assert.Contains(t, terminal.String(), "Failed to write progress event")
+	assert.Contains(t, terminal.String(), "broken pipe")
+}
@@ -0,0 +1,326 @@
+package validator
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/prysm/v5/config/params"
+	"github.com/prysmaticlabs/prysm/v5/consensus-types/primitives"
+	ethpb "github.com/prysmaticlabs/prysm/v5/proto/prysm/v1alpha1"
+	"github.com/prysmaticlabs/prysm/v5/runtime/version"
+	"github.com/sirupsen/logrus"
+
+	"github.com/ethpandaops/validator-tools/pkg/beacon"
+)
+
+// StateFile is a beacon.Client answering from an SSZ beacon state, such as
+// one downloaded from /eth/v2/debug/beacon/states/finalized or a checkpoint
+// sync provider, instead of a beacon node. Every state id is answered from
+// the one state in the file.
+type StateFile struct {
+	Network string
+
+	cfg      *params.BeaconChainConfig
+	st       sszBeaconState
+	fork     int
+	byPubkey map[string]primitives.ValidatorIndex
+	// pendingWithdrawals holds the validators with queued partial withdrawals
+	pendingWithdrawals map[primitives.ValidatorIndex]bool
+}
+
+var _ beacon.Client = (*StateFile)(nil)
+
+// LoadStateFile reads an SSZ beacon state of network. Without a network, the
+// known network with the state's genesis validators root is used.
+func LoadStateFile(path, network string) (*StateFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read beacon state")
+	}
+
+	if network == "" {
+		if network, err = stateNetwork(data); err != nil {
+			return nil, err
+		}
+	}
+
+	cfg, err := networkConfig(network)
+	if err != nil {
+		return nil, err
+	}
+
+	st, fork, err := decodeBeaconState(data, cfg)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode beacon state")
+	}
+
+	if !bytes.Equal(st.GetGenesisValidatorsRoot(), cfg.GenesisValidatorsRoot[:]) {
+		return nil, errors.Errorf("beacon state has genesis validators root 0x%x, expected 0x%x for network %s",
+			st.GetGenesisValidatorsRoot(), cfg.GenesisValidatorsRoot, network)
+	}
+
+	f := &StateFile{
+		Network:  network,
+		cfg:      cfg,
+		st:       st,
+		fork:     fork,
+		byPubkey: make(map[string]primitives.ValidatorIndex, len(st.GetValidators())),
+	}
+
+	for i, v := range st.GetValidators() {
+		f.byPubkey[hex.EncodeToString(v.GetPublicKey())] = primitives.ValidatorIndex(i)
+	}
+
+	if electra, ok := st.(*ethpb.BeaconStateElectra); ok {
+		f.pendingWithdrawals = make(map[primitives.ValidatorIndex]bool, len(electra.GetPendingPartialWithdrawals()))
+
+		for _, withdrawal := range electra.GetPendingPartialWithdrawals() {
+			f.pendingWithdrawals[withdrawal.GetIndex()] = true
+		}
+	}
+
+	log.WithFields(logrus.Fields{
+		"path":       path,
+		"network":    network,
+		"fork":       version.String(fork),
+		"slot":       st.GetSlot(),
+		"validators": len(st.GetValidators()),
+	}).Info("Loaded beacon state")
+
+	return f, nil
+}
+
+// stateNetwork returns the known network with the genesis validators root of
+// an SSZ beacon state, which directly follows its genesis time
+func stateNetwork(data []byte) (string, error) {
+	if len(data) < 40 {
+		return "", errors.New("beacon state is too short")
+	}
+
+	return networkByGenesisValidatorsRoot(hex.EncodeToString(data[8:40]))
+}
+
+// ValidatorCount returns the size of the validator registry
+func (f *StateFile) ValidatorCount() int {
+	return len(f.st.GetValidators())
+}
+
+// Slot returns the slot of the state
+func (f *StateFile) Slot() primitives.Slot {
+	return f.st.GetSlot()
+}
+
+func (f *StateFile) epoch() primitives.Epoch {
+	return primitives.Epoch(uint64(f.st.GetSlot()) / uint64(f.cfg.SlotsPerEpoch))
+}
+
+func (f *StateFile) Genesis(context.Context) (*beacon.Genesis, error) {
+	return &beacon.Genesis{
+		GenesisTime:           f.st.GetGenesisTime(),
+		GenesisValidatorsRoot: "0x" + hex.EncodeToString(f.st.GetGenesisValidatorsRoot()),
+		GenesisForkVersion:    "0x" + hex.EncodeToString(f.cfg.GenesisForkVersion),
+	}, nil
+}
+
+func (f *StateFile) Fork(context.Context, string) (*beacon.Fork, error) {
+	fork := f.st.GetFork()
+
+	return &beacon.Fork{
+		PreviousVersion: "0x" + hex.EncodeToString(fork.GetPreviousVersion()),
+		CurrentVersion:  "0x" + hex.EncodeToString(fork.GetCurrentVersion()),
+		Epoch:           uint64(fork.GetEpoch()),
+	}, nil
+}
+
+// Spec returns the spec of the state's network, as a beacon node of that
+// network would
+func (f *StateFile) Spec(context.Context) (beacon.Spec, error) {
+	spec := beacon.Spec{}
+
+	for key, value := range expectedSpec(f.cfg) {
+		raw, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+
+		spec[key] = raw
+	}
+
+	return spec, nil
+}
+
+func (f *StateFile) Validators(ctx context.Context, stateID string, ids []string) ([]*beacon.Validator, error) {
+	var validators []*beacon.Validator
+
+	err := f.StreamValidators(ctx, stateID, ids, func(v *beacon.Validator) error {
+		validators = append(validators, v)
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return validators, nil
+}
+
+func (f *StateFile) StreamValidators(_ context.Context, _ string, ids []string, fn func(*beacon.Validator) error) error {
+	if len(ids) == 0 {
+		for i := range f.st.GetValidators() {
+			if err := fn(f.validator(primitives.ValidatorIndex(i))); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	for _, id := range ids {
+		index, ok, err := f.lookup(id)
+		if err != nil {
+			return err
+		}
+
+		if !ok {
+			continue
+		}
+
+		if err := fn(f.validator(index)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (f *StateFile) Validator(_ context.Context, _ string, id string) (*beacon.Validator, error) {
+	index, ok, err := f.lookup(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !ok {
+		return nil, &beacon.APIError{StatusCode: 404, Message: "Validator not found"}
+	}
+
+	return f.validator(index), nil
+}
+
+func (f *StateFile) NodeSyncing(context.Context) (*beacon.SyncStatus, error) {
+	return &beacon.SyncStatus{HeadSlot: uint64(f.st.GetSlot())}, nil
+}
+
+func (f *StateFile) VoluntaryExits(context.Context) ([]*beacon.SignedVoluntaryExit, error) {
+	return nil, errors.New("the voluntary exit pool is not part of a beacon state file")
+}
+
+func (f *StateFile) SubmitVoluntaryExit(context.Context, *beacon.SignedVoluntaryExit) error {
+	return errors.New("cannot submit voluntary exits to a beacon state file")
+}
+
+func (f *StateFile) BLSToExecutionChanges(context.Context) ([]*beacon.SignedBLSToExecutionChange, error) {
+	return nil, errors.New("the BLS to execution change pool is not part of a beacon state file")
+}
+
+func (f *StateFile) SubmitBLSToExecutionChanges(context.Context, []*beacon.SignedBLSToExecutionChange) error {
+	return errors.New("cannot submit BLS to execution changes to a beacon state file")
+}
+
+func (f *StateFile) Events(context.Context, []string, func(*beacon.Event) error) error {
+	return errors.New("events are not available from a beacon state file")
+}
+
+// lookup returns the index of the validator with the index or 0x prefixed
+// pubkey id
+func (f *StateFile) lookup(id string) (primitives.ValidatorIndex, bool, error) {
+	if strings.HasPrefix(id, "0x") {
+		index, ok := f.byPubkey[strings.ToLower(strings.TrimPrefix(id, "0x"))]
+
+		return index, ok, nil
+	}
+
+	index, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		return 0, false, fmt.Errorf("invalid validator id %q", id)
+	}
+
+	return primitives.ValidatorIndex(index), index < uint64(len(f.st.GetValidators())), nil
+}
+
+// validator returns the registry entry at index in its beacon API form
+func (f *StateFile) validator(index primitives.ValidatorIndex) *beacon.Validator {
+	v := f.st.GetValidators()[index]
+
+	var balance uint64
+	if balances := f.st.GetBalances(); int(index) < len(balances) {
+		balance = balances[index]
+	}
+
+	return &beacon.Validator{
+		Index:   uint64(index),
+		Balance: balance,
+		Status:  validatorStatus(v, balance, f.epoch(), f.cfg.FarFutureEpoch),
+		Validator: beacon.ValidatorData{
+			Pubkey:                     "0x" + hex.EncodeToString(v.GetPublicKey()),
+			WithdrawalCredentials:      "0x" + hex.EncodeToString(v.GetWithdrawalCredentials()),
+			EffectiveBalance:           v.GetEffectiveBalance(),
+			Slashed:                    v.GetSlashed(),
+			ActivationEligibilityEpoch: uint64(v.GetActivationEligibilityEpoch()),
+			ActivationEpoch:            uint64(v.GetActivationEpoch()),
+			ExitEpoch:                  uint64(v.GetExitEpoch()),
+			WithdrawableEpoch:          uint64(v.GetWithdrawableEpoch()),
+		},
+	}
+}
+
+// validatorStatus returns the beacon API status of a validator at epoch
+func validatorStatus(v *ethpb.Validator, balance uint64, epoch, farFuture primitives.Epoch) string {
+	switch {
+	case v.GetActivationEpoch() > epoch:
+		if v.GetActivationEligibilityEpoch() == farFuture {
+			return "pending_initialized"
+		}
+
+		return "pending_queued"
+	case epoch < v.GetExitEpoch():
+		switch {
+		case v.GetExitEpoch() == farFuture:
+			return "active_ongoing"
+		case v.GetSlashed():
+			return "active_slashed"
+		default:
+			return "active_exiting"
+		}
+	case epoch < v.GetWithdrawableEpoch():
+		if v.GetSlashed() {
+			return "exited_slashed"
+		}
+
+		return "exited_unslashed"
+	case balance > 0:
+		return "withdrawal_possible"
+	default:
+		return "withdrawal_done"
+	}
+}
+
+// registryValidator returns the registry entry at index and whether it has
+// the given pubkey
+func (f *StateFile) registryValidator(index primitives.ValidatorIndex, pubkey []byte) (*ethpb.Validator, bool) {
+	v := f.st.GetValidators()[index]
+
+	return v, bytes.Equal(v.GetPublicKey(), pubkey)
+}
+
+// hasPendingWithdrawal reports whether a partial withdrawal of the validator
+// at index is queued, which blocks its exit since Electra
+func (f *StateFile) hasPendingWithdrawal(index primitives.ValidatorIndex) bool {
+	return f.pendingWithdrawals[index]
+}
@@ -0,0 +1,272 @@
+package validator
+
+import (
+	"context"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/prysmaticlabs/prysm/v5/config/params"
+	"github.com/prysmaticlabs/prysm/v5/consensus-types/primitives"
+	"github.com/prysmaticlabs/prysm/v5/crypto/bls"
+	ethpb "github.com/prysmaticlabs/prysm/v5/proto/prysm/v1alpha1"
+	"github.com/prysmaticlabs/prysm/v5/testing/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethpandaops/validator-tools/pkg/beacon"
+)
+
+// testStateEpoch is the epoch of test beacon states, after Electra on hoodi
+const testStateEpoch = 60000
+
+// writeTestBeaconState writes a hoodi Electra state with the given validators
+// and pending partial withdrawals and returns its path
+func writeTestBeaconState(t *testing.T, validators []*ethpb.Validator, pending ...*ethpb.PendingPartialWithdrawal) string {
+	t.Helper()
+
+	hoodi := withForkEpochUpdates("hoodi", params.HoodiConfig())
+
+	st, err := util.NewBeaconStateElectra(func(s *ethpb.BeaconStateElectra) error {
+		s.GenesisTime = 1742213400
+		s.GenesisValidatorsRoot = hoodi.GenesisValidatorsRoot[:]
+		s.Slot = primitives.Slot(testStateEpoch * uint64(hoodi.SlotsPerEpoch))
+		s.Fork = &ethpb.Fork{
+			PreviousVersion: hoodi.DenebForkVersion,
+			CurrentVersion:  hoodi.ElectraForkVersion,
+			Epoch:           hoodi.ElectraForkEpoch,
+		}
+
+		for _, v := range validators {
+			s.Validators = append(s.Validators, v)
+			s.Balances = append(s.Balances, v.EffectiveBalance)
+			s.PreviousEpochParticipation = append(s.PreviousEpochParticipation, 0)
+			s.CurrentEpochParticipation = append(s.CurrentEpochParticipation, 0)
+			s.InactivityScores = append(s.InactivityScores, 0)
+		}
+
+		s.PendingPartialWithdrawals = pending
+
+		return nil
+	})
+	require.NoError(t, err)
+
+	data, err := st.MarshalSSZ()
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "state.ssz")
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+
+	return path
+}
+
+// testValidator returns a registry entry with the given pubkey and epochs
+func testValidator(pubkey []byte, activation, exit primitives.Epoch) *ethpb.Validator {
+	farFuture := params.BeaconConfig().FarFutureEpoch
+
+	withdrawable := farFuture
+	if exit != farFuture {
+		withdrawable = exit + 256
+	}
+
+	return &ethpb.Validator{
+		PublicKey:                  pubkey,
+		WithdrawalCredentials:      make([]byte, 32),
+		EffectiveBalance:           params.BeaconConfig().MaxEffectiveBalance,
+		ActivationEligibilityEpoch: 0,
+		ActivationEpoch:            activation,
+		ExitEpoch:                  exit,
+		WithdrawableEpoch:          withdrawable,
+	}
+}
+
+func testPubkey(b byte) []byte {
+	pubkey := make([]byte, 48)
+	pubkey[0] = b
+
+	return pubkey
+}
+
+func TestLoadStateFile(t *testing.T) {
+	farFuture := params.BeaconConfig().FarFutureEpoch
+
+	path := writeTestBeaconState(t, []*ethpb.Validator{
+		testValidator(testPubkey(1), 0, farFuture),
+		testValidator(testPubkey(2), testStateEpoch+10, farFuture),
+		testValidator(testPubkey(3), 0, testStateEpoch-10),
+	})
+
+	ctx := context.Background()
+
+	// The network is found from the genesis validators root
+	f, err := LoadStateFile(path, "")
+	require.NoError(t, err)
+	assert.Equal(t, "hoodi", f.Network)
+	assert.Equal(t, 3, f.ValidatorCount())
+
+	_, err = LoadStateFile(path, "mainnet")
+	assert.Error(t, err)
+
+	_, err = LoadStateFile(filepath.Join(t.TempDir(), "missing.ssz"), "hoodi")
+	assert.Error(t, err)
+
+	genesis, err := f.Genesis(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1742213400), genesis.GenesisTime)
+	assert.Equal(t, "0x10000910", genesis.GenesisForkVersion)
+
+	fork, err := f.Fork(ctx, "finalized")
+	require.NoError(t, err)
+	assert.Equal(t, "0x60000910", fork.CurrentVersion)
+
+	// The state passes the same network check as a beacon node would
+	require.NoError(t, CheckBeaconNetwork(ctx, f, "hoodi"))
+
+	validators, err := f.Validators(ctx, "finalized", nil)
+	require.NoError(t, err)
+	require.Len(t, validators, 3)
+	assert.Equal(t, "active_ongoing", validators[0].Status)
+	assert.Equal(t, "pending_queued", validators[1].Status)
+	assert.Equal(t, "exited_unslashed", validators[2].Status)
+
+	some, err := f.Validators(ctx, "finalized", []string{"0x" + hex.EncodeToString(testPubkey(3)), "7"})
+	require.NoError(t, err)
+	require.Len(t, some, 1)
+	assert.Equal(t, uint64(2), some[0].Index)
+
+	_, err = f.Validator(ctx, "head", "3")
+	assert.True(t, beacon.IsNotFound(err))
+
+	highest, err := beacon.HighestValidatorIndex(ctx, f, "head")
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), highest)
+
+	g := &VoluntaryExitGenerator{IndexStart: -1, IndexOffset: 5, Beacon: f}
+
+	start, err := g.GetValidatorStartIndex(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 7, start)
+}
+
+func TestValidatorStatus(t *testing.T) {
+	farFuture := params.BeaconConfig().FarFutureEpoch
+
+	tests := []struct {
+		name      string
+		validator *ethpb.Validator
+		balance   uint64
+		expected  string
+	}{
+		{
+			name:      "pending initialized",
+			validator: &ethpb.Validator{ActivationEligibilityEpoch: farFuture, ActivationEpoch: farFuture, ExitEpoch: farFuture},
+			expected:  "pending_initialized",
+		},
+		{
+			name:      "pending queued",
+			validator: &ethpb.Validator{ActivationEpoch: 110, ExitEpoch: farFuture},
+			expected:  "pending_queued",
+		},
+		{
+			name:      "active ongoing",
+			validator: &ethpb.Validator{ActivationEpoch: 100, ExitEpoch: farFuture},
+			expected:  "active_ongoing",
+		},
+		{
+			name:      "active exiting",
+			validator: &ethpb.Validator{ExitEpoch: 101},
+			expected:  "active_exiting",
+		},
+		{
+			name:      "active slashed",
+			validator: &ethpb.Validator{ExitEpoch: 101, Slashed: true},
+			expected:  "active_slashed",
+		},
+		{
+			name:      "exited slashed",
+			validator: &ethpb.Validator{ExitEpoch: 90, WithdrawableEpoch: 101, Slashed: true},
+			expected:  "exited_slashed",
+		},
+		{
+			name:      "withdrawal possible",
+			validator: &ethpb.Validator{ExitEpoch: 90, WithdrawableEpoch: 100},
+			balance:   1,
+			expected:  "withdrawal_possible",
+		},
+		{
+			name:      "withdrawal done",
+			validator: &ethpb.Validator{ExitEpoch: 90, WithdrawableEpoch: 100},
+			expected:  "withdrawal_done",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, validatorStatus(tt.validator, tt.balance, 100, farFuture))
+		})
+	}
+}
+
+func TestVerifyWithStateFile(t *testing.T) {
+	defer params.OverrideBeaconConfig(params.MainnetConfig())
+
+	key, err := bls.RandKey()
+	require.NoError(t, err)
+
+	pubkey := key.PublicKey().Marshal()
+	hoodi := params.HoodiConfig()
+	farFuture := params.BeaconConfig().FarFutureEpoch
+
+	dir := t.TempDir()
+	for index := uint64(1); index <= 4; index++ {
+		writeSignedExit(t, dir, key, hoodi, hoodi.CapellaForkVersion, index)
+	}
+
+	tests := []struct {
+		name        string
+		ours        *ethpb.Validator
+		expectError string
+	}{
+		{
+			name: "our validator is active",
+			ours: testValidator(pubkey, 0, farFuture),
+		},
+		{
+			name:        "our validator has exited",
+			ours:        testValidator(pubkey, 0, testStateEpoch-10),
+			expectError: "non-active validator",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Index 1 is ours, index 2 is taken by another validator, 3 and 4
+			// are not in use yet
+			path := writeTestBeaconState(t, []*ethpb.Validator{
+				testValidator(testPubkey(1), 0, farFuture),
+				tt.ours,
+				testValidator(testPubkey(2), 0, farFuture),
+			})
+
+			exits, err := NewVoluntaryExits(dir, "hoodi", "0x"+hex.EncodeToString(make([]byte, 32)), []string{"0x" + hex.EncodeToString(pubkey)})
+			require.NoError(t, err)
+
+			f, err := LoadStateFile(path, "hoodi")
+			require.NoError(t, err)
+			exits.UseStateFile(f)
+
+			rsp, err := exits.Verify()
+			if tt.expectError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectError)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, uint64(1), rsp.FirstIndex)
+			assert.Equal(t, uint64(4), rsp.LastIndex)
+		})
+	}
+}
@@ -1,33 +1,58 @@
 package validator
 
 import (
+	"context"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 
-	"github.com/prysmaticlabs/prysm/v5/beacon-chain/core/blocks"
-	"github.com/prysmaticlabs/prysm/v5/beacon-chain/state"
-	state_native "github.com/prysmaticlabs/prysm/v5/beacon-chain/state/state-native"
+	"github.com/pkg/errors"
+	fieldparams "github.com/prysmaticlabs/prysm/v5/config/fieldparams"
 	"github.com/prysmaticlabs/prysm/v5/config/params"
 	"github.com/prysmaticlabs/prysm/v5/consensus-types/primitives"
 	ethpb "github.com/prysmaticlabs/prysm/v5/proto/prysm/v1alpha1"
 	"github.com/sirupsen/logrus"
+
+	"github.com/ethpandaops/validator-tools/pkg/atomicfile"
+	"github.com/ethpandaops/validator-tools/pkg/beacon"
 )
 
 // VoluntaryExits represents a collection of voluntary exits for validators
 type VoluntaryExits struct {
 	WithdrawalCreds []byte
 	ExitsByPubkey   map[string]*ValidatorExits
+	// CheckStructure also checks exits against the rules the chain applies
+	// when they are submitted, not only their signatures
+	CheckStructure bool
+	// StateFile is the beacon state exits are checked against, if set
+	StateFile *StateFile
+	// NumWorkers is the number of goroutines verifying signatures, by
+	// default the number of CPUs
+	NumWorkers int
+	// BatchSize is the number of signatures verified at once, by default
+	// DefaultVerifyBatchSize
+	BatchSize int
+	// Findings, if set, collects the problems found by every check instead
+	// of returning the first one as an error
+	Findings *Findings
 }
 
-// ValidatorExits represents the state and exits for a validator
+// ValidatorExits represents the exits for a validator, in validator index order
 type ValidatorExits struct {
-	State state.BeaconState
 	Exits []*VoluntaryExit
+	// Duplicates are tolerated duplicates of Exits. Their signatures are
+	// verified, but they are not counted.
+	Duplicates []*VoluntaryExit
+}
+
+// all returns the exits and their tolerated duplicates
+func (v *ValidatorExits) all() []*VoluntaryExit {
+	return append(append([]*VoluntaryExit{}, v.Exits...), v.Duplicates...)
 }
 
 // VoluntaryExit represents a single voluntary exit
@@ -35,6 +60,8 @@ type VoluntaryExit struct {
 	PBExit *ethpb.SignedVoluntaryExit
 	Pubkey []byte
 	Path   string
+	// NameIndex is the validator index in the file name, if it has one
+	NameIndex *primitives.ValidatorIndex
 }
 
 // SignedVoluntaryExit represents the JSON structure of a signed voluntary exit
@@ -53,13 +80,28 @@ type VerifyResponse struct {
 
 // NewVoluntaryExits creates a new VoluntaryExits instance
 func NewVoluntaryExits(path, network, withdrawalCreds string, expectedPubkeys []string) (*VoluntaryExits, error) {
+	return loadVoluntaryExits(path, network, withdrawalCreds, expectedPubkeys, nil)
+}
+
+// CollectVoluntaryExits creates a VoluntaryExits instance that collects
+// findings. Files that cannot be parsed and unexpected or missing pubkeys are
+// recorded instead of skipped or returned as an error, and so are the
+// problems found by ValidateCount, ValidateIndices and Verify.
+func CollectVoluntaryExits(path, network, withdrawalCreds string, expectedPubkeys []string) (*VoluntaryExits, error) {
+	return loadVoluntaryExits(path, network, withdrawalCreds, expectedPubkeys, &Findings{})
+}
+
+func loadVoluntaryExits(path, network, withdrawalCreds string, expectedPubkeys []string, findings *Findings) (*VoluntaryExits, error) {
 	if err := setNetwork(network); err != nil {
 		log.WithError(err).WithField("network", network).Error("Failed to set network")
 
 		return nil, err
 	}
 
-	exitsByPubkey := make(map[string]*ValidatorExits)
+	e := &VoluntaryExits{
+		ExitsByPubkey: make(map[string]*ValidatorExits),
+		Findings:      findings,
+	}
 
 	files, err := os.ReadDir(path)
 	if err != nil {
@@ -85,29 +127,50 @@ func NewVoluntaryExits(path, network, withdrawalCreds string, expectedPubkeys []
 		if rErr != nil {
 			log.WithError(rErr).WithField("file", file.Name()).Warn("Skipping file")
 
+			if findings != nil {
+				kind := FindingParse
+
+				var nameErr *fileNameError
+				if errors.As(rErr, &nameErr) {
+					kind = FindingFileName
+				}
+
+				findings.add(Finding{Kind: kind, File: filePath, Message: rErr.Error()})
+			}
+
 			continue
 		}
 
 		pubkeyStr := hex.EncodeToString(vexit.Pubkey)
 
-		// Check if pubkey is in expected list
-		if !expectedPubkeyMap[pubkeyStr] {
-			return nil, fmt.Errorf("unexpected pubkey found: %s", pubkeyStr)
-		}
-
-		if iErr := initializeExitState(exitsByPubkey, pubkeyStr, vexit); iErr != nil {
-			log.WithError(iErr).WithField("pubkey", pubkeyStr).Error("Failed to initialize exit state")
+		if _, exists := e.ExitsByPubkey[pubkeyStr]; !exists {
+			// Check if pubkey is in expected list
+			if !expectedPubkeyMap[pubkeyStr] {
+				err := e.fail(Finding{Kind: FindingPubkey, Pubkey: pubkeyStr, Message: fmt.Sprintf("unexpected pubkey found: %s", pubkeyStr)})
+				if err != nil {
+					return nil, err
+				}
+			}
 
-			return nil, iErr
+			e.ExitsByPubkey[pubkeyStr] = &ValidatorExits{Exits: []*VoluntaryExit{}}
 		}
 
-		exitsByPubkey[pubkeyStr].Exits = append(exitsByPubkey[pubkeyStr].Exits, vexit)
+		e.ExitsByPubkey[pubkeyStr].Exits = append(e.ExitsByPubkey[pubkeyStr].Exits, vexit)
+	}
+
+	for _, validatorExits := range e.ExitsByPubkey {
+		sort.SliceStable(validatorExits.Exits, func(i, j int) bool {
+			return validatorExits.Exits[i].PBExit.Exit.ValidatorIndex < validatorExits.Exits[j].PBExit.Exit.ValidatorIndex
+		})
 	}
 
 	// Check if all expected pubkeys were found
-	for pubkey := range expectedPubkeyMap {
-		if _, found := exitsByPubkey[pubkey]; !found {
-			return nil, fmt.Errorf("expected pubkey not found: %s", pubkey)
+	for _, pubkey := range sortedKeys(expectedPubkeyMap) {
+		if _, found := e.ExitsByPubkey[pubkey]; !found {
+			err := e.fail(Finding{Kind: FindingPubkey, Pubkey: pubkey, Message: fmt.Sprintf("expected pubkey not found: %s", pubkey)})
+			if err != nil {
+				return nil, err
+			}
 		}
 	}
 
@@ -118,78 +181,117 @@ func NewVoluntaryExits(path, network, withdrawalCreds string, expectedPubkeys []
 		return nil, err
 	}
 
-	return &VoluntaryExits{
-		WithdrawalCreds: creds,
-		ExitsByPubkey:   exitsByPubkey,
-	}, nil
+	e.WithdrawalCreds = creds
+
+	return e, nil
 }
 
-// setNetwork configures the network parameters
-func setNetwork(network string) error {
-	switch network {
-	case "mainnet":
-		params.OverrideBeaconConfig(params.MainnetConfig())
-	case "holesky":
-		params.OverrideBeaconConfig(params.HoleskyConfig())
-	case "hoodi":
-		params.OverrideBeaconConfig(params.HoodiConfig())
-	default:
-		return fmt.Errorf("unknown network: %s", network)
+// fail records a finding when collecting findings, and otherwise returns it
+// as an error
+func (e *VoluntaryExits) fail(finding Finding) error {
+	if e.Findings == nil {
+		return finding
 	}
 
+	e.Findings.add(finding)
+
 	return nil
 }
 
-// isExitFile checks if a file is a JSON exit file
+// isExitFile checks if a file is a JSON exit file. Hidden files are skipped as
+// they are leftover temporary files from interrupted atomic writes.
 func isExitFile(file os.DirEntry) bool {
-	return !file.IsDir() && strings.Contains(file.Name(), ".json")
+	if file.IsDir() || file.Name() == ManifestFileName {
+		return false
+	}
+
+	return strings.Contains(file.Name(), ".json") && !strings.HasPrefix(file.Name(), ".")
 }
 
-// initializeExitState initializes the state for a validator's exits
-func initializeExitState(exitsByPubkey map[string]*ValidatorExits, pubkeyStr string, vexit *VoluntaryExit) error {
-	if _, exists := exitsByPubkey[pubkeyStr]; exists {
-		return nil
+// CheckCount validates the number and sequence of exits
+func (e *VoluntaryExits) ValidateCount(numExits int) error {
+	if len(e.ExitsByPubkey) == 0 {
+		return e.fail(Finding{Kind: FindingCount, Message: "no voluntary exits found"})
 	}
 
-	slot := primitives.Slot(uint64(params.BeaconConfig().SlotsPerEpoch) * uint64(vexit.PBExit.Exit.Epoch))
+	for _, pubkey := range sortedKeys(e.ExitsByPubkey) {
+		validatorExits := e.ExitsByPubkey[pubkey]
 
-	st, err := state_native.InitializeFromProtoDeneb(&ethpb.BeaconStateDeneb{
-		Slot:                  slot,
-		GenesisValidatorsRoot: params.BeaconConfig().GenesisValidatorsRoot[:],
-	})
-	if err != nil {
-		return err
-	}
+		// Every exit of a pubkey can have been dropped as a duplicate
+		if len(validatorExits.Exits) == 0 {
+			if err := e.fail(Finding{Kind: FindingCount, Pubkey: pubkey, Message: fmt.Sprintf("no exits found for pubkey %s", pubkey)}); err != nil {
+				return err
+			}
 
-	for i := primitives.ValidatorIndex(0); i < vexit.PBExit.Exit.ValidatorIndex; i++ {
-		if err := st.AppendValidator(&ethpb.Validator{}); err != nil {
-			return err
+			continue
+		}
+
+		total := uint64(validatorExits.Exits[len(validatorExits.Exits)-1].PBExit.Exit.ValidatorIndex - validatorExits.Exits[0].PBExit.Exit.ValidatorIndex + 1)
+
+		if total != uint64(len(validatorExits.Exits)) {
+			err := e.fail(Finding{Kind: FindingCount, Pubkey: pubkey,
+				Message: fmt.Sprintf("%d files found but expected %d for pubkey %s", len(validatorExits.Exits), total, pubkey)})
+			if err != nil {
+				return err
+			}
 		}
-	}
 
-	exitsByPubkey[pubkeyStr] = &ValidatorExits{
-		State: st,
-		Exits: []*VoluntaryExit{},
+		if numExits > 0 && len(validatorExits.Exits) != numExits {
+			err := e.fail(Finding{Kind: FindingCount, Pubkey: pubkey,
+				Message: fmt.Sprintf("expected %d exits for pubkey %s but found %d", numExits, pubkey, len(validatorExits.Exits))})
+			if err != nil {
+				return err
+			}
+		}
 	}
 
 	return nil
 }
 
-// CheckCount validates the number and sequence of exits
-func (e *VoluntaryExits) ValidateCount(numExits int) error {
-	if len(e.ExitsByPubkey) == 0 {
-		return fmt.Errorf("no voluntary exits found")
+// ValidateRange checks that every pubkey has exactly one exit for each
+// validator index from first to first+count-1
+func (e *VoluntaryExits) ValidateRange(first primitives.ValidatorIndex, count int) error {
+	if count <= 0 {
+		return nil
 	}
 
-	for pubkey, validatorExits := range e.ExitsByPubkey {
-		total := uint64(validatorExits.Exits[len(validatorExits.Exits)-1].PBExit.Exit.ValidatorIndex - validatorExits.Exits[0].PBExit.Exit.ValidatorIndex + 1)
+	last := first + primitives.ValidatorIndex(count) - 1
 
-		if total != uint64(len(validatorExits.Exits)) {
-			return fmt.Errorf("%d files found but expected %d for pubkey %s", len(validatorExits.Exits), total, pubkey)
+	for _, pubkey := range sortedKeys(e.ExitsByPubkey) {
+		seen := make(map[primitives.ValidatorIndex]bool, count)
+
+		for _, exit := range e.ExitsByPubkey[pubkey].Exits {
+			index := exit.PBExit.Exit.ValidatorIndex
+			if index < first || index > last {
+				err := e.fail(exitFinding(FindingIndices, exit,
+					fmt.Sprintf("validator index %d of pubkey %s is outside the expected range %d to %d", index, pubkey, first, last)))
+				if err != nil {
+					return err
+				}
+
+				continue
+			}
+
+			seen[index] = true
 		}
 
-		if numExits > 0 && len(validatorExits.Exits) != numExits {
-			return fmt.Errorf("expected %d exits for pubkey %s but found %d", numExits, pubkey, len(validatorExits.Exits))
+		if len(seen) == count {
+			continue
+		}
+
+		var missing []primitives.ValidatorIndex
+
+		for index := first; index <= last; index++ {
+			if !seen[index] {
+				missing = append(missing, index)
+			}
+		}
+
+		err := e.fail(Finding{Kind: FindingIndices, Pubkey: pubkey,
+			Message: fmt.Sprintf("%d exits missing in the expected range %d to %d for pubkey %s, first missing validator index %d",
+				len(missing), first, last, pubkey, missing[0])})
+		if err != nil {
+			return err
 		}
 	}
 
@@ -206,58 +308,131 @@ func (e *VoluntaryExits) ValidateIndices() error {
 
 	var firstMin, firstMax primitives.ValidatorIndex
 
-	// Initialize with the first pubkey's values
-	for pubkey, validatorExits := range e.ExitsByPubkey {
-		if len(validatorExits.Exits) == 0 {
-			return fmt.Errorf("no exits found for pubkey %s", pubkey)
-		}
-
-		firstPubkey = pubkey
-		firstMin = validatorExits.Exits[0].PBExit.Exit.ValidatorIndex
-		firstMax = validatorExits.Exits[len(validatorExits.Exits)-1].PBExit.Exit.ValidatorIndex
+	// Compare all pubkeys with the first one that has exits
+	for _, pubkey := range sortedKeys(e.ExitsByPubkey) {
+		validatorExits := e.ExitsByPubkey[pubkey]
 
-		break
-	}
+		if len(validatorExits.Exits) == 0 {
+			if err := e.fail(Finding{Kind: FindingIndices, Pubkey: pubkey, Message: fmt.Sprintf("no exits found for pubkey %s", pubkey)}); err != nil {
+				return err
+			}
 
-	// Compare with all other pubkeys
-	for pubkey, validatorExits := range e.ExitsByPubkey {
-		if pubkey == firstPubkey {
 			continue
 		}
 
-		if len(validatorExits.Exits) == 0 {
-			return fmt.Errorf("no exits found for pubkey %s", pubkey)
-		}
-
 		currentMin := validatorExits.Exits[0].PBExit.Exit.ValidatorIndex
 		currentMax := validatorExits.Exits[len(validatorExits.Exits)-1].PBExit.Exit.ValidatorIndex
 
+		if firstPubkey == "" {
+			firstPubkey, firstMin, firstMax = pubkey, currentMin, currentMax
+
+			continue
+		}
+
 		if currentMin != firstMin {
-			return fmt.Errorf("minimum validator index mismatch: %d for pubkey %s vs %d for pubkey %s",
-				currentMin, pubkey, firstMin, firstPubkey)
+			err := e.fail(Finding{Kind: FindingIndices, Pubkey: pubkey,
+				Message: fmt.Sprintf("minimum validator index mismatch: %d for pubkey %s vs %d for pubkey %s",
+					currentMin, pubkey, firstMin, firstPubkey)})
+			if err != nil {
+				return err
+			}
 		}
 
 		if currentMax != firstMax {
-			return fmt.Errorf("maximum validator index mismatch: %d for pubkey %s vs %d for pubkey %s",
-				currentMax, pubkey, firstMax, firstPubkey)
+			err := e.fail(Finding{Kind: FindingIndices, Pubkey: pubkey,
+				Message: fmt.Sprintf("maximum validator index mismatch: %d for pubkey %s vs %d for pubkey %s",
+					currentMax, pubkey, firstMax, firstPubkey)})
+			if err != nil {
+				return err
+			}
 		}
 	}
 
 	return nil
 }
 
-// readExitFile reads and parses a voluntary exit file
-func readExitFile(filePath string) (*VoluntaryExit, error) {
-	parts := strings.Split(strings.TrimSuffix(filepath.Base(filePath), ".json"), "-")
-	if len(parts) != 2 {
-		return nil, fmt.Errorf("invalid file name format: %s", filePath)
+// fileNameError is an exit file name that parseExitFileName rejects
+type fileNameError struct {
+	message string
+}
+
+func (e *fileNameError) Error() string {
+	return e.message
+}
+
+// parseExitFileName parses the name of an exit file, either
+// <index>-<pubkey>.json as written by generate and extract, or
+// exit-<pubkey>.json without an index. The pubkey may have a 0x prefix and
+// be in either case, and must be 48 bytes. index is nil for names without an
+// index.
+func parseExitFileName(filePath string) (index *primitives.ValidatorIndex, pubkey []byte, err error) {
+	label, pubkeyHex, ok := strings.Cut(strings.TrimSuffix(filepath.Base(filePath), ".json"), "-")
+	if !ok || strings.Contains(pubkeyHex, "-") {
+		return nil, nil, &fileNameError{fmt.Sprintf("invalid file name format: %s", filePath)}
+	}
+
+	if label != "exit" {
+		parsed, pErr := strconv.ParseUint(label, 10, 64)
+		if pErr != nil {
+			return nil, nil, &fileNameError{fmt.Sprintf("invalid file name format: %s", filePath)}
+		}
+
+		nameIndex := primitives.ValidatorIndex(parsed)
+		index = &nameIndex
 	}
 
-	pubkey, err := hex.DecodeString(strings.TrimPrefix(parts[1], "0x"))
+	pubkey, err = hex.DecodeString(strings.TrimPrefix(strings.ToLower(pubkeyHex), "0x"))
 	if err != nil {
 		log.WithError(err).WithField("file", filePath).Error("Invalid pubkey in filename")
 
-		return nil, fmt.Errorf("invalid pubkey in filename: %s", filePath)
+		return nil, nil, &fileNameError{fmt.Sprintf("invalid pubkey in filename: %s", filePath)}
+	}
+
+	if len(pubkey) != fieldparams.BLSPubkeyLength {
+		log.WithFields(logrus.Fields{
+			"file":   filePath,
+			"length": len(pubkey),
+		}).Error("Invalid pubkey length in filename")
+
+		return nil, nil, &fileNameError{fmt.Sprintf("pubkey in filename is %d bytes instead of %d: %s", len(pubkey), fieldparams.BLSPubkeyLength, filePath)}
+	}
+
+	return index, pubkey, nil
+}
+
+// ValidateFileNames ensures the validator index in the name of each exit file
+// matches the validator index of its message, to catch renamed or mislabeled
+// files. Files named without an index are not checked.
+func (e *VoluntaryExits) ValidateFileNames() error {
+	for _, pubkey := range sortedKeys(e.ExitsByPubkey) {
+		for _, exit := range e.ExitsByPubkey[pubkey].all() {
+			if exit.NameIndex == nil || *exit.NameIndex == exit.PBExit.Exit.ValidatorIndex {
+				continue
+			}
+
+			message := fmt.Sprintf("file name index %d does not match message validator index %d: %s",
+				*exit.NameIndex, exit.PBExit.Exit.ValidatorIndex, exit.Path)
+
+			log.WithFields(logrus.Fields{
+				"file":            exit.Path,
+				"name_index":      *exit.NameIndex,
+				"validator_index": exit.PBExit.Exit.ValidatorIndex,
+			}).Error("Exit file is mislabeled")
+
+			if err := e.fail(exitFinding(FindingFileName, exit, message)); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// readExitFile reads and parses a voluntary exit file
+func readExitFile(filePath string) (*VoluntaryExit, error) {
+	nameIndex, pubkey, err := parseExitFileName(filePath)
+	if err != nil {
+		return nil, err
 	}
 
 	data, err := os.ReadFile(filePath)
@@ -304,20 +479,74 @@ func readExitFile(filePath string) (*VoluntaryExit, error) {
 			},
 			Signature: signature,
 		},
-		Pubkey: pubkey,
-		Path:   filePath,
+		Pubkey:    pubkey,
+		Path:      filePath,
+		NameIndex: nameIndex,
 	}, nil
 }
 
-// Verify verifies all voluntary exits
+// UseStateFile checks exits against the beacon state in f. Exits for
+// validators in the state are checked against their registry entry and the
+// withdrawal queue, as if submitted at the state's slot.
+func (e *VoluntaryExits) UseStateFile(f *StateFile) {
+	e.StateFile = f
+	e.CheckStructure = true
+}
+
+// Verify verifies the signatures of all voluntary exits, in parallel batches,
+// and with CheckStructure whether the chain would accept them
 func (e *VoluntaryExits) Verify() (*VerifyResponse, error) {
 	var firstIndex, lastIndex primitives.ValidatorIndex
 
 	var initialized bool
 
-	for pubkey, validatorExits := range e.ExitsByPubkey {
+	domain, err := exitDomain()
+	if err != nil {
+		return nil, err
+	}
+
+	pubkeys := sortedKeys(e.ExitsByPubkey)
+
+	var exits []*VoluntaryExit
+	for _, pubkey := range pubkeys {
+		exits = append(exits, e.ExitsByPubkey[pubkey].all()...)
+	}
+
+	failures := verifyExitSignatures(exits, domain, e.NumWorkers, e.BatchSize, e.Findings != nil)
+	if len(failures) > 0 {
+		for _, failure := range failures {
+			log.WithError(failure.err).WithFields(logrus.Fields{
+				"pubkey":          hex.EncodeToString(failure.exit.Pubkey),
+				"validator_index": failure.exit.PBExit.Exit.ValidatorIndex,
+				"file":            failure.exit.Path,
+			}).Error("Failed to verify exit signature")
+		}
+
+		if params.BeaconConfig().ConfigName == EphemeryNetwork {
+			warnEphemeryIteration(failures[0].exit)
+		}
+
+		if e.Findings == nil {
+			return nil, fmt.Errorf("%d exit signatures failed to verify, first in %s: %w", len(failures), failures[0].exit.Path, failures[0].err)
+		}
+	}
+
+	badSignature := make(map[*VoluntaryExit]bool, len(failures))
+	for _, failure := range failures {
+		badSignature[failure.exit] = true
+		e.Findings.add(exitFinding(FindingSignature, failure.exit, failure.err.Error()))
+	}
+
+	var checker *exitChecker
+	if e.CheckStructure {
+		checker = newExitChecker(e.StateFile)
+	}
+
+	for _, pubkey := range pubkeys {
+		validatorExits := e.ExitsByPubkey[pubkey]
 		log := log.WithField("pubkey", pubkey)
 		verifiedCount := 0
+		takenCount := 0
 
 		if !initialized && len(validatorExits.Exits) > 0 {
 			firstIndex = validatorExits.Exits[0].PBExit.Exit.ValidatorIndex
@@ -326,38 +555,50 @@ func (e *VoluntaryExits) Verify() (*VerifyResponse, error) {
 		}
 
 		for _, exit := range validatorExits.Exits {
-			if err := validatorExits.State.AppendValidator(&ethpb.Validator{
-				PublicKey:             exit.Pubkey,
-				WithdrawalCredentials: e.WithdrawalCreds,
-				ExitEpoch:             params.BeaconConfig().FarFutureEpoch,
-			}); err != nil {
-				log.WithError(err).WithField("validator_index", exit.PBExit.Exit.ValidatorIndex).Error("Failed to append validator")
+			index := exit.PBExit.Exit.ValidatorIndex
 
-				return nil, err
-			}
+			if checker != nil {
+				taken, err := checker.check(exit)
+				if err != nil {
+					log.WithError(err).WithField("validator_index", index).Error("Exit would be rejected by the chain")
 
-			validator, err := validatorExits.State.ValidatorAtIndexReadOnly(exit.PBExit.Exit.ValidatorIndex)
-			if err != nil {
-				log.WithError(err).WithField("validator_index", exit.PBExit.Exit.ValidatorIndex).Error("Failed to get validator")
+					if e.Findings == nil {
+						return nil, err
+					}
 
-				return nil, err
-			}
+					e.Findings.add(exitFinding(FindingStructure, exit, err.Error()))
 
-			if err := blocks.VerifyExitAndSignature(validator, validatorExits.State, exit.PBExit); err != nil {
-				log.WithError(err).WithField("validator_index", exit.PBExit.Exit.ValidatorIndex).Error("Failed to verify exit and signature")
+					continue
+				}
 
-				return nil, err
+				if taken {
+					takenCount++
+
+					log.WithField("validator_index", index).Debug("Validator index is used by another validator in the beacon state")
+
+					continue
+				}
+			}
+
+			if badSignature[exit] {
+				continue
 			}
 
 			verifiedCount++
 
-			log.WithField("validator_index", exit.PBExit.Exit.ValidatorIndex).Debug("Exit verified")
+			log.WithField("validator_index", index).Debug("Exit verified")
 		}
 
-		log.WithFields(logrus.Fields{
+		fields := logrus.Fields{
 			"verified": verifiedCount,
 			"total":    len(validatorExits.Exits),
-		}).Info("Exits verified")
+		}
+
+		if e.StateFile != nil {
+			fields["index_taken"] = takenCount
+		}
+
+		log.WithFields(fields).Info("Exits verified")
 	}
 
 	return &VerifyResponse{
@@ -366,32 +607,28 @@ func (e *VoluntaryExits) Verify() (*VerifyResponse, error) {
 	}, nil
 }
 
-func (e *VoluntaryExits) Extract(beaconURL, outputDir string) error {
-	// Create a VoluntaryExitGenerator to use the FetchJSON method
-	generator := &VoluntaryExitGenerator{BeaconURL: beaconURL}
-
-	// Fetch validator data from beacon API
-	resp, err := generator.FetchJSON(beaconURL + "/eth/v1/beacon/states/finalized/validators")
-	if err != nil {
-		log.WithError(err).Error("Failed to fetch validator data from beacon API")
-
-		return err
+// Extract copies the exit of each validator, as indexed in the finalized
+// state of the beacon node, to outputDir. Only our validators are requested
+// from the beacon node.
+func (e *VoluntaryExits) Extract(ctx context.Context, client beacon.Client, outputDir string) error {
+	// For each pubkey in our exit data, in a stable order, find the matching
+	// validator and copy files
+	pubkeys := make([]string, 0, len(e.ExitsByPubkey))
+	for pubkey := range e.ExitsByPubkey {
+		pubkeys = append(pubkeys, pubkey)
 	}
 
-	// Parse API response
-	var validatorResponse struct {
-		Data []struct {
-			Index     string `json:"index"`
-			Validator struct {
-				Pubkey                string `json:"pubkey"`
-				WithdrawalCredentials string `json:"withdrawal_credentials"`
-			} `json:"validator"`
-			Status string `json:"status"`
-		} `json:"data"`
+	sort.Strings(pubkeys)
+
+	ids := make([]string, 0, len(pubkeys))
+	for _, pubkey := range pubkeys {
+		ids = append(ids, "0x"+pubkey)
 	}
 
-	if err := json.Unmarshal(resp, &validatorResponse); err != nil {
-		log.WithError(err).Error("Failed to parse validator response")
+	// Fetch only our validators from the beacon API
+	validators, err := client.Validators(ctx, "finalized", ids)
+	if err != nil {
+		log.WithError(err).Error("Failed to fetch validator data from beacon API")
 
 		return err
 	}
@@ -403,56 +640,45 @@ func (e *VoluntaryExits) Extract(beaconURL, outputDir string) error {
 		return err
 	}
 
-	// Create map of pubkey -> validator info for active validators
-	validatorMap := make(map[string]struct {
-		index  string
-		status string
-	})
+	// Create map of pubkey -> validator for active validators
+	validatorMap := make(map[string]*beacon.Validator, len(validators))
 
-	for _, validator := range validatorResponse.Data {
+	for _, validator := range validators {
 		// Remove 0x prefix if present
-		pubkey := strings.TrimPrefix(validator.Validator.Pubkey, "0x")
-		validatorMap[pubkey] = struct {
-			index  string
-			status string
-		}{
-			index:  validator.Index,
-			status: validator.Status,
-		}
+		validatorMap[strings.TrimPrefix(validator.Validator.Pubkey, "0x")] = validator
 	}
 
 	// Track which validators we've processed
 	processedValidators := make(map[string]bool)
 
-	// For each pubkey in our exit data, find the matching validator and copy files
-	for pubkey, validatorExits := range e.ExitsByPubkey {
+	for _, pubkey := range pubkeys {
+		validatorExits := e.ExitsByPubkey[pubkey]
+
 		validatorInfo, exists := validatorMap[pubkey]
 		if !exists {
 			return fmt.Errorf("validator with pubkey %s not found in beacon state", pubkey)
 		}
 
 		// Check if validator is active (can be active_ongoing, active_exiting, etc.)
-		if !strings.HasPrefix(validatorInfo.status, "active") && validatorInfo.status != "pending_initialized" && validatorInfo.status != "pending_queued" {
-			return fmt.Errorf("validator with pubkey %s is not active (status: %s)", pubkey, validatorInfo.status)
+		if !validatorInfo.IsActive() {
+			return fmt.Errorf("validator with pubkey %s is not active (status: %s)", pubkey, validatorInfo.Status)
 		}
 
 		log.WithFields(logrus.Fields{
 			"pubkey": pubkey,
-			"index":  validatorInfo.index,
-			"status": validatorInfo.status,
+			"index":  validatorInfo.Index,
+			"status": validatorInfo.Status,
 		}).Info("Extracting exit files for validator")
 
 		// For each exit file for this validator
 		for _, exit := range validatorExits.Exits {
-			expectedIndex := fmt.Sprintf("%d", exit.PBExit.Exit.ValidatorIndex)
-
 			// Verify the validator index matches what we expect
-			if validatorInfo.index != expectedIndex {
+			if uint64(exit.PBExit.Exit.ValidatorIndex) != validatorInfo.Index {
 				continue
 			}
 
 			// Find the source file
-			sourceFileName := fmt.Sprintf("%s-%s.json", expectedIndex, pubkey)
+			sourceFileName := fmt.Sprintf("%d-%s.json", validatorInfo.Index, pubkey)
 
 			// Copy file to output directory
 			destFilePath := filepath.Join(outputDir, sourceFileName)
@@ -477,26 +703,36 @@ func (e *VoluntaryExits) Extract(beaconURL, outputDir string) error {
 		}
 	}
 
+	if err := WriteChecksums(outputDir); err != nil {
+		log.WithError(err).WithField("output_dir", outputDir).Error("Failed to write checksum manifest")
+
+		return err
+	}
+
 	log.WithField("count", len(processedValidators)).Info("Successfully extracted all validator exit files")
 
 	return nil
 }
 
-// copyFile copies a file from source to destination
+// copyFile atomically copies a file from source to destination. Extracted
+// exits are readable by other users, as they were before writes were atomic.
 func copyFile(src, dst string) error {
-	sourceFile, err := os.Open(src)
+	data, err := os.ReadFile(src)
 	if err != nil {
 		return err
 	}
-	defer sourceFile.Close()
 
-	destFile, err := os.Create(dst)
-	if err != nil {
-		return err
+	return atomicfile.WriteFile(dst, data, 0o644)
+}
+
+// sortedKeys returns the keys of m in order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
 	}
-	defer destFile.Close()
 
-	_, err = destFile.ReadFrom(sourceFile)
+	sort.Strings(keys)
 
-	return err
+	return keys
 }
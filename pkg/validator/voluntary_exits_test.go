@@ -1,21 +1,28 @@
 package validator
 
 import (
+	"context"
 	"encoding/hex"
-	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"testing"
 
 	"github.com/prysmaticlabs/prysm/v5/config/params"
+	"github.com/prysmaticlabs/prysm/v5/consensus-types/primitives"
 	ethpb "github.com/prysmaticlabs/prysm/v5/proto/prysm/v1alpha1"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/ethpandaops/validator-tools/pkg/beacon"
+	"github.com/ethpandaops/validator-tools/pkg/beacon/mock"
 )
 
-const testPubkeyHex = "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+const testPubkeyHex = "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
 
 func TestSetNetwork(t *testing.T) {
 	tests := []struct {
@@ -38,6 +45,21 @@ func TestSetNetwork(t *testing.T) {
 			network:     "hoodi",
 			expectError: false,
 		},
+		{
+			name:        "sepolia",
+			network:     "sepolia",
+			expectError: false,
+		},
+		{
+			name:        "gnosis",
+			network:     "gnosis",
+			expectError: false,
+		},
+		{
+			name:        "chiado",
+			network:     "chiado",
+			expectError: false,
+		},
 		{
 			name:        "invalid network",
 			network:     "invalid",
@@ -82,6 +104,18 @@ func TestIsExitFile(t *testing.T) {
 			isDir:    true,
 			expected: false,
 		},
+		{
+			name:     "manifest file",
+			fileName: "manifest.json",
+			isDir:    false,
+			expected: false,
+		},
+		{
+			name:     "temporary file",
+			fileName: ".exit-0x1234.json.tmp-123",
+			isDir:    false,
+			expected: false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -366,6 +400,170 @@ func TestReadExitFile(t *testing.T) {
 	}
 }
 
+func TestParseExitFileName(t *testing.T) {
+	pubkey, err := hex.DecodeString(testPubkeyHex)
+	require.NoError(t, err)
+
+	index := primitives.ValidatorIndex(100)
+
+	tests := []struct {
+		name        string
+		fileName    string
+		index       *primitives.ValidatorIndex
+		expectError string
+	}{
+		{
+			name:     "index and pubkey",
+			fileName: "100-" + testPubkeyHex + ".json",
+			index:    &index,
+		},
+		{
+			name:     "index and 0x pubkey",
+			fileName: "100-0x" + testPubkeyHex + ".json",
+			index:    &index,
+		},
+		{
+			name:     "upper case pubkey",
+			fileName: "100-0X" + strings.ToUpper(testPubkeyHex) + ".json",
+			index:    &index,
+		},
+		{
+			name:     "exit prefix without index",
+			fileName: "exit-0x" + testPubkeyHex + ".json",
+		},
+		{
+			name:        "unknown prefix",
+			fileName:    "foo-" + testPubkeyHex + ".json",
+			expectError: "invalid file name format",
+		},
+		{
+			name:        "negative index",
+			fileName:    "-1-" + testPubkeyHex + ".json",
+			expectError: "invalid file name format",
+		},
+		{
+			name:        "too many parts",
+			fileName:    "100-" + testPubkeyHex + "-copy.json",
+			expectError: "invalid file name format",
+		},
+		{
+			name:        "invalid pubkey",
+			fileName:    "100-0xzz.json",
+			expectError: "invalid pubkey in filename",
+		},
+		{
+			name:        "truncated pubkey",
+			fileName:    "100-0x" + testPubkeyHex[:94] + ".json",
+			expectError: "pubkey in filename is 47 bytes instead of 48",
+		},
+		{
+			name:        "pubkey too long",
+			fileName:    "exit-" + testPubkeyHex + "00.json",
+			expectError: "pubkey in filename is 49 bytes instead of 48",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nameIndex, namePubkey, err := parseExitFileName(filepath.Join("exits", tt.fileName))
+			if tt.expectError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectError)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.index, nameIndex)
+			assert.Equal(t, pubkey, namePubkey)
+		})
+	}
+}
+
+func TestValidateFileNames(t *testing.T) {
+	exitJSON := `{"message":{"epoch":"1","validator_index":"%d"},"signature":"0x00"}`
+
+	tests := []struct {
+		name        string
+		files       map[string]int
+		expectError string
+	}{
+		{
+			name: "matching names",
+			files: map[string]int{
+				"100-" + testPubkeyHex + ".json":   100,
+				"101-0x" + testPubkeyHex + ".json": 101,
+			},
+		},
+		{
+			name: "names without index",
+			files: map[string]int{
+				"exit-" + testPubkeyHex + ".json": 200,
+			},
+		},
+		{
+			name: "mislabeled file",
+			files: map[string]int{
+				"100-" + testPubkeyHex + ".json": 100,
+				"101-" + testPubkeyHex + ".json": 200,
+			},
+			expectError: "file name index 101 does not match message validator index 200",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, index := range tt.files {
+				require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(fmt.Sprintf(exitJSON, index)), 0o600))
+			}
+
+			exits, err := NewVoluntaryExits(dir, "mainnet", "0x00", []string{"0x" + testPubkeyHex})
+			require.NoError(t, err)
+
+			err = exits.ValidateFileNames()
+			if tt.expectError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectError)
+
+				// In collect mode the mislabeled file is reported as a finding
+				exits, err = CollectVoluntaryExits(dir, "mainnet", "0x00", []string{"0x" + testPubkeyHex})
+				require.NoError(t, err)
+				require.NoError(t, exits.ValidateFileNames())
+				require.Len(t, exits.Findings.Findings, 1)
+				assert.Equal(t, FindingFileName, exits.Findings.Findings[0].Kind)
+
+				return
+			}
+
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestCollectInvalidFileNames(t *testing.T) {
+	exitJSON := `{"message":{"epoch":"1","validator_index":"100"},"signature":"0x00"}`
+
+	dir := t.TempDir()
+	for _, name := range []string{
+		"100-" + testPubkeyHex + ".json",
+		"100-" + testPubkeyHex[:94] + ".json",
+		"100-.json",
+	} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(exitJSON), 0o600))
+	}
+
+	exits, err := CollectVoluntaryExits(dir, "mainnet", "0x00", []string{"0x" + testPubkeyHex})
+	require.NoError(t, err)
+	assert.Len(t, exits.ExitsByPubkey[testPubkeyHex].Exits, 1)
+
+	require.Len(t, exits.Findings.Findings, 2)
+
+	for _, finding := range exits.Findings.Findings {
+		assert.Equal(t, FindingFileName, finding.Kind)
+	}
+}
+
 func TestVerify(t *testing.T) {
 	// Setup test environment
 	err := setNetwork("mainnet")
@@ -521,6 +719,56 @@ func TestValidateCount(t *testing.T) {
 	}
 }
 
+func TestValidateRange(t *testing.T) {
+	// exitsFor returns the exits of a pubkey for the given validator indices
+	exitsFor := func(indices ...primitives.ValidatorIndex) *ValidatorExits {
+		validatorExits := &ValidatorExits{}
+		for _, index := range indices {
+			validatorExits.Exits = append(validatorExits.Exits, &VoluntaryExit{
+				PBExit: &ethpb.SignedVoluntaryExit{Exit: &ethpb.VoluntaryExit{ValidatorIndex: index, Epoch: 1}},
+			})
+		}
+
+		return validatorExits
+	}
+
+	tests := []struct {
+		name      string
+		exits     map[string]*ValidatorExits
+		errorText string
+	}{
+		{
+			name:  "exact range",
+			exits: map[string]*ValidatorExits{"pubkey1": exitsFor(101, 102, 103), "pubkey2": exitsFor(101, 102, 103)},
+		},
+		{
+			name:      "shifted range",
+			exits:     map[string]*ValidatorExits{"pubkey1": exitsFor(100, 101, 102)},
+			errorText: "validator index 100 of pubkey pubkey1 is outside the expected range 101 to 103",
+		},
+		{
+			name:      "missing index",
+			exits:     map[string]*ValidatorExits{"pubkey1": exitsFor(101, 103)},
+			errorText: "1 exits missing in the expected range 101 to 103 for pubkey pubkey1, first missing validator index 102",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exits := &VoluntaryExits{ExitsByPubkey: tt.exits}
+
+			err := exits.ValidateRange(101, 3)
+			if tt.errorText == "" {
+				require.NoError(t, err)
+
+				return
+			}
+
+			require.EqualError(t, err, tt.errorText)
+		})
+	}
+}
+
 func TestValidateIndices(t *testing.T) {
 	// Create test data with matching indices
 	pubkey1 := "pubkey1"
@@ -775,22 +1023,60 @@ func TestValidateIntegration(t *testing.T) {
 	assert.NoError(t, err, "Expected no error for indices validation")
 }
 
-// mockHTTPGenerator implements a mock for testing HTTP calls
-type mockHTTPGenerator struct {
-	responses map[string][]byte
-	errors    map[string]error
+// mockBeaconClient is a beacon.Client serving a fixed validator set. Methods
+// that aren't overridden panic through the nil embedded interface.
+type mockBeaconClient struct {
+	beacon.Client
+
+	validators []*beacon.Validator
+	err        error
+	stateIDs   []string
+	ids        [][]string
+}
+
+func (m *mockBeaconClient) Validators(ctx context.Context, stateID string, ids []string) ([]*beacon.Validator, error) {
+	var validators []*beacon.Validator
+
+	err := m.StreamValidators(ctx, stateID, ids, func(v *beacon.Validator) error {
+		validators = append(validators, v)
+
+		return nil
+	})
+
+	return validators, err
+}
+
+func (m *mockBeaconClient) Validator(_ context.Context, stateID, id string) (*beacon.Validator, error) {
+	m.stateIDs = append(m.stateIDs, stateID)
+
+	if m.err != nil {
+		return nil, m.err
+	}
+
+	for _, v := range m.validators {
+		if strconv.FormatUint(v.Index, 10) == id || v.Validator.Pubkey == id {
+			return v, nil
+		}
+	}
+
+	return nil, &beacon.APIError{StatusCode: http.StatusNotFound}
 }
 
-func (m *mockHTTPGenerator) FetchJSON(url string) ([]byte, error) {
-	if err, exists := m.errors[url]; exists {
-		return nil, err
+func (m *mockBeaconClient) StreamValidators(_ context.Context, stateID string, ids []string, fn func(*beacon.Validator) error) error {
+	m.stateIDs = append(m.stateIDs, stateID)
+	m.ids = append(m.ids, ids)
+
+	if m.err != nil {
+		return m.err
 	}
 
-	if resp, exists := m.responses[url]; exists {
-		return resp, nil
+	for _, v := range m.validators {
+		if err := fn(v); err != nil {
+			return err
+		}
 	}
 
-	return nil, fmt.Errorf("no mock response for URL: %s", url)
+	return nil
 }
 
 func TestCopyFile(t *testing.T) {
@@ -867,44 +1153,36 @@ func TestVoluntaryExitsExtract(t *testing.T) {
 	err = os.Chdir(tempDir)
 	require.NoError(t, err)
 
-	// Create mock beacon API response
-	beaconResponse := `{
-		"data": [
-			{
-				"index": "100",
-				"validator": {
-					"pubkey": "0x` + testPubkey1 + `",
-					"withdrawal_credentials": "0x0123456789abcdef0123456789abcdef01234567"
-				},
-				"status": "active_ongoing"
+	// Mock beacon node validators
+	activeValidators := []*beacon.Validator{
+		{
+			Index:  100,
+			Status: "active_ongoing",
+			Validator: beacon.ValidatorData{
+				Pubkey:                "0x" + testPubkey1,
+				WithdrawalCredentials: "0x0123456789abcdef0123456789abcdef01234567",
 			},
-			{
-				"index": "101",
-				"validator": {
-					"pubkey": "0x` + testPubkey2 + `",
-					"withdrawal_credentials": "0x0123456789abcdef0123456789abcdef01234567"
-				},
-				"status": "active_ongoing"
-			}
-		]
-	}`
+		},
+		{
+			Index:  101,
+			Status: "active_ongoing",
+			Validator: beacon.ValidatorData{
+				Pubkey:                "0x" + testPubkey2,
+				WithdrawalCredentials: "0x0123456789abcdef0123456789abcdef01234567",
+			},
+		},
+	}
 
 	tests := []struct {
 		name           string
-		setupMock      func() *mockHTTPGenerator
+		beacon         *mockBeaconClient
 		expectError    bool
 		errorContains  string
 		validateOutput func(t *testing.T)
 	}{
 		{
-			name: "successful extraction",
-			setupMock: func() *mockHTTPGenerator {
-				return &mockHTTPGenerator{
-					responses: map[string][]byte{
-						"http://localhost:5052/eth/v1/beacon/states/finalized/validators": []byte(beaconResponse),
-					},
-				}
-			},
+			name:        "successful extraction",
+			beacon:      &mockBeaconClient{validators: activeValidators},
 			expectError: false,
 			validateOutput: func(t *testing.T) {
 				t.Helper()
@@ -916,90 +1194,39 @@ func TestVoluntaryExitsExtract(t *testing.T) {
 			},
 		},
 		{
-			name: "beacon API error",
-			setupMock: func() *mockHTTPGenerator {
-				return &mockHTTPGenerator{
-					errors: map[string]error{
-						"http://localhost:5052/eth/v1/beacon/states/finalized/validators": fmt.Errorf("API error"),
-					},
-				}
-			},
+			name:          "beacon API error",
+			beacon:        &mockBeaconClient{err: fmt.Errorf("API error")},
 			expectError:   true,
 			errorContains: "API error",
 		},
 		{
-			name: "invalid beacon response",
-			setupMock: func() *mockHTTPGenerator {
-				return &mockHTTPGenerator{
-					responses: map[string][]byte{
-						"http://localhost:5052/eth/v1/beacon/states/finalized/validators": []byte("invalid json"),
-					},
-				}
-			},
-			expectError:   true,
-			errorContains: "invalid character",
-		},
-		{
-			name: "validator not found in beacon state",
-			setupMock: func() *mockHTTPGenerator {
-				emptyResponse := `{"data": []}`
-
-				return &mockHTTPGenerator{
-					responses: map[string][]byte{
-						"http://localhost:5052/eth/v1/beacon/states/finalized/validators": []byte(emptyResponse),
-					},
-				}
-			},
+			name:          "validator not found in beacon state",
+			beacon:        &mockBeaconClient{},
 			expectError:   true,
 			errorContains: "not found in beacon state",
 		},
 		{
 			name: "validator not active",
-			setupMock: func() *mockHTTPGenerator {
-				inactiveResponse := `{
-					"data": [
-						{
-							"index": "100",
-							"validator": {
-								"pubkey": "0x` + testPubkey1 + `",
-								"withdrawal_credentials": "0x0123456789abcdef0123456789abcdef01234567"
-							},
-							"status": "exited_slashed"
-						}
-					]
-				}`
-
-				return &mockHTTPGenerator{
-					responses: map[string][]byte{
-						"http://localhost:5052/eth/v1/beacon/states/finalized/validators": []byte(inactiveResponse),
-					},
-				}
-			},
+			beacon: &mockBeaconClient{validators: []*beacon.Validator{
+				{
+					Index:     100,
+					Status:    "exited_slashed",
+					Validator: beacon.ValidatorData{Pubkey: "0x" + testPubkey1},
+				},
+				activeValidators[1],
+			}},
 			expectError:   true,
 			errorContains: "is not active",
 		},
 		{
 			name: "validator index mismatch",
-			setupMock: func() *mockHTTPGenerator {
-				mismatchResponse := `{
-					"data": [
-						{
-							"index": "999",
-							"validator": {
-								"pubkey": "0x` + testPubkey1 + `",
-								"withdrawal_credentials": "0x0123456789abcdef0123456789abcdef01234567"
-							},
-							"status": "active_ongoing"
-						}
-					]
-				}`
-
-				return &mockHTTPGenerator{
-					responses: map[string][]byte{
-						"http://localhost:5052/eth/v1/beacon/states/finalized/validators": []byte(mismatchResponse),
-					},
-				}
-			},
+			beacon: &mockBeaconClient{validators: []*beacon.Validator{
+				{
+					Index:     999,
+					Status:    "active_ongoing",
+					Validator: beacon.ValidatorData{Pubkey: "0x" + testPubkey1},
+				},
+			}},
 			expectError:   true,
 			errorContains: "not found in beacon state",
 		},
@@ -1053,11 +1280,7 @@ func TestVoluntaryExitsExtract(t *testing.T) {
 				},
 			}
 
-			// Mock the HTTP generator
-			mockGen := tt.setupMock()
-
-			// Replace the generator creation in Extract method by creating a custom extract method for testing
-			err = extractWithMock(exits, "http://localhost:5052", outputDir, mockGen)
+			err = exits.Extract(context.Background(), tt.beacon, outputDir)
 
 			if tt.expectError {
 				require.Error(t, err)
@@ -1070,6 +1293,8 @@ func TestVoluntaryExitsExtract(t *testing.T) {
 			}
 
 			require.NoError(t, err)
+			assert.Equal(t, []string{"finalized"}, tt.beacon.stateIDs)
+			assert.Equal(t, [][]string{{"0x" + testPubkey1, "0x" + testPubkey2}}, tt.beacon.ids)
 
 			if tt.validateOutput != nil {
 				tt.validateOutput(t)
@@ -1078,97 +1303,63 @@ func TestVoluntaryExitsExtract(t *testing.T) {
 	}
 }
 
-// extractWithMock is a test helper that allows injecting a mock HTTP generator
-func extractWithMock(e *VoluntaryExits, beaconURL, outputDir string, mockGen *mockHTTPGenerator) error {
-	// Fetch validator data from beacon API using mock
-	resp, err := mockGen.FetchJSON(beaconURL + "/eth/v1/beacon/states/finalized/validators")
-	if err != nil {
-		return err
-	}
-
-	// Parse API response
-	var validatorResponse struct {
-		Data []struct {
-			Index     string `json:"index"`
-			Validator struct {
-				Pubkey                string `json:"pubkey"`
-				WithdrawalCredentials string `json:"withdrawal_credentials"`
-			} `json:"validator"`
-			Status string `json:"status"`
-		} `json:"data"`
-	}
-
-	if err := json.Unmarshal(resp, &validatorResponse); err != nil {
-		return err
-	}
+func TestVoluntaryExitsExtractMockBeacon(t *testing.T) {
+	tempDir := t.TempDir()
+	outputDir := filepath.Join(tempDir, "output")
 
-	// Create output directory if it doesn't exist
-	if err := os.MkdirAll(outputDir, 0o755); err != nil {
-		return err
-	}
+	testPubkey := "1234567890abcdef1234567890abcdef1234567890abcdef1234567890abcdef"
+	exitFile := filepath.Join(tempDir, "100-"+testPubkey+".json")
 
-	// Create map of pubkey -> validator info for active validators
-	validatorMap := make(map[string]struct {
-		index  string
-		status string
-	})
+	require.NoError(t, os.WriteFile(exitFile, []byte(`{"message":{"epoch":"1","validator_index":"100"},"signature":"0x12"}`), 0o600))
 
-	for _, validator := range validatorResponse.Data {
-		// Remove 0x prefix if present
-		pubkey := strings.TrimPrefix(validator.Validator.Pubkey, "0x")
-		validatorMap[pubkey] = struct {
-			index  string
-			status string
-		}{
-			index:  validator.Index,
-			status: validator.Status,
-		}
+	// The real HTTP client against a mock beacon node, with the exit's
+	// validator among others
+	state := &mock.State{}
+	for i := uint64(98); i < 103; i++ {
+		state.Validators = append(state.Validators, &beacon.Validator{
+			Index:     i,
+			Status:    "active_ongoing",
+			Validator: beacon.ValidatorData{Pubkey: fmt.Sprintf("0x%096x", i)},
+		})
 	}
 
-	// Track which validators we've processed
-	processedValidators := make(map[string]bool)
+	state.Validators[2].Validator.Pubkey = "0x" + testPubkey
 
-	// For each pubkey in our exit data, find the matching validator and copy files
-	for pubkey, validatorExits := range e.ExitsByPubkey {
-		validatorInfo, exists := validatorMap[pubkey]
-		if !exists {
-			return fmt.Errorf("validator with pubkey %s not found in beacon state", pubkey)
-		}
+	server := httptest.NewServer(mock.NewServer(state))
+	defer server.Close()
 
-		// Check if validator is active (can be active_ongoing, active_exiting, etc.)
-		if !strings.HasPrefix(validatorInfo.status, "active") && validatorInfo.status != "pending_initialized" && validatorInfo.status != "pending_queued" {
-			return fmt.Errorf("validator with pubkey %s is not active (status: %s)", pubkey, validatorInfo.status)
-		}
-
-		// For each exit file for this validator
-		for _, exit := range validatorExits.Exits {
-			expectedIndex := fmt.Sprintf("%d", exit.PBExit.Exit.ValidatorIndex)
-
-			// Verify the validator index matches what we expect
-			if validatorInfo.index != expectedIndex {
-				continue
-			}
+	client, err := beacon.NewClient(server.URL, nil)
+	require.NoError(t, err)
 
-			// Find the source file
-			sourceFileName := fmt.Sprintf("%s-%s.json", expectedIndex, pubkey)
+	pubkey, err := hex.DecodeString(testPubkey)
+	require.NoError(t, err)
 
-			// Copy file to output directory
-			destFilePath := filepath.Join(outputDir, sourceFileName)
+	exits := &VoluntaryExits{
+		ExitsByPubkey: map[string]*ValidatorExits{
+			testPubkey: {
+				Exits: []*VoluntaryExit{
+					{
+						PBExit: &ethpb.SignedVoluntaryExit{
+							Exit: &ethpb.VoluntaryExit{ValidatorIndex: 100, Epoch: 1},
+						},
+						Pubkey: pubkey,
+						Path:   exitFile,
+					},
+				},
+			},
+		},
+	}
 
-			if err := copyFile(exit.Path, destFilePath); err != nil {
-				return err
-			}
-		}
+	require.NoError(t, exits.Extract(context.Background(), client, outputDir))
 
-		processedValidators[pubkey] = true
-	}
+	info, err := os.Stat(filepath.Join(outputDir, "100-"+testPubkey+".json"))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o644), info.Mode().Perm())
 
-	// Verify all expected validators were processed
-	for pubkey := range e.ExitsByPubkey {
-		if !processedValidators[pubkey] {
-			return fmt.Errorf("validator %s was not processed", pubkey)
-		}
-	}
+	// The exit no longer applies once the validator has exited
+	state.Validators[2].Status = "exited_unslashed"
 
-	return nil
+	err = exits.Extract(context.Background(), client, filepath.Join(tempDir, "exited"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "is not active")
 }
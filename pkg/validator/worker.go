@@ -21,6 +21,12 @@ func (g *VoluntaryExitGenerator) processExitTasks(tasks chan exitTask, config *B
 
 	var completedExits uint64
 
+	if g.Progress == nil {
+		g.Progress = NewProgress(g.TotalKeystores, g.Iterations, nil, nil)
+	}
+
+	g.Progress.Start()
+
 	// Start progress reporter
 	stopProgress := make(chan struct{})
 	go g.reportProgress(keystoreNum, &completedExits, stopProgress)
@@ -46,6 +52,8 @@ func (g *VoluntaryExitGenerator) processExitTasks(tasks chan exitTask, config *B
 		}
 	}
 
+	g.Progress.KeystoreDone(keystoreNum)
+
 	return nil
 }
 
@@ -105,7 +113,7 @@ func (g *VoluntaryExitGenerator) worker(id int, tasks chan exitTask, wg *sync.Wa
 			return
 		}
 
-		outFile := filepath.Join(g.OutputDir, fmt.Sprintf("%d-%s.json", task.validatorIndex, task.pubkey))
+		outFile := filepath.Join(g.OutputDir, exitFileName(task.validatorIndex, task.pubkey))
 		if err := g.runEthdoCommand(task.keystorePath, outFile, tmpDir, workerLog); err != nil {
 			errChan <- errors.Wrapf(err, "worker %d failed to run ethdo for index %d",
 				id, task.validatorIndex)
@@ -118,19 +126,39 @@ func (g *VoluntaryExitGenerator) worker(id int, tasks chan exitTask, wg *sync.Wa
 	}
 }
 
-// reportProgress reports progress of exit generation
+// reportProgress reports progress of exit generation. Progress events are
+// emitted every second and a summary is logged every 10 seconds.
 func (g *VoluntaryExitGenerator) reportProgress(keystoreNum int32, completedExits *uint64, stop chan struct{}) {
-	ticker := time.NewTicker(10 * time.Second)
-	defer ticker.Stop()
+	progress := g.Progress
+	if progress == nil {
+		progress = NewProgress(g.TotalKeystores, g.Iterations, nil, nil)
+	}
+
+	updateTicker := time.NewTicker(time.Second)
+	defer updateTicker.Stop()
+
+	logTicker := time.NewTicker(10 * time.Second)
+	defer logTicker.Stop()
 
 	for {
 		select {
-		case <-ticker.C:
-			completed := atomic.LoadUint64(completedExits)
-			log.Infof("Progress: Keystore %d/%d - %d/%d exits generated (%.1f%%)",
+		case <-updateTicker.C:
+			progress.Update(keystoreNum, atomic.LoadUint64(completedExits))
+		case <-logTicker.C:
+			event := progress.Update(keystoreNum, atomic.LoadUint64(completedExits))
+
+			// The progress bar already shows this
+			if progress.HasBar() {
+				continue
+			}
+
+			log.Infof("Progress: Keystore %d/%d - %d/%d exits generated (%.1f%%), overall %.1f%% at %.1f exits/s, ETA %s",
 				keystoreNum, g.TotalKeystores,
-				completed, g.Iterations,
-				float64(completed)*100/float64(g.Iterations))
+				event.KeystoreCompleted, g.Iterations,
+				event.KeystorePercent,
+				event.OverallPercent,
+				event.ExitsPerSecond,
+				(time.Duration(event.ETASeconds) * time.Second).String())
 		case <-stop:
 			return
 		}